@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Admin subcommands.
+// This file implements `bms admin log-level`, which reads or changes the log
+// level of a running bmsd through the authenticated admin route on the admin
+// listener. With no arguments it prints the current levels; a single level
+// argument changes the base level and a component plus level changes that
+// component only.
+// `bms admin components` lists the log components registered in the server,
+// including plugin components.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/admin"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/logging"
+)
+
+// Admin log level command. {{{
+
+const adminRequestTimeout = 10 * time.Second
+
+func runAdminLogLevel(env commandEnv, args []string) error {
+	flags := flag.NewFlagSet("admin log-level", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	ttl := flags.Duration("ttl", 0, "revert the change after this duration")
+	token := flags.String("token", env.config.Admin.Token, "admin bearer token")
+	address := flags.String("url", "", "admin listener base URL (defaults to admin.address)")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	var component, level string
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		if _, err := logging.ParseLevel(flags.Arg(0)); err == nil {
+			level = flags.Arg(0)
+		} else {
+			component = flags.Arg(0)
+		}
+	case 2:
+		component, level = flags.Arg(0), flags.Arg(1)
+	default:
+		return errUsage
+	}
+
+	base, client, err := adminClient(env.config, *address, adminRequestTimeout)
+	if err != nil {
+		return err
+	}
+	endpoint := base + admin.LogLevelPath
+	if component != "" {
+		endpoint += "/" + component
+	}
+
+	method := http.MethodGet
+	var body io.Reader
+	if level != "" {
+		method = http.MethodPut
+		payload := admin.LogLevelRequest{Level: level}
+		if *ttl > 0 {
+			payload.TTL = ttl.String()
+		}
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	if component != "" {
+		var effective logging.EffectiveLevel
+		if err := adminRequest(env, client, method, endpoint, *token, body, &effective); err != nil {
+			return err
+		}
+		printLevel(env.stdout, effective)
+		return nil
+	}
+	var snapshot logging.LevelSnapshot
+	if err := adminRequest(env, client, method, endpoint, *token, body, &snapshot); err != nil {
+		return err
+	}
+	printLevels(env.stdout, snapshot)
+	return nil
+}
+
+func runAdminComponents(env commandEnv, args []string) error {
+	flags := flag.NewFlagSet("admin components", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	token := flags.String("token", env.config.Admin.Token, "admin bearer token")
+	address := flags.String("url", "", "admin listener base URL (defaults to admin.address)")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+
+	base, client, err := adminClient(env.config, *address, adminRequestTimeout)
+	if err != nil {
+		return err
+	}
+	var components []logging.ComponentInfo
+	if err := adminRequest(env, client, http.MethodGet, base+admin.ComponentsPath, *token, nil, &components); err != nil {
+		return err
+	}
+	for _, component := range components {
+		fmt.Fprintf(env.stdout, "%-24s%s\n", component.Name, component.Description)
+	}
+	return nil
+}
+
+// }}}
+// Admin helpers. {{{
+
+func adminRequest(env commandEnv, client *http.Client, method string, endpoint string, token string, body io.Reader, result any) error {
+	request, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	warnServerAPI(env.logger, response.Header)
+
+	if response.StatusCode != http.StatusOK {
+		var failure admin.ErrorResponse
+		if err := json.NewDecoder(response.Body).Decode(&failure); err == nil && failure.Error != "" {
+			return fmt.Errorf("%s: %s", errtext.ErrAdminRequestFailed, failure.Error)
+		}
+		return fmt.Errorf("%s: %s", errtext.ErrAdminRequestFailed, response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+func printLevel(writer io.Writer, effective logging.EffectiveLevel) {
+	source := ""
+	if !effective.Override {
+		source = " (base)"
+	}
+	fmt.Fprintf(writer, "%-16s%s%s%s\n", effective.Component, effective.Level, source, formatExpiry(effective.Expires))
+}
+
+func printLevels(writer io.Writer, snapshot logging.LevelSnapshot) {
+	fmt.Fprintf(writer, "%-16s%s%s\n", "level", snapshot.Level, formatExpiry(snapshot.Expires))
+	names := make([]string, 0, len(snapshot.Components))
+	for name := range snapshot.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entry := snapshot.Components[name]
+		fmt.Fprintf(writer, "%-16s%s%s\n", name, entry.Level, formatExpiry(entry.Expires))
+	}
+}
+
+func formatExpiry(expires *time.Time) string {
+	if expires == nil {
+		return ""
+	}
+	return " (until " + expires.Local().Format(time.RFC3339) + ")"
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
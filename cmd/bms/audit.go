@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Audit subcommands.
+// This file implements `bms audit verify`, which checks the hash chain of an
+// audit file, and `bms audit query`, which lists entries by action, actor,
+// outcome, and time. Both read the file named by --path, defaulting to
+// [audit] path, so they also work on a copy taken off the server.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/audit"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Audit commands. {{{
+
+func runAuditVerify(env commandEnv, args []string) error {
+	flags := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	path := flags.String("path", env.config.Audit.Path, "audit file (defaults to audit.path)")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+
+	file, err := openAuditFile(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := audit.Verify(file)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stdout, "ok: %d entries", result.Entries)
+	if result.Head != "" {
+		fmt.Fprintf(env.stdout, ", head %s", result.Head)
+	}
+	fmt.Fprintln(env.stdout)
+	return nil
+}
+
+func runAuditQuery(env commandEnv, args []string) error {
+	flags := flag.NewFlagSet("audit query", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	path := flags.String("path", env.config.Audit.Path, "audit file (defaults to audit.path)")
+	action := flags.String("action", "", "action or area prefix (e.g. auth or auth.login)")
+	actor := flags.String("actor", "", "only entries by this actor")
+	outcome := flags.String("outcome", "", "only entries with this outcome (success, failure)")
+	since := flags.String("since", "", "only entries since a time (RFC 3339) or duration (e.g. 24h)")
+	until := flags.String("until", "", "only entries before a time (RFC 3339) or duration (e.g. 1h)")
+	limit := flags.Int("n", 0, "show only the newest N entries (0 shows all)")
+	raw := flags.Bool("json", false, "print raw JSON entries")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+
+	now := time.Now()
+	filter := audit.Filter{Action: *action, Actor: *actor, Outcome: audit.Outcome(*outcome), Limit: *limit}
+	var err error
+	if filter.Since, err = parseAuditTime(*since, now); err != nil {
+		return err
+	}
+	if filter.Until, err = parseAuditTime(*until, now); err != nil {
+		return err
+	}
+
+	file, err := openAuditFile(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entries, err := audit.Query(file, filter)
+	if err != nil {
+		return err
+	}
+	if *raw {
+		encoder := json.NewEncoder(env.stdout)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return printAuditEntries(env.stdout, entries)
+}
+
+// }}}
+// Audit helpers. {{{
+
+func openAuditFile(path string) (*os.File, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%s: set audit.path or --path", errtext.ErrAuditPathRequired)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errtext.ErrOpenAuditLog, err)
+	}
+	return file, nil
+}
+
+// parseAuditTime accepts an RFC 3339 time or a duration before now.
+func parseAuditTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return parsed, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return time.Time{}, fmt.Errorf("%s: %q", errtext.ErrInvalidAuditTime, value)
+	}
+	return now.Add(-age), nil
+}
+
+func printAuditEntries(writer io.Writer, entries []audit.Entry) error {
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "SEQ\tTIME\tACTION\tOUTCOME\tACTOR\tSOURCE\tDETAILS")
+	for _, entry := range entries {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Seq,
+			entry.Time.Local().Format(time.RFC3339),
+			entry.Action,
+			entry.Outcome,
+			dashIfEmpty(entry.Actor),
+			dashIfEmpty(entry.Source),
+			dashIfEmpty(strings.TrimSpace(string(entry.Details))),
+		)
+	}
+	return table.Flush()
+}
+
+func dashIfEmpty(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
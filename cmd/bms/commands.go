@@ -0,0 +1,295 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// CLI subcommands.
+// This file defines the subcommand table and dispatcher for bms. Commands are
+// addressed by a word path (for example `admin log-level`), parse their own
+// flags, and receive the resolved config and logger through commandEnv.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/buildinfo"
+	"github.com/SandorMiskey/bms-core/internal/certs"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Subcommand table. {{{
+
+// errUsage marks errors that should print command usage.
+var errUsage = errors.New("usage")
+
+// exitUsage is the exit code for usage errors and, in standalone commands,
+// config errors, so scripts can tell a broken invocation from a failed check.
+const exitUsage = 4
+
+// exitError makes bms exit with code instead of 1; a nil err exits without
+// logging, for commands that already reported the outcome.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (exit *exitError) Error() string {
+	if exit.err == nil {
+		return fmt.Sprintf("exit status %d", exit.code)
+	}
+	return exit.err.Error()
+}
+
+func (exit *exitError) Unwrap() error {
+	return exit.err
+}
+
+type commandEnv struct {
+	config config.Config
+	logger *slog.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is one subcommand. Standalone commands run on the unvalidated
+// config with a stderr logger, skipping the logging runtime; noConfig ones
+// also skip config resolution and see the defaults.
+type command struct {
+	path       []string
+	usage      string
+	summary    string
+	standalone bool
+	noConfig   bool
+	run        func(env commandEnv, args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{
+			path:    []string{"admin", "components"},
+			usage:   "[--token token] [--url url]",
+			summary: "list log components registered in the server",
+			run:     runAdminComponents,
+		},
+		{
+			path:    []string{"admin", "log-level"},
+			usage:   "[--ttl duration] [--token token] [--url url] [component] [level]",
+			summary: "show or change the server log level",
+			run:     runAdminLogLevel,
+		},
+		{
+			path:    []string{"audit", "query"},
+			usage:   "[--action name] [--actor name] [--outcome outcome] [--since time] [--until time] [-n count] [--json] [--path file]",
+			summary: "search the audit log",
+			run:     runAuditQuery,
+		},
+		{
+			path:    []string{"audit", "verify"},
+			usage:   "[--path file]",
+			summary: "check the audit log hash chain",
+			run:     runAuditVerify,
+		},
+		{
+			path:    []string{"dev", "events"},
+			usage:   "",
+			summary: "print the log event catalog as Markdown",
+			run:     runDevEvents,
+		},
+		{
+			path:       []string{"dev", "migrate-check"},
+			usage:      "[--migrations dir] [--schema dir] [--json]",
+			summary:    "lint migrations and schema dumps; exit 1 on problems",
+			standalone: true,
+			noConfig:   true,
+			run:        runDevMigrateCheck,
+		},
+		{
+			path:       []string{"health"},
+			usage:      "[--ready|--live|--startup] [--timeout duration] [--json] [--quiet] [--url url]",
+			summary:    "probe server health; exit 0 healthy, 1 not ready, 3 unreachable, 4 usage or config error",
+			standalone: true,
+			run:        runHealth,
+		},
+		{
+			path:    []string{"logs", "tail"},
+			usage:   "[-f] [-n count] [--level level] [--component name] [--since time] [--json] [--token token] [--url url]",
+			summary: "show recent server log records",
+			run:     runLogsTail,
+		},
+		{
+			path:    []string{"version"},
+			usage:   "[--json] [--url url]",
+			summary: "show client and server versions",
+			run:     runVersion,
+		},
+	}
+}
+
+// findCommand returns the command with the longest path matching args.
+func findCommand(args []string) (command, []string, bool) {
+	var found command
+	matched := 0
+	for _, candidate := range commands() {
+		if len(candidate.path) > len(args) || len(candidate.path) <= matched {
+			continue
+		}
+		if strings.Join(candidate.path, " ") == strings.Join(args[:len(candidate.path)], " ") {
+			found = candidate
+			matched = len(candidate.path)
+		}
+	}
+	if matched == 0 {
+		return command{}, nil, false
+	}
+	return found, args[matched:], true
+}
+
+func runCommand(env commandEnv, args []string) error {
+	cmd, rest, ok := findCommand(args)
+	if !ok {
+		printUsage(env.stderr)
+		return fmt.Errorf("%s: %q", errtext.ErrUnknownCommand, strings.Join(args, " "))
+	}
+	return runFound(env, cmd, rest)
+}
+
+func runFound(env commandEnv, cmd command, rest []string) error {
+	err := cmd.run(env, rest)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(env.stderr, "usage: bms %s %s\n", strings.Join(cmd.path, " "), cmd.usage)
+	}
+	return err
+}
+
+// exitCode returns the process exit code for a command error.
+func exitCode(err error) int {
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+	if errors.Is(err, errUsage) {
+		return exitUsage
+	}
+	return 1
+}
+
+// reportable reports whether err should be logged as a command failure.
+func reportable(err error) bool {
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.err != nil
+	}
+	return !errors.Is(err, errUsage)
+}
+
+func printUsage(writer io.Writer) {
+	list := commands()
+	sort.Slice(list, func(i, j int) bool {
+		return strings.Join(list[i].path, " ") < strings.Join(list[j].path, " ")
+	})
+
+	fmt.Fprintln(writer, "usage: bms [--config path] <command> [flags] [args]")
+	fmt.Fprintln(writer, "commands:")
+	for _, cmd := range list {
+		fmt.Fprintf(writer, "    %-24s%s\n", strings.Join(cmd.path, " "), cmd.summary)
+	}
+}
+
+// }}}
+// Command helpers. {{{
+
+// serverClient returns the base URL of the server REST listener and an
+// HTTP client for it, preferring client.server.rest and falling back to
+// rest.address on the same host. Bare addresses use https when client or
+// listener TLS is configured; unix:///path addresses are dialed through the
+// socket. The client applies the [client.server] TLS settings; a zero
+// timeout means none.
+func serverClient(cfg config.Config, override string, timeout time.Duration) (string, *http.Client, error) {
+	address := override
+	if address == "" {
+		address = cfg.Client.Server.REST
+	}
+	if address == "" {
+		address = cfg.REST.Address
+	}
+	if address == "" {
+		return "", nil, fmt.Errorf("%s: set client.server.rest or rest.address", errtext.ErrServerAddressRequired)
+	}
+	return httpClient(cfg, address, cfg.Client.Server.TLSEnabled() || cfg.REST.TLS.Enabled(), timeout)
+}
+
+// adminClient returns the base URL of the server admin listener and an HTTP
+// client for it, taken from admin.address on the same host. Bare addresses
+// use https when admin listener TLS is configured.
+func adminClient(cfg config.Config, override string, timeout time.Duration) (string, *http.Client, error) {
+	address := override
+	if address == "" {
+		address = cfg.Admin.Address
+	}
+	if address == "" {
+		return "", nil, fmt.Errorf("%s: set admin.address", errtext.ErrServerAddressRequired)
+	}
+	return httpClient(cfg, address, cfg.Admin.TLS.Enabled(), timeout)
+}
+
+// httpClient returns the base URL for address and an HTTP client that
+// applies the [client.server] TLS settings.
+func httpClient(cfg config.Config, address string, secure bool, timeout time.Duration) (string, *http.Client, error) {
+	tlsConfig, err := certs.ClientConfig(cfg.Client.Server)
+	if err != nil {
+		return "", nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	client := &http.Client{Timeout: timeout, Transport: transport}
+	scheme := "http://"
+	if secure {
+		scheme = "https://"
+	}
+
+	if path, unix := config.UnixSocketPath(address); unix {
+		transport.DialContext = func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", path)
+		}
+		return scheme + "localhost", client, nil
+	}
+	if strings.Contains(address, "://") {
+		parsed, err := url.Parse(address)
+		if err != nil {
+			return "", nil, err
+		}
+		return strings.TrimSuffix(parsed.String(), "/"), client, nil
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", nil, err
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return scheme + net.JoinHostPort(host, port), client, nil
+}
+
+// warnServerAPI logs a warning when a server response reports an API
+// version this client does not speak.
+func warnServerAPI(logger *slog.Logger, header http.Header) {
+	if err := buildinfo.CheckAPI(header); err != nil {
+		logger.Warn(errtext.ErrIncompatibleAPIVersion, "error", err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
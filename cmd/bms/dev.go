@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Developer subcommands.
+// This file implements `bms dev events`, which prints the structured log
+// event catalog as a Markdown table so operators can build alerts and
+// dashboards against stable event names, and `bms dev migrate-check`, which
+// lints a migration tree and its schema dumps before they are committed.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SandorMiskey/bms-core/internal/logging"
+	"github.com/SandorMiskey/bms-core/internal/migrate/lint"
+)
+
+// Event catalog command. {{{
+
+func runDevEvents(env commandEnv, args []string) error {
+	flags := flag.NewFlagSet("dev events", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+	return writeEventsMarkdown(env.stdout, logging.Events())
+}
+
+func writeEventsMarkdown(writer io.Writer, events []logging.Event) error {
+	var builder strings.Builder
+	builder.WriteString("# Log events\n\n")
+	builder.WriteString("| Event | Component | Level | Message | Attributes | Description |\n")
+	builder.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, event := range events {
+		component := string(event.Component)
+		if component == "" {
+			component = "any"
+		}
+		attrs := make([]string, 0, len(event.Attrs))
+		for _, attr := range event.Attrs {
+			attrs = append(attrs, "`"+attr+"`")
+		}
+		fmt.Fprintf(&builder, "| `%s` | %s | %s | %s | %s | %s |\n",
+			event.Name,
+			component,
+			logging.LevelName(event.Level),
+			markdownCell(event.Message),
+			strings.Join(attrs, ", "),
+			markdownCell(event.Description),
+		)
+	}
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
+
+// }}}
+// Migration check command. {{{
+
+func runDevMigrateCheck(env commandEnv, args []string) error {
+	flags := flag.NewFlagSet("dev migrate-check", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	migrations := flags.String("migrations", filepath.Join("db", "migrations"), "migration tree")
+	schema := flags.String("schema", filepath.Join("db", "schema"), "schema dump tree (empty skips the dump check)")
+	raw := flags.Bool("json", false, "print JSON")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+
+	var problems lint.Problems
+	for _, problem := range lint.Check(os.DirFS(*migrations)) {
+		problem.File = filepath.Join(*migrations, problem.File)
+		problems = append(problems, problem)
+	}
+	if *schema != "" {
+		for _, problem := range lint.CheckSchemaDumps(os.DirFS(*migrations), os.DirFS(*schema)) {
+			problem.File = filepath.Join(*schema, problem.File)
+			problems = append(problems, problem)
+		}
+	}
+
+	if *raw {
+		encoder := json.NewEncoder(env.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(append(lint.Problems{}, problems...)); err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range problems {
+			fmt.Fprintln(env.stdout, problem.Error())
+		}
+		if len(problems) == 0 {
+			fmt.Fprintln(env.stdout, "migrate-check: ok")
+		}
+	}
+	if len(problems) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Health probe subcommand.
+// This file implements `bms health`, a self-contained probe for container
+// HEALTHCHECK instructions and monitoring scripts. It calls one of the
+// server's /readyz, /healthz, or /startupz endpoints and maps the outcome to
+// an exit code: 0 healthy, 1 not ready, 3 unreachable (2 is reserved by
+// Docker), and 4 for a usage or config error. The probe runs without the
+// logging runtime and without config validation; it only needs the server
+// address.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/health"
+)
+
+// Health command. {{{
+
+const (
+	healthDefaultTimeout = 5 * time.Second
+	healthBodyLimit      = 64 << 10
+)
+
+// Health probe exit codes.
+const (
+	healthExitNotReady    = 1
+	healthExitUnreachable = 3
+)
+
+// Health probe outcomes.
+const (
+	healthStatusHealthy     = "healthy"
+	healthStatusNotReady    = "not_ready"
+	healthStatusUnreachable = "unreachable"
+)
+
+// healthReport is the --json output. Detail carries the verbose readiness
+// or startup body when the server returned JSON.
+type healthReport struct {
+	Probe      string          `json:"probe"`
+	URL        string          `json:"url,omitempty"`
+	Status     string          `json:"status"`
+	HTTPStatus int             `json:"http_status,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Duration   string          `json:"duration"`
+	Detail     json.RawMessage `json:"detail,omitempty"`
+}
+
+func runHealth(env commandEnv, args []string) error {
+	flags := flag.NewFlagSet("health", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	ready := flags.Bool("ready", false, "probe readiness (default)")
+	live := flags.Bool("live", false, "probe liveness")
+	startup := flags.Bool("startup", false, "probe startup")
+	raw := flags.Bool("json", false, "print JSON")
+	quiet := flags.Bool("quiet", false, "print nothing; report through the exit code only")
+	timeout := flags.Duration("timeout", healthDefaultTimeout, "request timeout")
+	address := flags.String("url", "", "server base URL (defaults to client.server.rest)")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || *timeout <= 0 {
+		return errUsage
+	}
+
+	probe, path := "ready", health.ReadyzPath
+	selected := 0
+	for _, candidate := range []struct {
+		set  bool
+		name string
+		path string
+	}{
+		{*ready, "ready", health.ReadyzPath},
+		{*live, "live", health.HealthzPath},
+		{*startup, "startup", health.StartupzPath},
+	} {
+		if candidate.set {
+			probe, path = candidate.name, candidate.path
+			selected++
+		}
+	}
+	if selected > 1 {
+		return errUsage
+	}
+
+	report := probeHealth(env, probe, path, *address, *timeout, *raw)
+	switch {
+	case *quiet:
+	case *raw:
+		encoder := json.NewEncoder(env.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	case report.Error != "":
+		fmt.Fprintf(env.stdout, "%s: %s (%s)\n", probe, report.Status, report.Error)
+	default:
+		fmt.Fprintf(env.stdout, "%s: %s (%s)\n", probe, report.Status, report.Message)
+	}
+
+	switch report.Status {
+	case healthStatusHealthy:
+		return nil
+	case healthStatusNotReady:
+		return &exitError{code: healthExitNotReady}
+	default:
+		return &exitError{code: healthExitUnreachable}
+	}
+}
+
+// probeHealth calls path once and classifies the response; any transport
+// failure, including a bad address, counts as unreachable.
+func probeHealth(env commandEnv, probe string, path string, override string, timeout time.Duration, verbose bool) healthReport {
+	report := healthReport{Probe: probe, Status: healthStatusUnreachable}
+	started := time.Now()
+
+	base, client, err := serverClient(env.config, override, timeout)
+	if err != nil {
+		report.Error = err.Error()
+		return finishHealth(&report, started)
+	}
+	report.URL = base + path
+	endpoint := report.URL
+	if verbose && path != health.HealthzPath {
+		endpoint += "?verbose"
+	}
+
+	response, err := client.Get(endpoint)
+	if err != nil {
+		report.Error = fmt.Sprintf("%s: %v", errtext.ErrServerUnreachable, err)
+		return finishHealth(&report, started)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, healthBodyLimit))
+	if err != nil {
+		report.Error = fmt.Sprintf("%s: %v", errtext.ErrServerUnreachable, err)
+		return finishHealth(&report, started)
+	}
+
+	report.HTTPStatus = response.StatusCode
+	if strings.HasPrefix(response.Header.Get("Content-Type"), "application/json") && json.Valid(body) {
+		report.Detail = body
+	} else {
+		report.Message = strings.TrimSpace(string(body))
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		report.Status = healthStatusHealthy
+	case http.StatusServiceUnavailable:
+		report.Status = healthStatusNotReady
+	default:
+		// The server answered, but not from a health endpoint.
+		report.Status = healthStatusNotReady
+		report.Error = fmt.Sprintf("%s: %s", errtext.ErrUnexpectedHealthStatus, response.Status)
+	}
+	if report.Message == "" && report.Detail == nil {
+		report.Message = response.Status
+	}
+	return finishHealth(&report, started)
+}
+
+func finishHealth(report *healthReport, started time.Time) healthReport {
+	report.Duration = time.Since(started).Round(time.Millisecond).String()
+	return *report
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Log tail subcommand.
+// This file implements `bms logs tail`, which reads recent records from the
+// /debug/logs route on the server admin listener and renders them in slog
+// text format. With -f it keeps the connection open and prints new records as
+// they arrive until interrupted. Filters map directly to the route's query
+// parameters.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+
+	"github.com/SandorMiskey/bms-core/internal/admin"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/logging"
+)
+
+// Log tail command. {{{
+
+func runLogsTail(env commandEnv, args []string) error {
+	flags := flag.NewFlagSet("logs tail", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	follow := flags.Bool("f", false, "follow new records")
+	level := flags.String("level", "", "minimum level (debug, info, warn, error)")
+	component := flags.String("component", "", "only records for this component")
+	since := flags.String("since", "", "only records since a time (RFC 3339) or duration (e.g. 15m)")
+	limit := flags.Int("n", 100, "number of buffered records to show (0 shows all)")
+	raw := flags.Bool("json", false, "print raw JSON entries")
+	token := flags.String("token", env.config.Admin.Token, "admin bearer token")
+	address := flags.String("url", "", "admin listener base URL (defaults to admin.address)")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+
+	base, client, err := adminClient(env.config, *address, 0)
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	setQuery(query, "level", *level)
+	setQuery(query, "component", *component)
+	setQuery(query, "since", *since)
+	setQuery(query, "limit", strconv.Itoa(*limit))
+	if *follow {
+		query.Set("follow", "true")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if !*follow {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, adminRequestTimeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, base+admin.LogsPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if *token != "" {
+		request.Header.Set("Authorization", "Bearer "+*token)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		if ctx.Err() != nil && *follow {
+			return nil
+		}
+		return err
+	}
+	defer response.Body.Close()
+	warnServerAPI(env.logger, response.Header)
+	if response.StatusCode != http.StatusOK {
+		var failure admin.ErrorResponse
+		if err := json.NewDecoder(response.Body).Decode(&failure); err == nil && failure.Error != "" {
+			return fmt.Errorf("%s: %s", errtext.ErrAdminRequestFailed, failure.Error)
+		}
+		return fmt.Errorf("%s: %s", errtext.ErrAdminRequestFailed, response.Status)
+	}
+
+	err = renderEntries(response.Body, env.stdout, *raw)
+	if ctx.Err() != nil && *follow {
+		return nil
+	}
+	return err
+}
+
+func setQuery(query url.Values, key string, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+// }}}
+// Entry rendering. {{{
+
+// renderEntries reads NDJSON entries and writes them as slog text records.
+func renderEntries(reader io.Reader, writer io.Writer, raw bool) error {
+	handler := slog.NewTextHandler(writer, &slog.HandlerOptions{Level: slog.Level(-1 << 10)})
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if raw {
+			if _, err := fmt.Fprintf(writer, "%s\n", line); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var entry logging.Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return err
+		}
+		if err := handler.Handle(context.Background(), entryRecord(entry)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func entryRecord(entry logging.Entry) slog.Record {
+	level, err := logging.ParseLevel(entry.Level)
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	record := slog.NewRecord(entry.Time, level, entry.Message, 0)
+	if entry.Component != "" {
+		record.AddAttrs(slog.String(logging.FieldComponent, entry.Component))
+	}
+	record.AddAttrs(mapAttrs(entry.Attrs, logging.FieldComponent)...)
+	return record
+}
+
+// mapAttrs converts decoded attributes back into sorted slog attributes,
+// turning nested objects into groups.
+func mapAttrs(values map[string]any, skip string) []slog.Attr {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != skip {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		if nested, ok := values[key].(map[string]any); ok {
+			attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(mapAttrs(nested, "")...)})
+			continue
+		}
+		attrs = append(attrs, slog.Any(key, values[key]))
+	}
+	return attrs
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
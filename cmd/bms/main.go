@@ -3,8 +3,10 @@
 
 // CLI entry point.
 // This file defines the bms main function, which resolves configuration,
-// initializes structured logging with CLI defaults, emits startup diagnostics
-// (redacted), and exits on configuration errors.
+// initializes structured logging with CLI defaults, and exits on configuration
+// errors. Without a subcommand it emits startup diagnostics (redacted);
+// otherwise it dispatches to the subcommand table in commands.go. Standalone
+// commands such as `bms health` skip validation and the logging runtime.
 
 package main
 
@@ -13,7 +15,9 @@ import (
 	"flag"
 	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/SandorMiskey/bms-core/internal/buildinfo"
 	"github.com/SandorMiskey/bms-core/internal/config"
 	"github.com/SandorMiskey/bms-core/internal/errtext"
 	"github.com/SandorMiskey/bms-core/internal/logging"
@@ -25,8 +29,14 @@ func main() {
 	configPath := flag.String("config", "", "path to config.toml")
 	flag.Parse()
 
+	if cmd, rest, ok := findCommand(flag.Args()); ok && cmd.standalone {
+		os.Exit(runStandalone(*configPath, cmd, rest))
+	}
+
 	configResult, path, warnings, err := config.ResolveConfigDiagnostics(*configPath, config.ConfigOverlay{}, config.ConfigOverlay{})
-	logger, format := initLogger(configResult, logging.ComponentCLI)
+	logRuntime, format := initLogger(configResult, logging.ComponentCLI)
+	defer closeLogger(logRuntime)
+	logger := logRuntime.Logger
 
 	if err != nil {
 		var validationErrors config.ValidationErrors
@@ -36,28 +46,66 @@ func main() {
 		} else {
 			logger.Error(errtext.ErrConfigResolutionFailed, "error", err)
 		}
+		closeLogger(logRuntime)
 		os.Exit(1)
 	}
 
-	logging.LogConfigDiagnostics(logger, format, configResult, path, warnings)
+	if flag.NArg() == 0 {
+		logging.LogConfigDiagnostics(logger, format, configResult, path, warnings)
+		return
+	}
+
+	env := commandEnv{config: configResult, logger: logger, stdout: os.Stdout, stderr: os.Stderr}
+	if err := runCommand(env, flag.Args()); err != nil {
+		if reportable(err) {
+			logger.Error(errtext.ErrCommandFailed, "command", flag.Arg(0), "error", err)
+		}
+		closeLogger(logRuntime)
+		os.Exit(exitCode(err))
+	}
 }
 
-func initLogger(cfg config.Config, component logging.Component) (*slog.Logger, config.LogFormat) {
+// runStandalone runs cmd without validating the config or opening log
+// outputs, so probes stay cheap and work on a partially valid config.
+func runStandalone(configPath string, cmd command, args []string) int {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	configResult := config.DefaultConfig()
+	if !cmd.noConfig {
+		var err error
+		configResult, _, err = config.ResolveConfig(configPath, config.ConfigOverlay{}, config.ConfigOverlay{})
+		if err != nil {
+			logger.Error(errtext.ErrConfigResolutionFailed, "error", err)
+			return exitUsage
+		}
+	}
+
+	env := commandEnv{config: configResult, logger: logger, stdout: os.Stdout, stderr: os.Stderr}
+	if err := runFound(env, cmd, args); err != nil {
+		if reportable(err) {
+			logger.Error(errtext.ErrCommandFailed, "command", strings.Join(cmd.path, " "), "error", err)
+		}
+		return exitCode(err)
+	}
+	return 0
+}
+
+func initLogger(cfg config.Config, component logging.Component) (*logging.Runtime, config.LogFormat) {
 	defaults := logging.LoggerDefaults{
 		Fields: logging.DefaultFields{
 			Component:   component,
 			Environment: string(cfg.Server.Environment),
 			ServerID:    cfg.Server.ID,
+			Version:     buildinfo.Get().Version,
 		},
-		Format: config.LogFormatText,
+		Format: config.LogFormatConsole,
 		Level:  config.LogLevelInfo,
 	}
 
-	logger, err := logging.NewLogger(cfg.Logging, defaults)
+	runtime, err := logging.NewRuntime(cfg.Logging, defaults)
 	if err != nil {
 		fallback := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
 		fallback.Error(errtext.ErrLoggerInitFailed, "error", err)
-		logger = fallback
+		runtime = &logging.Runtime{Logger: fallback}
 	}
 
 	format := cfg.Logging.Format
@@ -65,7 +113,13 @@ func initLogger(cfg config.Config, component logging.Component) (*slog.Logger, c
 		format = defaults.Format
 	}
 
-	return logger, format
+	return runtime, format
+}
+
+func closeLogger(runtime *logging.Runtime) {
+	if err := runtime.Close(); err != nil {
+		_, _ = os.Stderr.WriteString(err.Error() + "\n")
+	}
 }
 
 // }}}
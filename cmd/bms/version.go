@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Version subcommand.
+// This file implements `bms version`, which prints the client build metadata
+// and, when a server is reachable, the server's /version response. A server
+// speaking a different API version is reported as incompatible; an
+// unreachable server is reported but does not fail the command.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/buildinfo"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Version command. {{{
+
+const versionRequestTimeout = 5 * time.Second
+
+// versionReport is the --json output.
+type versionReport struct {
+	Client     buildinfo.Info  `json:"client"`
+	Server     *buildinfo.Info `json:"server,omitempty"`
+	ServerErr  string          `json:"server_error,omitempty"`
+	Compatible *bool           `json:"compatible,omitempty"`
+}
+
+func runVersion(env commandEnv, args []string) error {
+	flags := flag.NewFlagSet("version", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	raw := flags.Bool("json", false, "print JSON")
+	address := flags.String("url", "", "server base URL (defaults to client.server.rest)")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return errUsage
+	}
+
+	report := versionReport{Client: buildinfo.Get()}
+	server, err := fetchServerVersion(env, *address)
+	if err != nil {
+		report.ServerErr = err.Error()
+	} else {
+		report.Server = &server
+		compatible := server.APIVersion == report.Client.APIVersion
+		report.Compatible = &compatible
+	}
+
+	if *raw {
+		encoder := json.NewEncoder(env.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Fprintf(env.stdout, "%-8s%s\n", "client", report.Client)
+	switch {
+	case report.Server == nil:
+		fmt.Fprintf(env.stdout, "%-8s%s\n", "server", report.ServerErr)
+	case !*report.Compatible:
+		fmt.Fprintf(env.stdout, "%-8s%s (%s)\n", "server", report.Server, errtext.ErrIncompatibleAPIVersion)
+	default:
+		fmt.Fprintf(env.stdout, "%-8s%s\n", "server", report.Server)
+	}
+	return nil
+}
+
+// fetchServerVersion reads the server's /version endpoint.
+func fetchServerVersion(env commandEnv, override string) (buildinfo.Info, error) {
+	base, client, err := serverClient(env.config, override, versionRequestTimeout)
+	if err != nil {
+		return buildinfo.Info{}, err
+	}
+	response, err := client.Get(base + buildinfo.Path)
+	if err != nil {
+		return buildinfo.Info{}, fmt.Errorf("%s: %w", errtext.ErrServerVersionFailed, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return buildinfo.Info{}, fmt.Errorf("%s: %s", errtext.ErrServerVersionFailed, response.Status)
+	}
+
+	var info buildinfo.Info
+	if err := json.NewDecoder(response.Body).Decode(&info); err != nil {
+		return buildinfo.Info{}, fmt.Errorf("%s: %w", errtext.ErrServerVersionFailed, err)
+	}
+	if err := buildinfo.CheckAPIVersion(fmt.Sprint(info.APIVersion), info.Version); err != nil {
+		env.logger.Warn(errtext.ErrIncompatibleAPIVersion, "error", err)
+	}
+	return info, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Admin listener wiring.
+// This file builds the separate admin listener from [admin] when the admin
+// token or pprof, expvar, or the debug endpoints are enabled; main runs it as
+// a lifecycle component. It serves the log level, component and recent-log
+// routes next to the debug routes. It listens on admin.address (loopback by
+// default), never on rest.address, and allows long write timeouts so CPU
+// profiles and execution traces can finish.
+
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/admin"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/metrics"
+)
+
+// Admin listener. {{{
+
+const (
+	listenerAdmin     = "admin"
+	adminWriteTimeout = 5 * time.Minute
+)
+
+// newAdminServer returns the admin listener server, or nil when no admin
+// endpoint is enabled.
+func newAdminServer(logger *slog.Logger, cfg config.AdminConfig, options admin.Options, httpMetrics *metrics.HTTPMetrics) *http.Server {
+	if !cfg.ListenerEnabled() {
+		logger.Info("admin listener disabled", "reason", "no admin endpoint is enabled")
+		return nil
+	}
+
+	mux := admin.NewDebugMux(admin.DebugOptions{
+		Failures:    options.Failures,
+		Debug:       cfg.Debug,
+		Expvar:      cfg.Expvar,
+		Pprof:       cfg.Pprof,
+		RequireAuth: cfg.RequireAuth,
+		Token:       cfg.Token,
+	})
+	if !admin.Register(mux, options) {
+		logger.Info("admin routes disabled", "reason", "admin token is empty")
+	}
+	logger.Info("admin listener enabled", "address", cfg.Address, "pprof", cfg.Pprof, "expvar", cfg.Expvar, "debug", cfg.Debug, "require_auth", cfg.RequireAuth)
+	return &http.Server{
+		Addr:              cfg.Address,
+		Handler:           httpMetrics.Wrap(listenerAdmin, mux),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      adminWriteTimeout,
+		IdleTimeout:       30 * time.Second,
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Server audit wiring.
+// This file opens the audit log configured under [audit] and records server
+// start and stop. Each start carries a digest of the resolved config; when it
+// differs from the previous start, a config.changed entry is recorded first
+// so config edits between restarts leave a trace.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"github.com/SandorMiskey/bms-core/internal/audit"
+	"github.com/SandorMiskey/bms-core/internal/buildinfo"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Audit wiring. {{{
+
+const (
+	auditActor        = "bmsd"
+	auditConfigDigest = "config_sha256"
+)
+
+// openAudit opens the audit log and records the server start. It returns a
+// nil log when auditing is disabled.
+func openAudit(logger *slog.Logger, cfg config.Config, configPath string) (*audit.Log, error) {
+	if cfg.Audit.Path == "" {
+		logger.Info("audit log disabled", "reason", "audit path is empty")
+		return nil, nil
+	}
+
+	digest, err := configDigest(cfg)
+	if err != nil {
+		return nil, err
+	}
+	previous := lastConfigDigest(cfg.Audit.Path)
+	auditLog, err := audit.Open(cfg.Audit.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	source, _ := os.Hostname()
+	if previous != "" && previous != digest {
+		recordAudit(logger, auditLog, audit.Event{
+			Action: audit.ActionConfigChanged,
+			Actor:  auditActor,
+			Source: source,
+			Details: map[string]any{
+				"config_path":                   configPath,
+				auditConfigDigest:               digest,
+				"previous_" + auditConfigDigest: previous,
+			},
+		})
+	}
+	recordAudit(logger, auditLog, audit.Event{
+		Action: audit.ActionServerStarted,
+		Actor:  auditActor,
+		Source: source,
+		Details: map[string]any{
+			"config_path":     configPath,
+			auditConfigDigest: digest,
+			"pid":             os.Getpid(),
+			"version":         buildinfo.Get().Version,
+		},
+	})
+	return auditLog, nil
+}
+
+// closeAudit records the server stop and closes the audit log.
+func closeAudit(logger *slog.Logger, auditLog *audit.Log) {
+	if auditLog == nil {
+		return
+	}
+	source, _ := os.Hostname()
+	recordAudit(logger, auditLog, audit.Event{Action: audit.ActionServerStopped, Actor: auditActor, Source: source})
+	if err := auditLog.Close(); err != nil {
+		logger.Error(errtext.ErrAuditRecordFailed, "error", err)
+	}
+}
+
+func recordAudit(logger *slog.Logger, auditLog *audit.Log, event audit.Event) {
+	if _, err := auditLog.Record(event); err != nil {
+		logger.Error(errtext.ErrAuditRecordFailed, "action", string(event.Action), "error", err)
+	}
+}
+
+// configDigest hashes the resolved config, secrets included, so rotating a
+// token also counts as a change. Only the digest is recorded.
+func configDigest(cfg config.Config) (string, error) {
+	encoded, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// lastConfigDigest returns the digest from the most recent server start, or
+// an empty string when there is none.
+func lastConfigDigest(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	entries, err := audit.Query(file, audit.Filter{Action: string(audit.ActionServerStarted), Limit: 1})
+	if err != nil || len(entries) == 0 {
+		return ""
+	}
+	var details map[string]any
+	if err := json.Unmarshal(entries[0].Details, &details); err != nil {
+		return ""
+	}
+	digest, _ := details[auditConfigDigest].(string)
+	return digest
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Backup and restore subcommands.
+// This file implements `bmsd backup`, which snapshots the configured
+// database into backup.directory (or --dir) and prunes it to
+// backup.retention, and `bmsd restore`, which checks a backup against this
+// server and build, loads it, and runs the migrations added since. Restore
+// runs with the server stopped. With backup.interval set, the backup
+// component takes the same snapshots while the server runs; a failed
+// snapshot is logged and retried at the next interval instead of stopping
+// the server.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/backup"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/lifecycle"
+	"github.com/SandorMiskey/bms-core/internal/logging"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+)
+
+// Backup command. {{{
+
+const (
+	backupUsage  = "usage: bmsd [--config path] backup [--dir path] [--json]"
+	restoreUsage = "usage: bmsd [--config path] restore [--force] [--json] <backup dir>"
+)
+
+// backupReport is the backup --json output.
+type backupReport struct {
+	Backup backup.Backup   `json:"backup"`
+	Pruned []backup.Backup `json:"pruned"`
+}
+
+func runBackup(configPath string, args []string) int {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	dir := flags.String("dir", "", "backup directory (default backup.directory)")
+	raw := flags.Bool("json", false, "print JSON")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, backupUsage)
+		return 1
+	}
+
+	configResult, _, err := config.ResolveConfigAndValidate(configPath, config.ConfigOverlay{}, config.ConfigOverlay{})
+	logRuntime, _ := initLogger(configResult, logging.ComponentDatabase)
+	defer closeLogger(logRuntime)
+	logger := logRuntime.Logger
+	if err != nil {
+		logger.Error(errtext.ErrConfigValidationFailed, "error", err)
+		return 1
+	}
+	if *dir == "" {
+		*dir = configResult.Backup.Directory
+	}
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "backup.directory is not set; pass --dir")
+		return 1
+	}
+
+	database, err := db.Open(configResult.Database)
+	if err != nil {
+		logger.Error(errtext.ErrDatabaseOpen, "error", err)
+		return 1
+	}
+	defer database.Close()
+	ctx := context.Background()
+	if err := database.Connect(ctx); err != nil {
+		logger.Error(errtext.ErrDatabaseConnect, "error", err)
+		return 1
+	}
+
+	report := backupReport{Pruned: []backup.Backup{}}
+	report.Backup, err = backup.Create(ctx, database, *dir, configResult.Server.ID)
+	if err != nil {
+		logger.Error(errtext.ErrBackupCreate, "error", err)
+		return 1
+	}
+	pruned, err := backup.Prune(*dir, configResult.Backup.Retention)
+	report.Pruned = append(report.Pruned, pruned...)
+	if err != nil {
+		logger.Warn("backup prune failed", "dir", *dir, "error", err)
+	}
+
+	if *raw {
+		return writeJSON(report)
+	}
+	writeBackup(os.Stdout, report.Backup)
+	for _, old := range report.Pruned {
+		fmt.Fprintf(os.Stdout, "pruned %s\n", old.Path)
+	}
+	return 0
+}
+
+func runRestore(configPath string, args []string) int {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	force := flags.Bool("force", false, "restore a backup taken on another server")
+	raw := flags.Bool("json", false, "print JSON")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, restoreUsage)
+		return 1
+	}
+
+	configResult, _, err := config.ResolveConfigAndValidate(configPath, config.ConfigOverlay{}, config.ConfigOverlay{})
+	logRuntime, _ := initLogger(configResult, logging.ComponentDatabase)
+	defer closeLogger(logRuntime)
+	logger := logRuntime.Logger
+	if err != nil {
+		logger.Error(errtext.ErrConfigValidationFailed, "error", err)
+		return 1
+	}
+
+	opened, err := backup.Open(flags.Arg(0))
+	if err != nil {
+		logger.Error(errtext.ErrRestoreFailed, "error", err)
+		return 1
+	}
+	restored, err := backup.Restore(context.Background(), configResult.Database, opened, backup.RestoreOptions{
+		Migrations: migrate.Source(configResult.Database),
+		ServerID:   configResult.Server.ID,
+		Force:      *force,
+		Logger:     logger,
+	})
+	if err != nil {
+		logger.Error(errtext.ErrRestoreFailed, "backup", opened.Path, "error", err)
+		return 1
+	}
+	logger.Info("backup restored", "backup", opened.Path, "schema_version", opened.Manifest.SchemaVersion, "applied", len(restored.Applied))
+
+	if *raw {
+		return writeJSON(restored)
+	}
+	fmt.Fprintf(os.Stdout, "restored %s (schema version %d, taken %s)\n", opened.Path, opened.Manifest.SchemaVersion, opened.Manifest.CreatedAt.Format(time.RFC3339))
+	if restored.Previous != "" {
+		fmt.Fprintf(os.Stdout, "previous database kept at %s\n", restored.Previous)
+	}
+	for _, migration := range restored.Applied {
+		fmt.Fprintf(os.Stdout, "applied      %s\n", migration)
+	}
+	return 0
+}
+
+func writeBackup(writer io.Writer, created backup.Backup) {
+	manifest := created.Manifest
+	fmt.Fprintf(writer, "backup %s\n", created.Path)
+	fmt.Fprintf(writer, "  schema version %d, %d bytes, sha256 %s\n", manifest.SchemaVersion, manifest.Size, manifest.SHA256)
+}
+
+func writeJSON(value any) int {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// }}}
+// Backup component. {{{
+
+const backupComponentName = "backup"
+
+// backupComponent takes a snapshot every backup.interval and prunes the
+// directory afterwards. Stop cancels a snapshot in progress and waits for
+// it to clean up.
+func backupComponent(logger *slog.Logger, database *db.DB, settings config.BackupConfig, serverID string, dependsOn []string) lifecycle.Component {
+	var stop context.CancelFunc
+	done := make(chan struct{})
+	return lifecycle.Component{
+		Name:      backupComponentName,
+		DependsOn: dependsOn,
+		Start: func(context.Context) error {
+			var ctx context.Context
+			ctx, stop = context.WithCancel(context.Background())
+			interval := parseDuration(settings.Interval, 0)
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						scheduledBackup(ctx, logger, database, settings, serverID)
+					}
+				}
+			}()
+			logger.Info("scheduled backups enabled", "dir", settings.Directory, "interval", settings.Interval, "retention", settings.Retention)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			stop()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+func scheduledBackup(ctx context.Context, logger *slog.Logger, database *db.DB, settings config.BackupConfig, serverID string) {
+	created, err := backup.Create(ctx, database, settings.Directory, serverID)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error(errtext.ErrBackupCreate, "dir", settings.Directory, "error", err)
+		}
+		return
+	}
+	logger.Info("backup created", "path", created.Path, "schema_version", created.Manifest.SchemaVersion, "size", created.Manifest.Size)
+	pruned, err := backup.Prune(settings.Directory, settings.Retention)
+	for _, old := range pruned {
+		logger.Info("backup pruned", "path", old.Path)
+	}
+	if err != nil {
+		logger.Warn("backup prune failed", "dir", settings.Directory, "error", err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
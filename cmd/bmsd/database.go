@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Server database.
+// This file wires internal/db into bmsd: the pool is prepared from
+// [database] before the components are built, the database component
+// connects first and closes last, and listeners depend on it so requests
+// never reach a server without a database. With database.auto_migrate the
+// migrations component applies pending migrations after the database
+// connects and before the listeners start, so readiness stays "starting"
+// until the schema is current. The identity component then records
+// server.id in server_meta, or warns when the database already belongs to
+// another server.
+
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/lifecycle"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+)
+
+// Database component. {{{
+
+const (
+	databaseComponentName   = "database"
+	identityComponentName   = "identity"
+	migrationsComponentName = "migrations"
+)
+
+// databaseComponent connects database on start and closes it on stop.
+func databaseComponent(logger *slog.Logger, database *db.DB) lifecycle.Component {
+	return lifecycle.Component{
+		Name: databaseComponentName,
+		Start: func(ctx context.Context) error {
+			if err := database.Connect(ctx); err != nil {
+				return err
+			}
+			stats := database.Stats()
+			logger.Info("database connected", "driver", database.Dialect().Driver(), "max_open_conns", stats.MaxOpenConnections)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			return database.Close()
+		},
+	}
+}
+
+// migrationsComponent applies pending migrations on start.
+func migrationsComponent(logger *slog.Logger, migrator *migrate.Migrator) lifecycle.Component {
+	return lifecycle.Component{
+		Name:      migrationsComponentName,
+		DependsOn: []string{databaseComponentName},
+		Start: func(ctx context.Context) error {
+			applied, err := migrator.Up(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("migrations current", "applied", len(applied))
+			return nil
+		},
+	}
+}
+
+// identityComponent records serverID in server_meta on start. A database
+// that records another server only logs a warning, since a restore with
+// --force or a renamed server legitimately leaves one behind; a schema
+// without server_meta is skipped the same way.
+func identityComponent(logger *slog.Logger, database *db.DB, serverID string, dependsOn []string) lifecycle.Component {
+	return lifecycle.Component{
+		Name:      identityComponentName,
+		DependsOn: dependsOn,
+		Start: func(ctx context.Context) error {
+			recorded, err := database.Meta(ctx, db.MetaServerID)
+			switch {
+			case err != nil:
+				logger.Warn(errtext.ErrServerMeta, "error", err)
+			case recorded == "":
+				if err := database.SetMeta(ctx, db.MetaServerID, serverID); err != nil {
+					logger.Warn(errtext.ErrServerMeta, "error", err)
+					return nil
+				}
+				logger.Info("server identity recorded")
+			case recorded != serverID:
+				logger.Warn(errtext.ErrServerIDMismatch, "recorded", recorded, "configured", serverID)
+			}
+			return nil
+		},
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Doctor subcommand.
+// This file implements `bmsd doctor`, which resolves the config and runs the
+// environment checks in internal/doctor without starting the server. It
+// prints a readable report or, with --json, the report as JSON, and exits
+// non-zero when any check fails; warnings do not fail it.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/SandorMiskey/bms-core/internal/doctor"
+)
+
+// Doctor command. {{{
+
+func runDoctor(configPath string, args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	raw := flags.Bool("json", false, "print JSON")
+	timeout := flags.Duration("timeout", doctor.DefaultTimeout, "network check timeout")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: bmsd [--config path] doctor [--json] [--timeout duration]")
+		return 1
+	}
+
+	report := doctor.Run(context.Background(), configPath, doctor.Options{Timeout: *timeout})
+	if *raw {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else {
+		report.WriteText(os.Stdout)
+	}
+	if report.Status == doctor.StatusFail {
+		return 1
+	}
+	return 0
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
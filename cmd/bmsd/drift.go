@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Database subcommands.
+// This file implements `bmsd db drift`, which compares the tables, columns,
+// constraints, and indexes of the configured database with the newest
+// committed schema dump for its applied migration version (db/schema by
+// default). It exits 0 when they match, 1 on drift, and 2 when the
+// comparison cannot run, so CI can tell a changed schema from a broken
+// setup.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/logging"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+	"github.com/SandorMiskey/bms-core/internal/schema"
+)
+
+// Drift command. {{{
+
+const dbUsage = "usage: bmsd [--config path] db drift [--schema dir] [--json]"
+
+// Drift exit codes.
+const (
+	driftExitDrift = 1
+	driftExitError = 2
+)
+
+// driftReport is the --json output.
+type driftReport struct {
+	Version     uint64              `json:"version"`
+	Dump        string              `json:"dump"`
+	Drift       bool                `json:"drift"`
+	Differences []schema.Difference `json:"differences"`
+}
+
+func runDB(configPath string, args []string) int {
+	if len(args) == 0 || args[0] != "drift" {
+		fmt.Fprintln(os.Stderr, dbUsage)
+		return driftExitError
+	}
+	flags := flag.NewFlagSet("db drift", flag.ContinueOnError)
+	schemaDir := flags.String("schema", filepath.Join("db", "schema"), "schema dump tree")
+	raw := flags.Bool("json", false, "print JSON")
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, dbUsage)
+		return driftExitError
+	}
+
+	configResult, _, err := config.ResolveConfigAndValidate(configPath, config.ConfigOverlay{}, config.ConfigOverlay{})
+	logRuntime, _ := initLogger(configResult, logging.ComponentDatabase)
+	defer closeLogger(logRuntime)
+	logger := logRuntime.Logger
+	if err != nil {
+		logger.Error(errtext.ErrConfigValidationFailed, "error", err)
+		return driftExitError
+	}
+
+	report, err := checkDrift(context.Background(), logger, configResult.Database, *schemaDir)
+	if err != nil {
+		logger.Error(errtext.ErrSchemaIntrospect, "error", err)
+		return driftExitError
+	}
+	if *raw {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return driftExitError
+		}
+	} else {
+		writeDrift(os.Stdout, report)
+	}
+	if report.Drift {
+		return driftExitDrift
+	}
+	return 0
+}
+
+// checkDrift compares the database in settings with the dump for its
+// applied version.
+func checkDrift(ctx context.Context, logger *slog.Logger, settings config.DatabaseConfig, schemaDir string) (driftReport, error) {
+	report := driftReport{Differences: []schema.Difference{}}
+	database, err := db.Open(settings)
+	if err != nil {
+		return report, err
+	}
+	defer database.Close()
+	if err := database.Connect(ctx); err != nil {
+		return report, err
+	}
+
+	migrator, err := migrate.New(database, migrate.Source(settings), logger)
+	if err != nil {
+		return report, err
+	}
+	status, err := migrator.Status(ctx)
+	switch {
+	case err != nil:
+		return report, err
+	case status.Dirty:
+		return report, fmt.Errorf("%s at version %d", errtext.ErrMigrationDirty, status.Version)
+	case status.Version == 0:
+		return report, fmt.Errorf("%s; run bmsd migrate up", errtext.ErrNoMigrationsApplied)
+	}
+	report.Version = status.Version
+
+	dumps := os.DirFS(schemaDir)
+	dump, err := schema.LatestDump(dumps, settings.Driver, status.Version)
+	if err != nil {
+		return report, err
+	}
+	report.Dump = filepath.Join(schemaDir, dump)
+	body, err := fs.ReadFile(dumps, dump)
+	if err != nil {
+		return report, err
+	}
+	want, err := schema.ParseDump(ctx, settings.Driver, string(body))
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", report.Dump, err)
+	}
+	got, err := schema.Introspect(ctx, database)
+	if err != nil {
+		return report, err
+	}
+	report.Differences = append(report.Differences, schema.Diff(want, got)...)
+	report.Drift = len(report.Differences) > 0
+	return report, nil
+}
+
+func writeDrift(writer io.Writer, report driftReport) {
+	fmt.Fprintf(writer, "version %d, compared with %s\n", report.Version, report.Dump)
+	for _, difference := range report.Differences {
+		fmt.Fprintln(writer, difference)
+	}
+	if report.Drift {
+		fmt.Fprintf(writer, "drift: %d differences\n", len(report.Differences))
+		return
+	}
+	fmt.Fprintln(writer, "no drift")
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Server health checks.
+// This file registers the readiness checks bmsd exposes under /readyz. Each
+// subsystem that can fail after startup adds its check here, marked critical
+// only when the server cannot do useful work without it.
+
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/audit"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/health"
+	"github.com/SandorMiskey/bms-core/internal/lifecycle"
+)
+
+// Health checks. {{{
+
+const (
+	auditCheckName     = "audit"
+	auditCheckCacheTTL = 10 * time.Second
+	databaseCheckName  = "database"
+	healthWatchName    = "health-watch"
+)
+
+// registerHealthChecks adds the server readiness checks to registry.
+func registerHealthChecks(registry *health.Registry, database *db.DB, auditLog *audit.Log) error {
+	if err := registry.Register(databaseCheckName, database, health.CheckOptions{Critical: true}); err != nil {
+		return err
+	}
+	if auditLog != nil {
+		// The audit file being removed or rotated away means new entries
+		// land in an unlinked inode; report it without failing readiness.
+		check := health.CheckerFunc(func(context.Context) error {
+			_, err := os.Stat(auditLog.Path())
+			return err
+		})
+		options := health.CheckOptions{CacheTTL: auditCheckCacheTTL}
+		if err := registry.Register(auditCheckName, check, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// healthWatchComponent runs the periodic readiness checks that move the
+// state between ready and degraded.
+func healthWatchComponent(state *health.State, checks *health.Registry) lifecycle.Component {
+	var stop context.CancelFunc
+	return lifecycle.Component{
+		Name: healthWatchName,
+		Start: func(context.Context) error {
+			var ctx context.Context
+			ctx, stop = context.WithCancel(context.Background())
+			go state.Watch(ctx, checks, healthWatchInterval)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			stop()
+			return nil
+		},
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
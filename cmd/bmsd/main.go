@@ -3,8 +3,11 @@
 
 // Server entry point.
 // This file defines the bmsd main function, which resolves configuration,
-// initializes structured logging with server defaults, emits startup
-// diagnostics (redacted), and exits on configuration errors.
+// initializes logging, runs listeners and background work as lifecycle
+// components, and shuts them down on a signal or a fatal error. Subcommands
+// and server features live in their own files: doctor.go, migrate.go,
+// drift.go, backup.go, database.go, admin.go, audit.go, systemd.go, and
+// tls.go.
 
 package main
 
@@ -12,6 +15,7 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -19,20 +23,60 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/SandorMiskey/bms-core/internal/admin"
+	"github.com/SandorMiskey/bms-core/internal/buildinfo"
+	"github.com/SandorMiskey/bms-core/internal/certs"
 	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
 	"github.com/SandorMiskey/bms-core/internal/errtext"
 	"github.com/SandorMiskey/bms-core/internal/health"
+	"github.com/SandorMiskey/bms-core/internal/lifecycle"
 	"github.com/SandorMiskey/bms-core/internal/logging"
+	"github.com/SandorMiskey/bms-core/internal/metrics"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+	"github.com/SandorMiskey/bms-core/internal/socket"
+	"github.com/SandorMiskey/bms-core/internal/systemd"
 )
 
 // Main entry point. {{{
 
+const (
+	defaultLogBufferSize   = 2000
+	defaultShutdownTimeout = 15 * time.Second
+	healthWatchInterval    = 15 * time.Second
+)
+
 func main() {
+	os.Exit(run())
+}
+
+// run starts the server and returns the process exit code, so deferred
+// cleanup (audit, logger) runs before the process exits.
+func run() int {
 	configPath := flag.String("config", "", "path to config.toml")
+	showVersion := flag.Bool("version", false, "print version and exit")
 	flag.Parse()
+	if *showVersion {
+		fmt.Println("bmsd", buildinfo.Get())
+		return 0
+	}
+	switch flag.Arg(0) {
+	case "doctor":
+		return runDoctor(*configPath, flag.Args()[1:])
+	case "migrate":
+		return runMigrate(*configPath, flag.Args()[1:])
+	case "db":
+		return runDB(*configPath, flag.Args()[1:])
+	case "backup":
+		return runBackup(*configPath, flag.Args()[1:])
+	case "restore":
+		return runRestore(*configPath, flag.Args()[1:])
+	}
 
 	configResult, path, warnings, err := config.ResolveConfigDiagnostics(*configPath, config.ConfigOverlay{}, config.ConfigOverlay{})
-	logger, format := initLogger(configResult, logging.ComponentServer)
+	logRuntime, format := initLogger(configResult, logging.ComponentServer)
+	defer closeLogger(logRuntime)
+	logger := logRuntime.Logger
 
 	if err != nil {
 		var validationErrors config.ValidationErrors
@@ -42,34 +86,168 @@ func main() {
 		} else {
 			logger.Error(errtext.ErrConfigResolutionFailed, "error", err)
 		}
-		os.Exit(1)
+		return 1
 	}
 
 	logging.LogConfigDiagnostics(logger, format, configResult, path, warnings)
 
-	healthState := health.NewState()
-	healthServer := startHealthServer(logger, configResult.REST.Address, healthState)
-	healthState.SetReady(true)
+	auditLog, err := openAudit(logger, configResult, path)
+	if err != nil {
+		logger.Error(errtext.ErrOpenAuditLog, "error", err)
+		return 1
+	}
+	defer closeAudit(logger, auditLog)
+
+	database, err := db.Open(configResult.Database)
+	if err != nil {
+		logger.Error(errtext.ErrDatabaseOpen, "error", err)
+		return 1
+	}
+	var migrator *migrate.Migrator
+	if configResult.Database.AutoMigrate {
+		migrator, err = migrate.New(database, migrate.Source(configResult.Database), logger)
+		if err != nil {
+			logger.Error(errtext.ErrMigrationInvalid, "error", err)
+			return 1
+		}
+	}
+
+	healthState := health.NewState(logger)
+	healthChecks := health.NewRegistry()
+	if err := registerHealthChecks(healthChecks, database, auditLog); err != nil {
+		logger.Error(errtext.ErrRegisterHealthCheck, "error", err)
+		return 1
+	}
+	healthMux := health.NewMux(healthState, healthChecks)
+	serverMetrics := newServerMetrics(healthState, healthChecks)
+	healthMux.Handle(metrics.Path, metrics.Handler(serverMetrics.registry))
+	healthMux.Handle("GET "+buildinfo.Path, buildinfo.Handler())
+	adminOptions := admin.Options{
+		Audit:    auditLog,
+		Checks:   healthChecks,
+		Failures: admin.NewAuthFailures(auditLog),
+		Health:   healthState,
+		Logs:     logRuntime.Buffer,
+		Levels:   logRuntime.Levels,
+		Token:    configResult.Admin.Token,
+	}
+
+	notifier := systemd.NewNotifier(logger)
+	notifier.Follow(healthState)
+	activated, err := systemd.Listeners()
+	if err != nil {
+		logger.Error(errtext.ErrSocketActivation, "error", err)
+		return 1
+	}
+	watchdog, err := watchdogComponent(notifier, healthState)
+	if err != nil {
+		logger.Error(errtext.ErrInvalidWatchdog, "error", err)
+		return 1
+	}
+
+	manager := lifecycle.New(logger)
+	var components []lifecycle.Component
+	if watchdog != nil {
+		components = append(components, *watchdog)
+	}
+	components = append(components, databaseComponent(logger, database), healthWatchComponent(healthState, healthChecks))
+	restDependsOn := []string{databaseComponentName}
+	if migrator != nil {
+		components = append(components, migrationsComponent(logger, migrator))
+		restDependsOn = append(restDependsOn, migrationsComponentName)
+	}
+	if configResult.Server.ID != "" {
+		components = append(components, identityComponent(logger, database, configResult.Server.ID, restDependsOn))
+		restDependsOn = append(restDependsOn, identityComponentName)
+	}
+	if configResult.Backup.Interval != "" {
+		components = append(components, backupComponent(logger, database, configResult.Backup, configResult.Server.ID, restDependsOn))
+	}
+	restHandler := serverMetrics.http.Wrap(listenerREST, buildinfo.Headers(healthMux))
+	listeners := []struct {
+		name      string
+		server    *http.Server
+		socket    config.SocketConfig
+		tls       config.TLSConfig
+		dependsOn []string
+	}{
+		{listenerREST, newRESTServer(logger, configResult.REST.Address, len(activated[listenerREST]) > 0, restHandler), configResult.REST.Socket, configResult.REST.TLS, restDependsOn},
+		{listenerAdmin, newAdminServer(logger, configResult.Admin, adminOptions, serverMetrics.http), config.SocketConfig{}, configResult.Admin.TLS, nil},
+	}
+	var tlsServers []*certs.Server
+	for _, listener := range listeners {
+		if listener.server == nil {
+			continue
+		}
+		tlsServer, err := enableTLS(logger, listener.name, listener.server, listener.tls, path)
+		if err != nil {
+			logger.Error(errtext.ErrServerStartFailed, "listener", listener.name, "error", err)
+			return 1
+		}
+		if tlsServer != nil {
+			tlsServers = append(tlsServers, tlsServer)
+		}
+		components = append(components, serverComponent(manager, activated, listener.name, listener.server, listener.socket, listener.dependsOn...))
+	}
+	if len(tlsServers) > 0 {
+		components = append(components, tlsWatchComponent(tlsServers, serverMetrics.tlsReloads))
+	}
+	closeUnusedListeners(logger, activated)
+	for _, component := range components {
+		if err := manager.Add(component); err != nil {
+			logger.Error(errtext.ErrServerStartFailed, "error", err)
+			return 1
+		}
+	}
+
+	shutdownTimeout := parseDuration(configResult.Server.ShutdownTimeout, defaultShutdownTimeout)
+	if err := manager.Start(context.Background()); err != nil {
+		logger.Error(errtext.ErrServerStartFailed, "error", err)
+		healthState.Set(health.PhaseStopped, "startup failed")
+		return 1
+	}
+	healthState.Set(health.PhaseReady, "startup complete")
+
+	exitCode := 0
+	reason := "shutdown signal received"
+	if fatal := waitForShutdown(logger, logRuntime.Sinks, serverMetrics.reopens, manager.Fatal()); fatal != nil {
+		exitCode = 1
+		reason = fatal.Error()
+	}
 
-	waitForShutdown(logger, healthServer)
+	// Readiness drops first so load balancers stop routing before the
+	// listeners close; a failed component skips the delay.
+	healthState.Set(health.PhaseDraining, reason)
+	if exitCode == 0 {
+		time.Sleep(parseDuration(configResult.Server.DrainDelay, 0))
+	}
+	stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := manager.Stop(stopCtx); err != nil {
+		exitCode = 1
+	}
+	healthState.Set(health.PhaseStopped, "shutdown complete")
+	return exitCode
 }
 
-func initLogger(cfg config.Config, component logging.Component) (*slog.Logger, config.LogFormat) {
+func initLogger(cfg config.Config, component logging.Component) (*logging.Runtime, config.LogFormat) {
 	defaults := logging.LoggerDefaults{
+		BufferSize: defaultLogBufferSize,
 		Fields: logging.DefaultFields{
 			Component:   component,
 			Environment: string(cfg.Server.Environment),
 			ServerID:    cfg.Server.ID,
+			Version:     buildinfo.Get().Version,
 		},
 		Format: config.LogFormatJSON,
 		Level:  config.LogLevelInfo,
 	}
 
-	logger, err := logging.NewLogger(cfg.Logging, defaults)
+	runtime, err := logging.NewRuntime(cfg.Logging, defaults)
 	if err != nil {
 		fallback := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
 		fallback.Error(errtext.ErrLoggerInitFailed, "error", err)
-		logger = fallback
+		runtime = &logging.Runtime{Logger: fallback}
 	}
 
 	format := cfg.Logging.Format
@@ -77,47 +255,70 @@ func initLogger(cfg config.Config, component logging.Component) (*slog.Logger, c
 		format = defaults.Format
 	}
 
-	return logger, format
+	return runtime, format
 }
 
-func startHealthServer(logger *slog.Logger, address string, state *health.State) *http.Server {
-	if address == "" {
+func closeLogger(runtime *logging.Runtime) {
+	if err := runtime.Close(); err != nil {
+		_, _ = os.Stderr.WriteString(err.Error() + "\n")
+	}
+}
+
+// newRESTServer returns the REST listener server, or nil when rest.address
+// is empty and systemd passed no REST socket.
+func newRESTServer(logger *slog.Logger, address string, activated bool, handler http.Handler) *http.Server {
+	if address == "" && !activated {
 		logger.Warn("health server disabled", "reason", "rest address is empty")
 		return nil
 	}
 
-	server := &http.Server{
+	return &http.Server{
 		Addr:              address,
-		Handler:           health.NewMux(state),
+		Handler:           handler,
+		ConnContext:       socket.ConnContext,
 		ReadHeaderTimeout: 5 * time.Second,
 		ReadTimeout:       10 * time.Second,
 		WriteTimeout:      10 * time.Second,
 		IdleTimeout:       30 * time.Second,
 	}
-
-	go func() {
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			logger.Error(errtext.ErrHealthServerServeFailed, "error", err, "address", address)
-		}
-	}()
-
-	return server
 }
 
-func waitForShutdown(logger *slog.Logger, server *http.Server) {
+// waitForShutdown blocks until SIGINT or SIGTERM or a fatal component
+// error, reopening log files on SIGHUP meanwhile. It returns the fatal
+// error, if any.
+func waitForShutdown(logger *slog.Logger, sinks *logging.Sinks, reopens *metrics.Counter, fatal <-chan error) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	<-ctx.Done()
-	if server == nil {
-		return
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-fatal:
+			return err
+		case <-hangup:
+			if err := sinks.Reopen(); err != nil {
+				logger.Error(errtext.ErrLogReopenFailed, "error", err)
+				reopens.Inc(resultFailure)
+				continue
+			}
+			reopens.Inc(resultSuccess)
+		}
 	}
+}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		logger.Error(errtext.ErrHealthServerShutdownFailed, "error", err)
+// parseDuration parses a validated config duration, using fallback when it
+// is empty.
+func parseDuration(value string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
 	}
+	return duration
 }
 
 // }}}
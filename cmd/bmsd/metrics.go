@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Server metrics.
+// This file builds the bmsd metrics registry: Go runtime and process
+// metrics, build info, HTTP request metrics for each listener, log reopen
+// and TLS certificate reload counts, and the latest health check results and
+// lifecycle state, read at scrape time.
+
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/buildinfo"
+	"github.com/SandorMiskey/bms-core/internal/health"
+	"github.com/SandorMiskey/bms-core/internal/metrics"
+)
+
+// Server metrics. {{{
+
+const (
+	listenerREST = "rest"
+
+	resultFailure = "failure"
+	resultSuccess = "success"
+)
+
+var healthPhases = []health.Phase{
+	health.PhaseStarting,
+	health.PhaseReady,
+	health.PhaseDegraded,
+	health.PhaseDraining,
+	health.PhaseStopped,
+}
+
+type serverMetrics struct {
+	registry   *metrics.Registry
+	http       *metrics.HTTPMetrics
+	reopens    *metrics.Counter
+	tlsReloads *metrics.Counter
+}
+
+func newServerMetrics(state *health.State, checks *health.Registry) *serverMetrics {
+	registry := metrics.NewRegistry()
+	metrics.RegisterRuntime(registry)
+
+	reopens := registry.Counter("bms_log_reopens_total", "Log file reopens on SIGHUP, by result.", "result")
+	reopens.Add(0, resultSuccess)
+	reopens.Add(0, resultFailure)
+	tlsReloads := registry.Counter("bms_tls_reloads_total", "TLS certificate reloads after the files changed, by listener and result.", "listener", "result")
+
+	checkUp := registry.Gauge("bms_health_check_up", "Whether the last run of a health check passed (1) or failed (0).", "check", "critical")
+	checkDuration := registry.Gauge("bms_health_check_duration_seconds", "Duration of the last run of a health check.", "check")
+	phase := registry.Gauge("bms_health_state", "Current lifecycle state (1 for the active state).", "state")
+
+	build := buildinfo.Get()
+	registry.Gauge("bms_build_info", "Build metadata of the running server (always 1).", "version", "commit", "go_version", "api_version").
+		Set(1, build.Version, build.Commit, build.GoVersion, strconv.Itoa(build.APIVersion))
+
+	registry.OnScrape(func() {
+		for _, result := range checks.Results() {
+			up := 0.0
+			if result.OK() {
+				up = 1
+			}
+			checkUp.Set(up, result.Name, strconv.FormatBool(result.Critical))
+			if duration, err := time.ParseDuration(result.Duration); err == nil {
+				checkDuration.Set(duration.Seconds(), result.Name)
+			}
+		}
+		current := state.Snapshot().State
+		for _, candidate := range healthPhases {
+			active := 0.0
+			if candidate == current {
+				active = 1
+			}
+			phase.Set(active, string(candidate))
+		}
+	})
+
+	return &serverMetrics{
+		registry:   registry,
+		http:       metrics.NewHTTPMetrics(registry),
+		reopens:    reopens,
+		tlsReloads: tlsReloads,
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Migrate subcommand.
+// This file implements `bmsd migrate up|down N|status|force V`, which runs the
+// embedded migrations (or the tree in database.migrations) against the
+// configured database without starting the server. It replaces the migrate
+// CLI for operators; the Makefile targets remain for schema dumps.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/logging"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+)
+
+// Migrate command. {{{
+
+const migrateUsage = "usage: bmsd [--config path] migrate up | down N | status [--json] | force V"
+
+// errMigrateUsage marks bad migrate arguments.
+var errMigrateUsage = errors.New("usage")
+
+func runMigrate(configPath string, args []string) int {
+	configResult, _, err := config.ResolveConfigAndValidate(configPath, config.ConfigOverlay{}, config.ConfigOverlay{})
+	logRuntime, _ := initLogger(configResult, logging.ComponentDatabase)
+	defer closeLogger(logRuntime)
+	logger := logRuntime.Logger
+	if err != nil {
+		logger.Error(errtext.ErrConfigValidationFailed, "error", err)
+		return 1
+	}
+
+	database, err := db.Open(configResult.Database)
+	if err != nil {
+		logger.Error(errtext.ErrDatabaseOpen, "error", err)
+		return 1
+	}
+	defer database.Close()
+	ctx := context.Background()
+	if err := database.Connect(ctx); err != nil {
+		logger.Error(errtext.ErrDatabaseConnect, "error", err)
+		return 1
+	}
+	migrator, err := migrate.New(database, migrate.Source(configResult.Database), logger)
+	if err != nil {
+		logger.Error(errtext.ErrMigrationInvalid, "error", err)
+		return 1
+	}
+
+	if err := migrateCommand(ctx, migrator, os.Stdout, args); err != nil {
+		if errors.Is(err, errMigrateUsage) {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+		} else {
+			logger.Error(errtext.ErrMigrationFailed, "error", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+func migrateCommand(ctx context.Context, migrator *migrate.Migrator, writer io.Writer, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+	switch command, rest := args[0], args[1:]; command {
+	case "up":
+		if len(rest) > 0 {
+			return errMigrateUsage
+		}
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(writer, "applied      %s\n", migration)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(writer, "no pending migrations")
+		}
+		return err
+	case "down":
+		count, err := positiveArg(rest)
+		if err != nil {
+			return err
+		}
+		reverted, err := migrator.Down(ctx, int(count))
+		for _, migration := range reverted {
+			fmt.Fprintf(writer, "rolled back  %s\n", migration)
+		}
+		return err
+	case "force":
+		version, err := positiveArg(rest)
+		if err != nil && !(len(rest) == 1 && rest[0] == "0") {
+			return err
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "version set to %d\n", version)
+		return nil
+	case "status":
+		flags := flag.NewFlagSet("migrate status", flag.ContinueOnError)
+		raw := flags.Bool("json", false, "print JSON")
+		if err := flags.Parse(rest); err != nil || flags.NArg() > 0 {
+			return errMigrateUsage
+		}
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if *raw {
+			encoder := json.NewEncoder(writer)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(status)
+		}
+		printMigrateStatus(writer, status)
+		return nil
+	default:
+		return errMigrateUsage
+	}
+}
+
+// positiveArg parses the single positive number in args.
+func positiveArg(args []string) (uint64, error) {
+	if len(args) != 1 {
+		return 0, errMigrateUsage
+	}
+	value, err := strconv.ParseUint(args[0], 10, 63)
+	if err != nil || value == 0 {
+		return 0, errMigrateUsage
+	}
+	return value, nil
+}
+
+func printMigrateStatus(writer io.Writer, status migrate.Status) {
+	for _, migration := range status.Migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(writer, "%-9s%s\n", state, migration.Migration)
+	}
+	summary := fmt.Sprintf("version %d, %d pending", status.Version, status.Pending)
+	switch {
+	case status.Dirty:
+		summary += ", dirty (fix the schema, then run bmsd migrate force)"
+	case status.Unknown:
+		summary += ", version unknown to this build"
+	}
+	fmt.Fprintln(writer, summary)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Service manager integration.
+// This file wires bmsd into systemd: health state changes become READY=1,
+// STOPPING=1, and STATUS= notifications, the watchdog runs as the first
+// lifecycle component so it covers startup and shutdown, and listeners use
+// socket-activated sockets named after them (FileDescriptorName=rest) in
+// place of binding their configured address. Outside systemd all of this
+// is inert.
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/health"
+	"github.com/SandorMiskey/bms-core/internal/lifecycle"
+	"github.com/SandorMiskey/bms-core/internal/socket"
+	"github.com/SandorMiskey/bms-core/internal/systemd"
+)
+
+// Service manager. {{{
+
+const systemdWatchdogName = "systemd-watchdog"
+
+// watchdogComponent sends watchdog keep-alives every interval until the
+// server stops, or returns nil when the watchdog is disabled.
+func watchdogComponent(notifier *systemd.Notifier, state *health.State) (*lifecycle.Component, error) {
+	interval, err := systemd.WatchdogInterval()
+	if err != nil || interval == 0 || notifier == nil {
+		return nil, err
+	}
+
+	alive := func() bool {
+		return state.Snapshot().State != health.PhaseStopped
+	}
+	var stop context.CancelFunc
+	return &lifecycle.Component{
+		Name: systemdWatchdogName,
+		Start: func(context.Context) error {
+			var ctx context.Context
+			ctx, stop = context.WithCancel(context.Background())
+			go notifier.RunWatchdog(ctx, interval, alive)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			stop()
+			return nil
+		},
+	}, nil
+}
+
+// serverComponent serves server on the socket-activated listener named
+// name, or binds server.Addr (host:port or unix:///path) when systemd
+// passed none.
+func serverComponent(manager *lifecycle.Manager, activated systemd.Activated, name string, server *http.Server, settings config.SocketConfig, dependsOn ...string) lifecycle.Component {
+	listen := func() (net.Listener, error) {
+		return socket.Listen(server.Addr, settings)
+	}
+	if listener := activated.Take(name); listener != nil {
+		listen = func() (net.Listener, error) {
+			return listener, nil
+		}
+	}
+	return manager.HTTPServerListen(name, server, listen, dependsOn...)
+}
+
+// closeUnusedListeners closes socket-activated listeners no server took.
+func closeUnusedListeners(logger *slog.Logger, activated systemd.Activated) {
+	for _, name := range activated.Close() {
+		logger.Warn("socket-activated listener not used", "name", name, "reason", "no server with this name is enabled")
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
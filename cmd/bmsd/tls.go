@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Listener TLS.
+// This file loads the TLS settings of each listener, logs the certificate
+// fingerprint and expiry at startup (the fingerprint of a self-signed
+// certificate is what clients pin in client.server.fingerprint; the
+// certificate is kept in tls/ next to the config file so the pin survives
+// restarts), and runs the component that reloads certificate files when
+// they change and counts the reloads.
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+
+	"github.com/SandorMiskey/bms-core/internal/certs"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/lifecycle"
+	"github.com/SandorMiskey/bms-core/internal/metrics"
+)
+
+// Listener TLS. {{{
+
+const (
+	tlsWatchName  = "tls-watch"
+	selfSignedDir = "tls"
+)
+
+// enableTLS sets server up to serve TLS from settings and returns the
+// loaded certificates, or nil when TLS is off for the listener. Self-signed
+// certificates are kept next to the config file at configPath.
+func enableTLS(logger *slog.Logger, name string, server *http.Server, settings config.TLSConfig, configPath string) (*certs.Server, error) {
+	if server == nil || !settings.Enabled() {
+		return nil, nil
+	}
+	var hosts []string
+	if host, _, err := net.SplitHostPort(server.Addr); err == nil {
+		hosts = append(hosts, host)
+	}
+
+	loaded, err := certs.NewServer(name, settings, hosts, filepath.Join(filepath.Dir(configPath), selfSignedDir), logger)
+	if err != nil {
+		return nil, err
+	}
+	server.TLSConfig = loaded.TLSConfig()
+	logger.Info("tls enabled",
+		"listener", name,
+		"self_signed", settings.SelfSigned,
+		"mutual", settings.ClientCA != "",
+		"fingerprint", loaded.Fingerprint(),
+		"not_after", loaded.NotAfter(),
+	)
+	return loaded, nil
+}
+
+// tlsWatchComponent reloads changed certificate files for every TLS
+// listener and counts the reloads by result.
+func tlsWatchComponent(servers []*certs.Server, reloads *metrics.Counter) lifecycle.Component {
+	for _, server := range servers {
+		reloads.Add(0, server.Name(), resultSuccess)
+		reloads.Add(0, server.Name(), resultFailure)
+	}
+	var stop context.CancelFunc
+	return lifecycle.Component{
+		Name: tlsWatchName,
+		Start: func(context.Context) error {
+			var ctx context.Context
+			ctx, stop = context.WithCancel(context.Background())
+			for _, server := range servers {
+				name := server.Name()
+				go server.Watch(ctx, certs.DefaultReloadInterval, func(err error) {
+					result := resultSuccess
+					if err != nil {
+						result = resultFailure
+					}
+					reloads.Inc(name, result)
+				})
+			}
+			return nil
+		},
+		Stop: func(context.Context) error {
+			stop()
+			return nil
+		},
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
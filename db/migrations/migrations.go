@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Embedded migrations.
+// This file embeds the migration trees so bmsd can migrate without the
+// external migrate CLI. shared/ holds the reference definitions and sqlite/
+// and postgres/ the variants applied to each driver; every tree uses the
+// same <timestamp>_<slug>.{up,down}.sql names (see internal/migrate/lint).
+
+package migrations
+
+import "embed"
+
+// Migration trees. {{{
+
+// FS holds the shared, sqlite, and postgres migration trees.
+//
+//go:embed shared sqlite postgres
+var FS embed.FS
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
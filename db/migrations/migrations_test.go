@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Migration tree tests.
+// This file runs the migration lint against the embedded trees and the
+// schema dumps in db/schema, so go test fails on the same problems as
+// `bms dev migrate-check`.
+
+package migrations
+
+import (
+	"os"
+	"testing"
+
+	"github.com/SandorMiskey/bms-core/internal/migrate/lint"
+)
+
+// Lint tests. {{{
+
+func TestLint(t *testing.T) {
+	for _, problem := range lint.Check(FS) {
+		t.Errorf("db/migrations/%s", problem.Error())
+	}
+	for _, problem := range lint.CheckSchemaDumps(FS, os.DirFS("../schema")) {
+		t.Errorf("db/schema/%s", problem.Error())
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
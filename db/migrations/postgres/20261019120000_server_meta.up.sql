@@ -0,0 +1,11 @@
+-- SPDX-License-Identifier: Apache-2.0
+-- Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+-- Server metadata.
+-- Key/value settings owned by the server instance, such as its identity.
+
+CREATE TABLE server_meta (
+    key        text        NOT NULL PRIMARY KEY,
+    value      text        NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,12 @@
+-- SPDX-License-Identifier: Apache-2.0
+-- Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+-- Server metadata.
+-- Key/value settings owned by the server instance, such as its identity.
+-- Reference definition; sqlite/ and postgres/ hold the applied variants.
+
+CREATE TABLE server_meta (
+    key        TEXT      NOT NULL PRIMARY KEY,
+    value      TEXT      NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
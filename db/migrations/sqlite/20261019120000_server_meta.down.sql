@@ -0,0 +1,4 @@
+-- SPDX-License-Identifier: Apache-2.0
+-- Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+DROP TABLE server_meta;
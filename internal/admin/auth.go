@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Admin authentication.
+// This file defines RequireToken, a middleware that guards admin routes with
+// a static bearer token from [admin] token. Tokens are compared in constant
+// time, and failures return 401 without revealing which part was wrong.
+// Requests that present a wrong token are recorded in the audit log through
+// AuthFailures, which writes the first failure from each host per window and
+// summarizes the rest with a count once the window closes and that host or
+// another one fails again, so a client retrying a bad token cannot flood the
+// audit log with synced writes.
+
+package admin
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/audit"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Admin authentication. {{{
+
+const (
+	adminActor        = "admin"
+	authFailureWindow = time.Minute
+	bearerPrefix      = "Bearer "
+)
+
+// RequireToken rejects requests that do not carry the bearer token. Failed
+// attempts that present credentials are recorded through failures (which may
+// be nil).
+func RequireToken(token string, failures *AuthFailures, next http.Handler) http.Handler {
+	expected := []byte(token)
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		header := request.Header.Get("Authorization")
+		presented, ok := strings.CutPrefix(header, bearerPrefix)
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(presented), expected) != 1 {
+			if header != "" {
+				failures.record(request)
+			}
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="bms-admin"`)
+			writeError(writer, http.StatusUnauthorized, errtext.ErrAdminUnauthorized)
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// }}}
+// Auth failure auditing. {{{
+
+// AuthFailures rate-limits audit records for failed admin authentication per
+// source host. Share one between every route guarded by the same token.
+type AuthFailures struct {
+	mu       sync.Mutex
+	auditLog *audit.Log
+	window   time.Duration
+	hosts    map[string]*authFailure
+	now      func() time.Time
+}
+
+type authFailure struct {
+	first      time.Time
+	suppressed int
+}
+
+// NewAuthFailures returns auth failure auditing into auditLog (which may be
+// nil).
+func NewAuthFailures(auditLog *audit.Log) *AuthFailures {
+	return &AuthFailures{
+		auditLog: auditLog,
+		window:   authFailureWindow,
+		hosts:    make(map[string]*authFailure),
+		now:      time.Now,
+	}
+}
+
+// record counts a failure and writes the audit records that are due. The
+// records are written after the lock is released so a slow sync never
+// blocks other requests.
+func (failures *AuthFailures) record(request *http.Request) {
+	if failures == nil {
+		return
+	}
+	host := remoteHost(request)
+	now := failures.now()
+
+	failures.mu.Lock()
+	events := failures.sweep(now)
+	if entry, ok := failures.hosts[host]; ok {
+		entry.suppressed++
+	} else {
+		failures.hosts[host] = &authFailure{first: now}
+		events = append(events, audit.Event{
+			Action:  audit.ActionAdminAuthFailed,
+			Source:  host,
+			Outcome: audit.OutcomeFailure,
+			Details: map[string]any{"method": request.Method, "path": request.URL.Path},
+		})
+	}
+	failures.mu.Unlock()
+
+	for _, event := range events {
+		_, _ = failures.auditLog.Record(event)
+	}
+}
+
+// sweep drops hosts whose window has closed and returns a summary event for
+// each one that had failures suppressed.
+func (failures *AuthFailures) sweep(now time.Time) []audit.Event {
+	var events []audit.Event
+	for host, entry := range failures.hosts {
+		if now.Sub(entry.first) < failures.window {
+			continue
+		}
+		delete(failures.hosts, host)
+		if entry.suppressed == 0 {
+			continue
+		}
+		events = append(events, audit.Event{
+			Action:  audit.ActionAdminAuthFailed,
+			Source:  host,
+			Outcome: audit.OutcomeFailure,
+			Details: map[string]any{"count": entry.suppressed, "window": failures.window.String()},
+		})
+	}
+	return events
+}
+
+// remoteHost returns the client address without its port.
+func remoteHost(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
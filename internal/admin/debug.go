@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Runtime debug routes.
+// This file defines the mux for the separate admin listener: net/http/pprof
+// under /debug/pprof/, expvar at /debug/vars, and goroutine dumps and build
+// info under /debug/. Each group is opt-in, and with RequireAuth every route
+// needs the admin bearer token. These routes never go on the public REST
+// listener.
+
+package admin
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime/debug"
+	runtimepprof "runtime/pprof"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Debug routes. {{{
+
+const (
+	BuildInfoPath  = "/debug/buildinfo"
+	ExpvarPath     = "/debug/vars"
+	GoroutinesPath = "/debug/goroutines"
+	PprofPath      = "/debug/pprof/"
+
+	goroutineDebugFull = 2
+)
+
+// DebugOptions selects what the admin listener serves.
+type DebugOptions struct {
+	Debug       bool          // Goroutine dumps and build info.
+	Expvar      bool          // expvar at /debug/vars.
+	Failures    *AuthFailures // Audits failed authentication (nil disables auditing).
+	Pprof       bool          // net/http/pprof under /debug/pprof/.
+	RequireAuth bool          // Require Token on every route.
+	Token       string        // Admin bearer token.
+}
+
+// NewDebugMux returns the admin listener mux with the enabled routes.
+func NewDebugMux(options DebugOptions) *http.ServeMux {
+	mux := http.NewServeMux()
+	guard := func(next http.Handler) http.Handler {
+		if !options.RequireAuth {
+			return next
+		}
+		return RequireToken(options.Token, options.Failures, next)
+	}
+
+	if options.Pprof {
+		mux.Handle(PprofPath, guard(http.HandlerFunc(pprof.Index)))
+		mux.Handle(PprofPath+"cmdline", guard(http.HandlerFunc(pprof.Cmdline)))
+		mux.Handle(PprofPath+"profile", guard(http.HandlerFunc(pprof.Profile)))
+		mux.Handle(PprofPath+"symbol", guard(http.HandlerFunc(pprof.Symbol)))
+		mux.Handle(PprofPath+"trace", guard(http.HandlerFunc(pprof.Trace)))
+	}
+	if options.Expvar {
+		mux.Handle("GET "+ExpvarPath, guard(expvar.Handler()))
+	}
+	if options.Debug {
+		mux.Handle("GET "+GoroutinesPath, guard(http.HandlerFunc(getGoroutines)))
+		mux.Handle("GET "+BuildInfoPath, guard(http.HandlerFunc(getBuildInfo)))
+	}
+	return mux
+}
+
+// getGoroutines writes a full stack dump of every goroutine.
+func getGoroutines(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(writer, goroutineDebugFull)
+}
+
+// getBuildInfo writes the module build info embedded in the binary.
+func getBuildInfo(writer http.ResponseWriter, _ *http.Request) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		writeError(writer, http.StatusNotFound, errtext.ErrBuildInfoUnavailable)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = writer.Write([]byte(info.String()))
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
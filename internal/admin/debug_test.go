@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Runtime debug route tests.
+// This file verifies the admin listener mounts only the enabled route
+// groups and enforces the admin token when auth is required.
+
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Debug route tests. {{{
+
+func TestDebugMuxToggles(t *testing.T) {
+	mux := NewDebugMux(DebugOptions{Debug: true})
+
+	cases := map[string]int{
+		GoroutinesPath: http.StatusOK,
+		ExpvarPath:     http.StatusNotFound,
+		PprofPath:      http.StatusNotFound,
+	}
+	for path, status := range cases {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != status {
+			t.Fatalf("%s: expected status %d, got %d", path, status, recorder.Code)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, GoroutinesPath, nil))
+	if !strings.Contains(recorder.Body.String(), "goroutine ") {
+		t.Fatalf("expected a goroutine dump, got: %q", recorder.Body.String())
+	}
+}
+
+func TestDebugMuxAuth(t *testing.T) {
+	mux := NewDebugMux(DebugOptions{Expvar: true, Pprof: true, RequireAuth: true, Token: "s3cret"})
+
+	for _, path := range []string{ExpvarPath, PprofPath} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: expected status 401, got %d", path, recorder.Code)
+		}
+
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("Authorization", "Bearer s3cret")
+		recorder = httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200 with token, got %d", path, recorder.Code)
+		}
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
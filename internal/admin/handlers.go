@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Admin HTTP handlers.
+// This file defines the admin routes mounted on the admin listener next to
+// the debug routes. GET and PUT on /admin/log-level read and change the base
+// log level, /admin/log-level/{component} does the same for a single
+// component, and /admin/components lists the registered log components.
+// /debug/readyz and /debug/readyz/{check} serve readiness with the check
+// error text that the public REST endpoints omit.
+// Requests and responses are JSON; every route requires the admin bearer
+// token, and level changes are recorded in the audit log before they apply.
+// The recent-log route (/debug/logs) lives in logs.go.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/audit"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/health"
+	"github.com/SandorMiskey/bms-core/internal/logging"
+)
+
+// Admin routes. {{{
+
+const (
+	ComponentsPath = "/admin/components"
+	LogLevelPath   = "/admin/log-level"
+	ReadyzPath     = "/debug" + health.ReadyzPath
+	maxRequestBody = 4096
+)
+
+// LogLevelRequest is the PUT body for log level changes.
+type LogLevelRequest struct {
+	Level string `json:"level"`         // Level name (`debug`, `info`, `warn`, `error`).
+	TTL   string `json:"ttl,omitempty"` // Optional duration after which the change reverts.
+}
+
+// ErrorResponse is returned with non-2xx statuses.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Options selects what the admin routes expose.
+type Options struct {
+	Audit    *audit.Log          // Audit log for level changes (nil disables auditing).
+	Checks   *health.Registry    // Health checks for /debug/readyz (nil disables the route).
+	Failures *AuthFailures       // Audits failed authentication (nil disables auditing).
+	Health   *health.State       // Lifecycle state for /debug/readyz.
+	Logs     *logging.RingBuffer // Recent-log buffer for /debug/logs (nil disables the route).
+	Levels   *logging.Levels     // Runtime levels for /admin/log-level (nil disables the route).
+	Token    string              // Bearer token required by every admin route.
+}
+
+// Register mounts the admin routes on mux. It returns false and mounts
+// nothing when the token is empty, so admin routes are never left
+// unauthenticated.
+func Register(mux *http.ServeMux, options Options) bool {
+	if options.Token == "" {
+		return false
+	}
+
+	guard := func(next http.Handler) http.Handler {
+		return RequireToken(options.Token, options.Failures, next)
+	}
+	if options.Levels != nil {
+		mux.Handle("GET "+LogLevelPath, guard(getLogLevel(options.Levels)))
+		mux.Handle("PUT "+LogLevelPath, guard(putLogLevel(options.Levels, options.Audit)))
+		mux.Handle("GET "+LogLevelPath+"/{component}", guard(getLogLevel(options.Levels)))
+		mux.Handle("PUT "+LogLevelPath+"/{component}", guard(putLogLevel(options.Levels, options.Audit)))
+		mux.Handle("GET "+ComponentsPath, guard(getComponents()))
+	}
+	if options.Logs != nil {
+		mux.Handle("GET "+LogsPath, guard(getLogs(options.Logs)))
+	}
+	if options.Checks != nil {
+		mux.Handle("GET "+ReadyzPath, guard(health.ReadyzHandler(options.Health, options.Checks, true)))
+		mux.Handle("GET "+ReadyzPath+"/{check}", guard(health.ReadyzCheckHandler(options.Checks, true)))
+	}
+	return true
+}
+
+// }}}
+// Log level handlers. {{{
+
+func getLogLevel(levels *logging.Levels) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		component := logging.Component(request.PathValue("component"))
+		if component == "" {
+			writeJSON(writer, http.StatusOK, levels.Snapshot())
+			return
+		}
+		if !logging.ValidComponent(component) {
+			writeError(writer, http.StatusNotFound, fmt.Sprintf("%s: %q", errtext.ErrInvalidLogComponent, component))
+			return
+		}
+		writeJSON(writer, http.StatusOK, levels.Lookup(component))
+	}
+}
+
+func getComponents() http.HandlerFunc {
+	return func(writer http.ResponseWriter, _ *http.Request) {
+		writeJSON(writer, http.StatusOK, logging.RegisteredComponents())
+	}
+}
+
+func putLogLevel(levels *logging.Levels, auditLog *audit.Log) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		component := logging.Component(request.PathValue("component"))
+
+		var body LogLevelRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxRequestBody))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&body); err != nil {
+			writeError(writer, http.StatusBadRequest, fmt.Sprintf("%s: %v", errtext.ErrInvalidAdminRequest, err))
+			return
+		}
+
+		level, err := logging.ParseLevel(body.Level)
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		var ttl time.Duration
+		if body.TTL != "" {
+			ttl, err = time.ParseDuration(body.TTL)
+			if err != nil {
+				writeError(writer, http.StatusBadRequest, fmt.Sprintf("%s: %q", errtext.ErrInvalidLevelTTL, body.TTL))
+				return
+			}
+		}
+		if component != "" && !logging.ValidComponent(component) {
+			writeError(writer, http.StatusBadRequest, fmt.Sprintf("%s: %q", errtext.ErrInvalidLogComponent, component))
+			return
+		}
+		if ttl < 0 {
+			writeError(writer, http.StatusBadRequest, fmt.Sprintf("%s: %s", errtext.ErrInvalidLevelTTL, ttl))
+			return
+		}
+
+		details := map[string]any{"level": logging.LevelName(level)}
+		if component != "" {
+			details["component"] = string(component)
+		}
+		if ttl > 0 {
+			details["ttl"] = ttl.String()
+		}
+		event := audit.Event{Action: audit.ActionAdminLogLevel, Actor: adminActor, Source: remoteHost(request), Details: details}
+		if _, err := auditLog.Record(event); err != nil {
+			writeError(writer, http.StatusInternalServerError, fmt.Sprintf("%s: %v", errtext.ErrAuditRecordFailed, err))
+			return
+		}
+		if err := levels.Set(component, level, ttl); err != nil {
+			writeError(writer, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if component != "" {
+			writeJSON(writer, http.StatusOK, levels.Lookup(component))
+			return
+		}
+		writeJSON(writer, http.StatusOK, levels.Snapshot())
+	}
+}
+
+// }}}
+// Response helpers. {{{
+
+func writeJSON(writer http.ResponseWriter, status int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(value)
+}
+
+func writeError(writer http.ResponseWriter, status int, message string) {
+	writeJSON(writer, status, ErrorResponse{Error: message})
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
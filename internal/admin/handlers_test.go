@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Admin handler tests.
+// This file verifies admin routes require the bearer token, report the
+// current log levels and the level in effect for one component, apply base
+// and component level changes, and record failed logins and level changes in
+// the audit log, one failed login per host per window.
+
+package admin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/audit"
+	"github.com/SandorMiskey/bms-core/internal/logging"
+)
+
+// Admin handler tests. {{{
+
+const testToken = "secret-token"
+
+func newTestMux(t *testing.T) (*http.ServeMux, *logging.Levels) {
+	t.Helper()
+	levels := logging.NewLevels(slog.LevelInfo, nil)
+	mux := http.NewServeMux()
+	if !Register(mux, Options{Levels: levels, Token: testToken}) {
+		t.Fatal("expected admin routes to be registered")
+	}
+	return mux, levels
+}
+
+func TestRegisterRequiresToken(t *testing.T) {
+	if Register(http.NewServeMux(), Options{Levels: logging.NewLevels(slog.LevelInfo, nil)}) {
+		t.Fatal("expected admin routes to stay disabled without a token")
+	}
+}
+
+func TestLogLevelRequiresAuth(t *testing.T) {
+	mux, _ := newTestMux(t)
+
+	for _, header := range []string{"", "Bearer wrong", testToken} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, LogLevelPath, nil)
+		if header != "" {
+			request.Header.Set("Authorization", header)
+		}
+		mux.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status 401 for %q, got %d", header, recorder.Code)
+		}
+	}
+}
+
+func TestLogLevelGetAndPut(t *testing.T) {
+	mux, levels := newTestMux(t)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPut, LogLevelPath+"/database", strings.NewReader(`{"level":"debug","ttl":"1h"}`))
+	request.Header.Set("Authorization", "Bearer "+testToken)
+	mux.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if levels.Effective(logging.ComponentDatabase) != slog.LevelDebug {
+		t.Fatalf("expected database level debug, got %s", levels.Effective(logging.ComponentDatabase))
+	}
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, LogLevelPath, nil)
+	request.Header.Set("Authorization", "Bearer "+testToken)
+	mux.ServeHTTP(recorder, request)
+
+	var snapshot logging.LevelSnapshot
+	if err := json.NewDecoder(recorder.Body).Decode(&snapshot); err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	if snapshot.Level != "info" {
+		t.Fatalf("expected base level info, got %s", snapshot.Level)
+	}
+	entry, ok := snapshot.Components["database"]
+	if !ok || entry.Level != "debug" || entry.Expires == nil {
+		t.Fatalf("unexpected database entry: %+v", snapshot.Components)
+	}
+}
+
+func TestLogLevelGetComponent(t *testing.T) {
+	mux, levels := newTestMux(t)
+	if err := levels.Set(logging.ComponentDatabase, slog.LevelDebug, time.Hour); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	get := func(component string) (int, logging.EffectiveLevel) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, LogLevelPath+"/"+component, nil)
+		request.Header.Set("Authorization", "Bearer "+testToken)
+		mux.ServeHTTP(recorder, request)
+		var effective logging.EffectiveLevel
+		_ = json.NewDecoder(recorder.Body).Decode(&effective)
+		return recorder.Code, effective
+	}
+
+	code, effective := get("database")
+	if code != http.StatusOK || effective.Component != "database" || effective.Level != "debug" || !effective.Override || effective.Expires == nil {
+		t.Fatalf("unexpected database level: %d %+v", code, effective)
+	}
+	code, effective = get("rest")
+	if code != http.StatusOK || effective.Component != "rest" || effective.Level != "info" || effective.Override || effective.Expires != nil {
+		t.Fatalf("unexpected rest level: %d %+v", code, effective)
+	}
+	if code, _ = get("unknown"); code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for an unknown component, got %d", code)
+	}
+}
+
+func TestComponentsList(t *testing.T) {
+	mux, _ := newTestMux(t)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, ComponentsPath, nil)
+	request.Header.Set("Authorization", "Bearer "+testToken)
+	mux.ServeHTTP(recorder, request)
+
+	var components []logging.ComponentInfo
+	if err := json.NewDecoder(recorder.Body).Decode(&components); err != nil {
+		t.Fatalf("failed to decode components: %v", err)
+	}
+	if len(components) < 12 || components[0].Name != logging.ComponentAuth || !components[0].Core {
+		t.Fatalf("unexpected components: %+v", components)
+	}
+}
+
+func TestLogLevelPutRejectsInvalidInput(t *testing.T) {
+	mux, _ := newTestMux(t)
+
+	bodies := map[string]string{
+		LogLevelPath:              `{"level":"loud"}`,
+		LogLevelPath + "/unknown": `{"level":"debug"}`,
+		LogLevelPath + "/rest":    `{"level":"debug","ttl":"soon"}`,
+	}
+	for path, body := range bodies {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+		request.Header.Set("Authorization", "Bearer "+testToken)
+		mux.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400 for %s %s, got %d", path, body, recorder.Code)
+		}
+	}
+}
+
+func TestAdminAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer auditLog.Close()
+	mux := http.NewServeMux()
+	Register(mux, Options{Audit: auditLog, Failures: NewAuthFailures(auditLog), Levels: logging.NewLevels(slog.LevelInfo, nil), Token: testToken})
+
+	for _, header := range []string{"", "Bearer wrong", "Bearer " + testToken} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPut, LogLevelPath+"/sync", strings.NewReader(`{"level":"debug","ttl":"30m"}`))
+		if header != "" {
+			request.Header.Set("Authorization", header)
+		}
+		mux.ServeHTTP(recorder, request)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer file.Close()
+	entries, err := audit.Query(file, audit.Filter{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected failed auth and level change entries, got: %+v", entries)
+	}
+	if entries[0].Action != audit.ActionAdminAuthFailed || entries[0].Outcome != audit.OutcomeFailure || entries[0].Source != "192.0.2.1" {
+		t.Fatalf("unexpected auth failure entry: %+v", entries[0])
+	}
+	if entries[1].Action != audit.ActionAdminLogLevel || string(entries[1].Details) != `{"component":"sync","level":"debug","ttl":"30m0s"}` {
+		t.Fatalf("unexpected level change entry: %+v", entries[1])
+	}
+}
+
+func TestAuthFailuresPerHostWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer auditLog.Close()
+	failures := NewAuthFailures(auditLog)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	failures.now = func() time.Time { return now }
+	handler := RequireToken(testToken, failures, http.NotFoundHandler())
+
+	fail := func(remote string) {
+		request := httptest.NewRequest(http.MethodGet, LogLevelPath, nil)
+		request.RemoteAddr = remote
+		request.Header.Set("Authorization", "Bearer wrong")
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+	for range 5 {
+		fail("192.0.2.1:1234")
+	}
+	fail("192.0.2.2:1234")
+	now = now.Add(authFailureWindow)
+	fail("192.0.2.2:1234")
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer file.Close()
+	entries, err := audit.Query(file, audit.Filter{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Source+" "+string(entry.Details))
+	}
+	want := []string{
+		`192.0.2.1 {"method":"GET","path":"/admin/log-level"}`,
+		`192.0.2.2 {"method":"GET","path":"/admin/log-level"}`,
+		`192.0.2.1 {"count":4,"window":"1m0s"}`,
+		`192.0.2.2 {"method":"GET","path":"/admin/log-level"}`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected auth failure entries:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
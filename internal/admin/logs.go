@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Recent-log route.
+// This file defines GET /debug/logs, which returns entries from the in-memory
+// ring buffer as newline-delimited JSON. Query parameters filter by minimum
+// level, component, start time (RFC 3339 or a duration such as 15m), and a
+// tail limit. With follow=true the connection stays open and new entries are
+// streamed as they are logged until the client disconnects.
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/logging"
+)
+
+// Recent-log route. {{{
+
+const (
+	LogsPath         = "/debug/logs"
+	NDJSONType       = "application/x-ndjson"
+	logsPollInterval = 15 * time.Second
+)
+
+func getLogs(buffer *logging.RingBuffer) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		filter, follow, err := parseLogsQuery(request, time.Now())
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var updates <-chan logging.Entry
+		if follow {
+			var cancel func()
+			updates, cancel = buffer.Subscribe()
+			defer cancel()
+		}
+
+		writer.Header().Set("Content-Type", NDJSONType)
+		writer.Header().Set("Cache-Control", "no-store")
+		writer.WriteHeader(http.StatusOK)
+
+		encoder := json.NewEncoder(writer)
+		var last uint64
+		for _, entry := range buffer.Entries(filter) {
+			if err := encoder.Encode(entry); err != nil {
+				return
+			}
+			last = entry.Seq
+		}
+		if !follow {
+			return
+		}
+
+		controller := http.NewResponseController(writer)
+		_ = controller.SetWriteDeadline(time.Time{})
+		_ = controller.Flush()
+
+		filter.Limit = 0
+		ticker := time.NewTicker(logsPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-request.Context().Done():
+				return
+			case <-ticker.C:
+				// An empty line keeps proxies from closing an idle stream.
+				if _, err := writer.Write([]byte("\n")); err != nil {
+					return
+				}
+			case entry, ok := <-updates:
+				if !ok {
+					return
+				}
+				if entry.Seq <= last || !filter.Match(entry) {
+					continue
+				}
+				if err := encoder.Encode(entry); err != nil {
+					return
+				}
+				last = entry.Seq
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseLogsQuery reads level, component, since, limit, and follow.
+func parseLogsQuery(request *http.Request, now time.Time) (logging.LogFilter, bool, error) {
+	query := request.URL.Query()
+	filter := logging.LogFilter{Component: query.Get("component")}
+
+	if value := query.Get("level"); value != "" {
+		level, err := logging.ParseLevel(value)
+		if err != nil {
+			return logging.LogFilter{}, false, err
+		}
+		filter.Level = level
+	}
+	if value := query.Get("since"); value != "" {
+		since, err := parseSince(value, now)
+		if err != nil {
+			return logging.LogFilter{}, false, err
+		}
+		filter.Since = since
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return logging.LogFilter{}, false, fmt.Errorf("%s: limit %q", errtext.ErrInvalidAdminRequest, value)
+		}
+		filter.Limit = limit
+	}
+
+	follow := false
+	if value := query.Get("follow"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return logging.LogFilter{}, false, fmt.Errorf("%s: follow %q", errtext.ErrInvalidAdminRequest, value)
+		}
+		follow = parsed
+	}
+	return filter, follow, nil
+}
+
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if since, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return since, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return time.Time{}, fmt.Errorf("%s: since %q", errtext.ErrInvalidAdminRequest, value)
+	}
+	return now.Add(-age), nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
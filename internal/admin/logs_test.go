@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Recent-log route tests.
+// This file verifies /debug/logs query parsing, NDJSON snapshots filtered by
+// level and component, and that follow mode streams new entries.
+
+package admin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/logging"
+)
+
+// Recent-log route tests. {{{
+
+func TestParseLogsQuery(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	request := httptest.NewRequest(http.MethodGet, LogsPath+"?level=warn&component=sync&since=15m&limit=5&follow=true", nil)
+
+	filter, follow, err := parseLogsQuery(request, now)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !follow || filter.Component != "sync" || filter.Limit != 5 {
+		t.Fatalf("unexpected filter: %+v follow=%v", filter, follow)
+	}
+	if filter.Level.Level() != slog.LevelWarn {
+		t.Fatalf("expected warn level, got %s", filter.Level.Level())
+	}
+	if !filter.Since.Equal(now.Add(-15 * time.Minute)) {
+		t.Fatalf("unexpected since: %s", filter.Since)
+	}
+
+	for _, query := range []string{"level=loud", "since=yesterday", "limit=-1", "follow=maybe"} {
+		request := httptest.NewRequest(http.MethodGet, LogsPath+"?"+query, nil)
+		if _, _, err := parseLogsQuery(request, now); err == nil {
+			t.Fatalf("expected error for %s", query)
+		}
+	}
+}
+
+func TestLogsSnapshot(t *testing.T) {
+	buffer := logging.NewRingBuffer(10)
+	mux := http.NewServeMux()
+	Register(mux, Options{Logs: buffer, Token: testToken})
+	logger := newBufferLogger(buffer)
+
+	logger.Info("info record", logging.FieldComponent, "sync")
+	logger.Error("error record", logging.FieldComponent, "database")
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, LogsPath+"?level=error", nil)
+	request.Header.Set("Authorization", "Bearer "+testToken)
+	mux.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if recorder.Header().Get("Content-Type") != NDJSONType {
+		t.Fatalf("unexpected content type: %s", recorder.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 entry, got %d: %s", len(lines), recorder.Body.String())
+	}
+	var entry logging.Entry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("failed to decode entry: %v", err)
+	}
+	if entry.Message != "error record" || entry.Component != "database" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestLogsFollow(t *testing.T) {
+	buffer := logging.NewRingBuffer(10)
+	mux := http.NewServeMux()
+	Register(mux, Options{Logs: buffer, Token: testToken})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	logger := newBufferLogger(buffer)
+	logger.Info("backlog")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+LogsPath+"?follow=true", nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+testToken)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer response.Body.Close()
+
+	scanner := bufio.NewScanner(response.Body)
+	if !scanner.Scan() || !strings.Contains(scanner.Text(), "backlog") {
+		t.Fatalf("expected backlog entry, got: %s", scanner.Text())
+	}
+	logger.Info("live")
+	if !scanner.Scan() || !strings.Contains(scanner.Text(), "live") {
+		t.Fatalf("expected live entry, got: %s", scanner.Text())
+	}
+}
+
+func newBufferLogger(buffer *logging.RingBuffer) *slog.Logger {
+	return slog.New(buffer.Handler())
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
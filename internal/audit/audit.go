@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Audit log.
+// This file defines Log, the append-only sink for security-relevant events
+// (logins, device pairing, recovery code use, config and admin changes).
+// Entries are JSON Lines, each carrying a sequence number and the SHA-256
+// hash of the previous entry, so edits, deletions, and reordering break the
+// chain and show up in Verify. The audit log is separate from slog: records
+// are never filtered by logging.level, throttled, or sent to log outputs.
+
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Audit entries. {{{
+
+// Action names an audited event; the prefix before the dot is its area.
+type Action string
+
+const (
+	ActionAdminAuthFailed  Action = "admin.auth_failed"
+	ActionAdminLogLevel    Action = "admin.log_level_changed"
+	ActionAuthDevicePaired Action = "auth.device_paired"
+	ActionAuthKeyEnrolled  Action = "auth.key_enrolled"
+	ActionAuthLocalTrust   Action = "auth.local_trust"
+	ActionAuthLogin        Action = "auth.login"
+	ActionAuthLoginFailed  Action = "auth.login_failed"
+	ActionAuthRecoveryCode Action = "auth.recovery_code_used"
+	ActionConfigChanged    Action = "config.changed"
+	ActionServerStarted    Action = "server.started"
+	ActionServerStopped    Action = "server.stopped"
+)
+
+// Outcome reports whether the audited action succeeded.
+type Outcome string
+
+const (
+	OutcomeFailure Outcome = "failure"
+	OutcomeSuccess Outcome = "success"
+)
+
+// Event is what callers record; Log.Record fills in the chain fields.
+type Event struct {
+	Action  Action
+	Actor   string         // Who acted (user, device, or `admin`).
+	Source  string         // Where the action came from (remote address, host).
+	Outcome Outcome        // Defaults to success.
+	Details map[string]any // Extra context; must not contain secrets.
+}
+
+// Entry is a single line of the audit log.
+type Entry struct {
+	Seq     uint64          `json:"seq"`
+	Time    time.Time       `json:"time"`
+	Action  Action          `json:"action"`
+	Actor   string          `json:"actor,omitempty"`
+	Source  string          `json:"source,omitempty"`
+	Outcome Outcome         `json:"outcome"`
+	Details json.RawMessage `json:"details,omitempty"`
+	Prev    string          `json:"prev"`
+	Hash    string          `json:"hash,omitempty"`
+}
+
+// ComputeHash returns the chain hash for the entry: SHA-256 over its JSON
+// encoding without the hash field.
+func (entry Entry) ComputeHash() (string, error) {
+	entry.Hash = ""
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// }}}
+// Audit log. {{{
+
+const (
+	auditDirMode  = 0o700
+	auditFileMode = 0o600
+	maxEntrySize  = 1024 * 1024
+)
+
+// Log appends hash-chained entries to an audit file. A nil *Log records
+// nothing, so callers can hold one unconditionally.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+	path string
+	seq  uint64
+	last string
+	now  func() time.Time
+}
+
+// Open opens or creates the audit file at path and resumes its chain. It
+// refuses to append after a last line that does not parse.
+func Open(path string) (*Log, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%s", errtext.ErrAuditPathRequired)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), auditDirMode); err != nil {
+		return nil, fmt.Errorf("%s: %w", errtext.ErrOpenAuditLog, err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, auditFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errtext.ErrOpenAuditLog, err)
+	}
+
+	last, err := lastEntry(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &Log{file: file, path: path, seq: last.Seq, last: last.Hash, now: time.Now}, nil
+}
+
+// Path returns the audit file path.
+func (auditLog *Log) Path() string {
+	if auditLog == nil {
+		return ""
+	}
+	return auditLog.path
+}
+
+// Record appends an event and syncs it to disk before returning.
+func (auditLog *Log) Record(event Event) (Entry, error) {
+	if auditLog == nil {
+		return Entry{}, nil
+	}
+
+	var details json.RawMessage
+	if len(event.Details) > 0 {
+		encoded, err := json.Marshal(event.Details)
+		if err != nil {
+			return Entry{}, fmt.Errorf("%s: %w", errtext.ErrWriteAuditLog, err)
+		}
+		details = encoded
+	}
+	outcome := event.Outcome
+	if outcome == "" {
+		outcome = OutcomeSuccess
+	}
+
+	auditLog.mu.Lock()
+	defer auditLog.mu.Unlock()
+	if auditLog.file == nil {
+		return Entry{}, fmt.Errorf("%s", errtext.ErrAuditLogClosed)
+	}
+
+	entry := Entry{
+		Seq:     auditLog.seq + 1,
+		Time:    auditLog.now().UTC(),
+		Action:  event.Action,
+		Actor:   event.Actor,
+		Source:  event.Source,
+		Outcome: outcome,
+		Details: details,
+		Prev:    auditLog.last,
+	}
+	hash, err := entry.ComputeHash()
+	if err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", errtext.ErrWriteAuditLog, err)
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", errtext.ErrWriteAuditLog, err)
+	}
+	if _, err := auditLog.file.Write(append(line, '\n')); err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", errtext.ErrWriteAuditLog, err)
+	}
+	if err := auditLog.file.Sync(); err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", errtext.ErrWriteAuditLog, err)
+	}
+
+	auditLog.seq = entry.Seq
+	auditLog.last = entry.Hash
+	return entry, nil
+}
+
+// Close closes the audit file.
+func (auditLog *Log) Close() error {
+	if auditLog == nil {
+		return nil
+	}
+	auditLog.mu.Lock()
+	defer auditLog.mu.Unlock()
+	if auditLog.file == nil {
+		return nil
+	}
+	err := auditLog.file.Close()
+	auditLog.file = nil
+	return err
+}
+
+func lastEntry(reader io.Reader) (Entry, error) {
+	scanner := newScanner(reader)
+	var last []byte
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			last = append(last[:0], scanner.Bytes()...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", errtext.ErrOpenAuditLog, err)
+	}
+	if last == nil {
+		return Entry{}, nil
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(last, &entry); err != nil || entry.Hash == "" {
+		return Entry{}, fmt.Errorf("%s: last entry does not parse", errtext.ErrAuditLogCorrupt)
+	}
+	return entry, nil
+}
+
+func newScanner(reader io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEntrySize)
+	return scanner
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
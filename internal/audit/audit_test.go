@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Audit log tests.
+// This file verifies that entries are chained and synced to an append-only
+// file, that reopening resumes the chain, and that a nil log records nothing.
+
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Audit log tests. {{{
+
+func TestLogRecordChainsEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	auditLog, err := Open(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	first, err := auditLog.Record(Event{Action: ActionAuthLogin, Actor: "ha5bms", Source: "192.0.2.10"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	second, err := auditLog.Record(Event{Action: ActionAuthLoginFailed, Actor: "guest", Outcome: OutcomeFailure, Details: map[string]any{"reason": "bad password"}})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if first.Seq != 1 || first.Prev != "" || first.Outcome != OutcomeSuccess {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+	if second.Seq != 2 || second.Prev != first.Hash {
+		t.Fatalf("expected second entry to chain to the first, got: %+v", second)
+	}
+	if err := auditLog.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := auditLog.Record(Event{Action: ActionAuthLogin}); err == nil {
+		t.Fatalf("expected error after close")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expected audit file, got: %v", err)
+	}
+	if info.Mode().Perm() != auditFileMode {
+		t.Fatalf("expected mode %o, got %o", auditFileMode, info.Mode().Perm())
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer reopened.Close()
+	third, err := reopened.Record(Event{Action: ActionAuthDevicePaired})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if third.Seq != 3 || third.Prev != second.Hash {
+		t.Fatalf("expected reopened log to resume the chain, got: %+v", third)
+	}
+}
+
+func TestOpenRejectsCorruptTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte("not json\n"), 0o600); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "audit log is corrupt") {
+		t.Fatalf("expected corrupt log error, got: %v", err)
+	}
+}
+
+func TestNilLogRecordsNothing(t *testing.T) {
+	var auditLog *Log
+	if _, err := auditLog.Record(Event{Action: ActionAuthLogin}); err != nil {
+		t.Fatalf("expected nil log to ignore records, got: %v", err)
+	}
+	if err := auditLog.Close(); err != nil {
+		t.Fatalf("expected nil log close to succeed, got: %v", err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
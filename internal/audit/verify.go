@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Audit log verification and queries.
+// This file defines Verify, which walks an audit file and checks sequence
+// numbers, previous-hash links, and entry hashes, and Query, which returns
+// entries matching a filter. Both read the file directly, so they work on a
+// copy taken off the server as well as on the live file.
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Verification. {{{
+
+// VerifyResult summarizes an intact chain.
+type VerifyResult struct {
+	Entries int    `json:"entries"`
+	Head    string `json:"head,omitempty"` // Hash of the last entry.
+}
+
+// Verify checks the chain read from reader and reports the first break with
+// its line number.
+func Verify(reader io.Reader) (VerifyResult, error) {
+	var result VerifyResult
+	var previous Entry
+	line := 0
+
+	scanner := newScanner(reader)
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return result, chainError(line, "entry does not parse")
+		}
+		if entry.Seq != previous.Seq+1 {
+			return result, chainError(line, fmt.Sprintf("expected seq %d, got %d", previous.Seq+1, entry.Seq))
+		}
+		if entry.Prev != previous.Hash {
+			return result, chainError(line, "previous hash does not match")
+		}
+		hash, err := entry.ComputeHash()
+		if err != nil || hash != entry.Hash {
+			return result, chainError(line, "entry hash does not match")
+		}
+
+		previous = entry
+		result.Entries++
+		result.Head = entry.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("%s: %w", errtext.ErrOpenAuditLog, err)
+	}
+	return result, nil
+}
+
+func chainError(line int, reason string) error {
+	return fmt.Errorf("%s: line %d: %s", errtext.ErrAuditChainBroken, line, reason)
+}
+
+// }}}
+// Queries. {{{
+
+// Filter selects audit entries.
+type Filter struct {
+	Action  string    // Exact action or area prefix (`auth` matches `auth.login`).
+	Actor   string    // Exact actor.
+	Outcome Outcome   // Exact outcome.
+	Since   time.Time // Entries at or after this time.
+	Until   time.Time // Entries before this time.
+	Limit   int       // Keep only the newest N matches (0 keeps all).
+}
+
+// Match reports whether entry passes the filter.
+func (filter Filter) Match(entry Entry) bool {
+	if filter.Action != "" {
+		action := string(entry.Action)
+		if action != filter.Action && !strings.HasPrefix(action, filter.Action+".") {
+			return false
+		}
+	}
+	if filter.Actor != "" && entry.Actor != filter.Actor {
+		return false
+	}
+	if filter.Outcome != "" && entry.Outcome != filter.Outcome {
+		return false
+	}
+	if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && !entry.Time.Before(filter.Until) {
+		return false
+	}
+	return true
+}
+
+// Query returns entries matching filter, oldest first. Lines that do not
+// parse are reported as errors; use Verify to check the chain itself.
+func Query(reader io.Reader, filter Filter) ([]Entry, error) {
+	var matches []Entry
+	line := 0
+
+	scanner := newScanner(reader)
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s: line %d: entry does not parse", errtext.ErrAuditLogCorrupt, line)
+		}
+		if !filter.Match(entry) {
+			continue
+		}
+		matches = append(matches, entry)
+		if filter.Limit > 0 && len(matches) > filter.Limit {
+			matches = matches[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", errtext.ErrOpenAuditLog, err)
+	}
+	return matches, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Audit verification tests.
+// This file verifies that Verify accepts an intact chain and reports edited,
+// deleted, and reordered entries, and that Query filters by action area,
+// outcome, time, and limit.
+
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Audit verification tests. {{{
+
+func writeTestAudit(t *testing.T, events ...Event) []string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := Open(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	auditLog.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	for _, event := range events {
+		if _, err := auditLog.Record(event); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	_ = auditLog.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+func testEvents() []Event {
+	return []Event{
+		{Action: ActionServerStarted, Actor: "bmsd"},
+		{Action: ActionAuthLogin, Actor: "ha5bms"},
+		{Action: ActionAuthLoginFailed, Actor: "guest", Outcome: OutcomeFailure},
+		{Action: ActionAdminLogLevel, Actor: "admin", Details: map[string]any{"level": "debug"}},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	lines := writeTestAudit(t, testEvents()...)
+
+	result, err := Verify(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("expected intact chain, got: %v", err)
+	}
+	if result.Entries != 4 || result.Head == "" {
+		t.Fatalf("unexpected verify result: %+v", result)
+	}
+
+	cases := map[string]struct {
+		lines []string
+		want  string
+	}{
+		"edited":    {lines: []string{lines[0], strings.Replace(lines[1], "ha5bms", "intruder", 1), lines[2], lines[3]}, want: "line 2: entry hash does not match"},
+		"deleted":   {lines: []string{lines[0], lines[2], lines[3]}, want: "line 2: expected seq 2, got 3"},
+		"reordered": {lines: []string{lines[0], lines[2], lines[1], lines[3]}, want: "line 2: expected seq 2, got 3"},
+		"truncated": {lines: []string{lines[1], lines[2], lines[3]}, want: "line 1: expected seq 1, got 2"},
+	}
+	for name, tc := range cases {
+		_, err := Verify(strings.NewReader(strings.Join(tc.lines, "\n")))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected %q, got: %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	content := strings.Join(writeTestAudit(t, testEvents()...), "\n")
+
+	auth, err := Query(strings.NewReader(content), Filter{Action: "auth"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(auth) != 2 || auth[0].Action != ActionAuthLogin {
+		t.Fatalf("expected auth area entries, got: %+v", auth)
+	}
+
+	failures, _ := Query(strings.NewReader(content), Filter{Outcome: OutcomeFailure})
+	if len(failures) != 1 || failures[0].Actor != "guest" {
+		t.Fatalf("expected one failure, got: %+v", failures)
+	}
+
+	since := time.Date(2026, 3, 1, 12, 3, 0, 0, time.UTC)
+	recent, _ := Query(strings.NewReader(content), Filter{Since: since, Limit: 1})
+	if len(recent) != 1 || recent[0].Seq != 4 {
+		t.Fatalf("expected newest entry since %s, got: %+v", since, recent)
+	}
+	if none, _ := Query(strings.NewReader(content), Filter{Action: "auth.log"}); len(none) != 0 {
+		t.Fatalf("expected prefix match on whole segments only, got: %+v", none)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,307 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Database backups.
+// This file defines Backup, one snapshot directory named bms-<UTC time>
+// holding the database copy and manifest.json, and Create, which writes it.
+// The snapshot is taken in a single read transaction, so the schema version
+// recorded in the manifest is the one the data was read at. A backup is
+// built in a .partial directory, synced, and renamed into place, so a crash
+// never leaves a directory that looks complete. The manifest carries the
+// SHA-256 of the copy, which Verify checks before anything is restored.
+
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/buildinfo"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Manifest. {{{
+
+const (
+	// FormatVersion is bumped on incompatible changes to the backup layout.
+	FormatVersion = 1
+
+	manifestFile = "manifest.json"
+	namePrefix   = "bms-"
+	nameLayout   = "20060102T150405Z"
+	partialExt   = ".partial"
+
+	dirMode  fs.FileMode = 0o700
+	fileMode fs.FileMode = 0o600
+)
+
+// Database copy file names, per driver.
+var dataFiles = map[config.DatabaseDriver]string{
+	config.DriverPostgres: "database.sql",
+	config.DriverSQLite:   "database.sqlite",
+}
+
+// Manifest describes one backup.
+type Manifest struct {
+	Format        int                   `json:"format"`              // Backup layout version.
+	CreatedAt     time.Time             `json:"created_at"`          // Snapshot time (UTC).
+	ServerID      string                `json:"server_id,omitempty"` // server.id that owns the source.
+	Driver        config.DatabaseDriver `json:"driver"`              // Source database driver.
+	SchemaVersion uint64                `json:"schema_version"`      // Applied migration version.
+	Dirty         bool                  `json:"dirty,omitempty"`     // The source migration state was dirty.
+	BMSVersion    string                `json:"bms_version"`         // Version of the bmsd that wrote it.
+	File          string                `json:"file"`                // Database copy, relative to the backup.
+	Size          int64                 `json:"size"`                // Size of File in bytes.
+	SHA256        string                `json:"sha256"`              // Hex SHA-256 of File.
+}
+
+// Backup is a backup directory and its manifest.
+type Backup struct {
+	Path     string   `json:"path"`
+	Manifest Manifest `json:"manifest"`
+}
+
+// DataPath returns the path of the database copy.
+func (backup Backup) DataPath() string {
+	return filepath.Join(backup.Path, backup.Manifest.File)
+}
+
+// Open reads the manifest of the backup at path.
+func Open(path string) (Backup, error) {
+	body, err := os.ReadFile(filepath.Join(path, manifestFile))
+	if err != nil {
+		return Backup{}, fmt.Errorf("%s: %w", errtext.ErrBackupInvalid, err)
+	}
+	backup := Backup{Path: path}
+	if err := json.Unmarshal(body, &backup.Manifest); err != nil {
+		return Backup{}, fmt.Errorf("%s: %s: %w", errtext.ErrBackupInvalid, manifestFile, err)
+	}
+	manifest := backup.Manifest
+	switch {
+	case manifest.Format != FormatVersion:
+		return Backup{}, fmt.Errorf("%s: format %d, want %d", errtext.ErrBackupInvalid, manifest.Format, FormatVersion)
+	case dataFiles[manifest.Driver] == "":
+		return Backup{}, fmt.Errorf("%s: %s: %q", errtext.ErrBackupInvalid, errtext.ErrDatabaseDriver, manifest.Driver)
+	case manifest.File != filepath.Base(manifest.File) || manifest.File == manifestFile:
+		return Backup{}, fmt.Errorf("%s: file %q", errtext.ErrBackupInvalid, manifest.File)
+	}
+	return backup, nil
+}
+
+// Verify checks the size and checksum of the database copy.
+func (backup Backup) Verify() error {
+	size, sum, err := checksum(backup.DataPath())
+	if err != nil {
+		return fmt.Errorf("%s: %w", errtext.ErrBackupInvalid, err)
+	}
+	if size != backup.Manifest.Size || sum != backup.Manifest.SHA256 {
+		return fmt.Errorf("%s: %s", errtext.ErrBackupChecksum, backup.DataPath())
+	}
+	return nil
+}
+
+// checksum returns the size and hex SHA-256 of the file at path.
+func checksum(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// }}}
+// Create. {{{
+
+// Create writes a backup of the connected database into a new directory
+// under dir, which is created if needed. The manifest names the server
+// recorded in server_meta, or serverID when the database records none.
+func Create(ctx context.Context, database *db.DB, dir string, serverID string) (Backup, error) {
+	backup, err := create(ctx, database, dir, serverID, time.Now().UTC())
+	if err != nil {
+		return Backup{}, fmt.Errorf("%s: %w", errtext.ErrBackupCreate, err)
+	}
+	return backup, nil
+}
+
+func create(ctx context.Context, database *db.DB, dir string, serverID string, now time.Time) (Backup, error) {
+	driver := database.Dialect().Driver()
+	if dataFiles[driver] == "" {
+		return Backup{}, fmt.Errorf("%s: %q", errtext.ErrDatabaseDriver, driver)
+	}
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return Backup{}, err
+	}
+	final := filepath.Join(dir, namePrefix+now.Format(nameLayout))
+	if _, err := os.Lstat(final); !errors.Is(err, fs.ErrNotExist) {
+		return Backup{}, fmt.Errorf("%s: %s", errtext.ErrPathExists, final)
+	}
+	partial := final + partialExt
+	if err := os.Mkdir(partial, dirMode); err != nil {
+		return Backup{}, err
+	}
+
+	if recorded, err := database.Meta(ctx, db.MetaServerID); err == nil && recorded != "" {
+		serverID = recorded
+	}
+	backup, err := write(ctx, database, partial, Manifest{
+		Format:     FormatVersion,
+		CreatedAt:  now,
+		ServerID:   serverID,
+		Driver:     driver,
+		BMSVersion: buildinfo.Get().Version,
+		File:       dataFiles[driver],
+	})
+	if err == nil {
+		err = os.Rename(partial, final)
+	}
+	if err == nil {
+		err = syncDir(dir)
+	}
+	if err != nil {
+		return Backup{}, errors.Join(err, os.RemoveAll(partial))
+	}
+	backup.Path = final
+	return backup, nil
+}
+
+// write dumps the database into dir and writes the manifest.
+func write(ctx context.Context, database *db.DB, dir string, manifest Manifest) (Backup, error) {
+	backup := Backup{Path: dir, Manifest: manifest}
+	path := backup.DataPath()
+	var err error
+	switch manifest.Driver {
+	case config.DriverSQLite:
+		backup.Manifest.SchemaVersion, backup.Manifest.Dirty, err = dumpSQLite(ctx, database, path)
+	case config.DriverPostgres:
+		backup.Manifest.SchemaVersion, backup.Manifest.Dirty, err = dumpPostgres(ctx, database, path)
+	}
+	if err != nil {
+		return Backup{}, err
+	}
+	if err := syncFile(path); err != nil {
+		return Backup{}, err
+	}
+	if backup.Manifest.Size, backup.Manifest.SHA256, err = checksum(path); err != nil {
+		return Backup{}, err
+	}
+
+	body, err := json.MarshalIndent(backup.Manifest, "", "  ")
+	if err != nil {
+		return Backup{}, err
+	}
+	manifestPath := filepath.Join(dir, manifestFile)
+	if err := os.WriteFile(manifestPath, append(body, '\n'), fileMode); err != nil {
+		return Backup{}, err
+	}
+	if err := syncFile(manifestPath); err != nil {
+		return Backup{}, err
+	}
+	return backup, syncDir(dir)
+}
+
+// versionQuery reads the migration version recorded in schema_migrations.
+const versionQuery = "SELECT version, dirty FROM schema_migrations LIMIT 1"
+
+// scanVersion reads the migration version; a missing table or row means
+// version 0.
+func scanVersion(row interface{ Scan(...any) error }, exists bool) (uint64, bool, error) {
+	if !exists {
+		return 0, false, nil
+	}
+	var version int64
+	var dirty bool
+	if err := row.Scan(&version, &dirty); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return uint64(version), dirty, nil
+}
+
+func syncFile(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	return errors.Join(file.Sync(), file.Close())
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return errors.Join(dir.Sync(), dir.Close())
+}
+
+// }}}
+// List and prune. {{{
+
+// List returns the complete backups in dir, oldest first. Entries that are
+// not backup directories, unfinished ones, and unreadable manifests are
+// skipped; a missing dir holds no backups.
+func List(dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || !strings.HasPrefix(name, namePrefix) || strings.HasSuffix(name, partialExt) {
+			continue
+		}
+		if backup, err := Open(filepath.Join(dir, name)); err == nil {
+			backups = append(backups, backup)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Manifest.CreatedAt.Before(backups[j].Manifest.CreatedAt)
+	})
+	return backups, nil
+}
+
+// Prune removes all but the newest keep backups in dir and returns the
+// removed ones. Zero keeps every backup.
+func Prune(dir string, keep int) ([]Backup, error) {
+	backups, err := List(dir)
+	if err != nil || keep <= 0 || len(backups) <= keep {
+		return nil, err
+	}
+	var removed []Backup
+	for _, backup := range backups[:len(backups)-keep] {
+		if err := os.RemoveAll(backup.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, backup)
+	}
+	return removed, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,348 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Backup tests.
+// This file verifies SQLite backups end to end: the manifest and checksum,
+// refusing a tampered copy or a database still in use, restoring over an
+// existing file with the migrations added since the backup while keeping its
+// mode and putting it back on failure, the server identity recorded in
+// server_meta, the restore checks, and listing and pruning backup
+// directories.
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+)
+
+// Backup tests. {{{
+
+var firstMigration = map[string]string{
+	"1_meta.up.sql":   "CREATE TABLE meta (key TEXT NOT NULL PRIMARY KEY, value TEXT NOT NULL);",
+	"1_meta.down.sql": "DROP TABLE meta;",
+}
+
+var bothMigrations = map[string]string{
+	"1_meta.up.sql":   firstMigration["1_meta.up.sql"],
+	"1_meta.down.sql": firstMigration["1_meta.down.sql"],
+	"2_note.up.sql":   "ALTER TABLE meta ADD COLUMN note TEXT NOT NULL DEFAULT '';",
+	"2_note.down.sql": "-- IRREVERSIBLE: drops the note column.\nALTER TABLE meta DROP COLUMN note;",
+}
+
+// tree places the same files in shared/, sqlite/, and postgres/.
+func tree(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, body := range files {
+		for _, dir := range []string{"shared", "sqlite", "postgres"} {
+			fsys[dir+"/"+name] = &fstest.MapFile{Data: []byte(body)}
+		}
+	}
+	return fsys
+}
+
+func sqliteSettings(path string) config.DatabaseConfig {
+	settings := config.DefaultConfig().Database
+	settings.Driver, settings.DSN = config.DriverSQLite, "file:"+path
+	return settings
+}
+
+// openMigrated opens the database in settings migrated with files.
+func openMigrated(t *testing.T, settings config.DatabaseConfig, files map[string]string) *db.DB {
+	t.Helper()
+	database, err := db.Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = database.Close()
+	})
+	migrator, err := migrate.New(database, tree(files), slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	return database
+}
+
+func TestCreateAndRestoreSQLite(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bms.db")
+	database := openMigrated(t, sqliteSettings(path), firstMigration)
+	if _, err := database.ExecContext(ctx, "INSERT INTO meta VALUES ('call', 'HA5BMS')"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	created, err := Create(ctx, database, filepath.Join(dir, "backups"), "server-a")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	manifest := created.Manifest
+	if manifest.Format != FormatVersion || manifest.Driver != config.DriverSQLite || manifest.SchemaVersion != 1 || manifest.ServerID != "server-a" || manifest.Size == 0 || len(manifest.SHA256) != 64 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	if !strings.HasPrefix(filepath.Base(created.Path), namePrefix) {
+		t.Fatalf("unexpected backup path %s", created.Path)
+	}
+	if info, err := os.Stat(created.DataPath()); err != nil || info.Mode().Perm() != fileMode {
+		t.Fatalf("expected a private copy, got %v, %v", info, err)
+	}
+
+	opened, err := Open(created.Path)
+	if err != nil || opened.Manifest != manifest {
+		t.Fatalf("expected the written manifest, got %+v, %v", opened.Manifest, err)
+	}
+	if err := opened.Verify(); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	if _, err := database.ExecContext(ctx, "INSERT INTO meta VALUES ('grid', 'JN97')"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if err := database.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+
+	restored, err := Restore(ctx, sqliteSettings(path), opened, RestoreOptions{Migrations: tree(bothMigrations), ServerID: "server-a"})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if len(restored.Applied) != 1 || restored.Applied[0].Version != 2 {
+		t.Fatalf("expected migration 2 after loading, got %+v", restored.Applied)
+	}
+	if restored.Previous == "" {
+		t.Fatal("expected the replaced file to be kept")
+	}
+	if _, err := os.Stat(restored.Previous); err != nil {
+		t.Fatalf("previous: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("expected the replaced file's mode, got %v, %v", info, err)
+	}
+
+	reopened := openMigrated(t, sqliteSettings(path), bothMigrations)
+	var count int
+	var note string
+	if err := reopened.QueryRowContext(ctx, "SELECT count(*), max(note) FROM meta").Scan(&count, &note); err != nil || count != 1 {
+		t.Fatalf("expected the backed up row only, got %d, %v", count, err)
+	}
+	previous := openMigrated(t, sqliteSettings(restored.Previous), firstMigration)
+	if err := previous.QueryRowContext(ctx, "SELECT count(*) FROM meta").Scan(&count); err != nil || count != 2 {
+		t.Fatalf("expected the replaced file intact, got %d, %v", count, err)
+	}
+}
+
+func TestRestoreRefusesOpenDatabase(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bms.db")
+	database := openMigrated(t, sqliteSettings(path), firstMigration)
+	created, err := Create(ctx, database, filepath.Join(dir, "backups"), "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	_, err = Restore(ctx, sqliteSettings(path), created, RestoreOptions{Migrations: tree(firstMigration)})
+	if err == nil || !strings.Contains(err.Error(), errtext.ErrRestoreInUse) {
+		t.Fatalf("expected a refusal while the database is open, got %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".restore-") || strings.Contains(entry.Name(), ".pre-restore-") {
+			t.Fatalf("unexpected leftover %s", entry.Name())
+		}
+	}
+	if err := database.Check(ctx); err != nil {
+		t.Fatalf("expected the open database untouched, got %v", err)
+	}
+}
+
+func TestMoveSQLiteRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	from, to := filepath.Join(dir, "bms.db"), filepath.Join(dir, "moved.db")
+	for _, name := range []string{from, from + "-wal"} {
+		if err := os.WriteFile(name, []byte(name), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	// A non-empty directory in the way makes the -wal rename fail.
+	if err := os.MkdirAll(filepath.Join(to+"-wal", "blocker"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if err := moveSQLite(from, to); err == nil {
+		t.Fatal("expected the move to fail")
+	}
+	for _, name := range []string{from, from + "-wal"} {
+		if data, err := os.ReadFile(name); err != nil || string(data) != name {
+			t.Fatalf("expected %s back in place, got %q, %v", name, data, err)
+		}
+	}
+	if _, err := os.Stat(to); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected nothing left at %s, got %v", to, err)
+	}
+}
+
+func TestServerIdentity(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bms.db")
+	files := map[string]string{
+		"1_server_meta.up.sql":   "CREATE TABLE server_meta (key TEXT NOT NULL PRIMARY KEY, value TEXT NOT NULL, updated_at TEXT NOT NULL);",
+		"1_server_meta.down.sql": "DROP TABLE server_meta;",
+	}
+	database := openMigrated(t, sqliteSettings(path), files)
+	if err := database.SetMeta(ctx, db.MetaServerID, "server-b"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	created, err := Create(ctx, database, filepath.Join(dir, "backups"), "server-a")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Manifest.ServerID != "server-b" {
+		t.Fatalf("expected the recorded server, got %q", created.Manifest.ServerID)
+	}
+	if err := database.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	options := RestoreOptions{Migrations: tree(files), ServerID: "server-a"}
+	if _, err := Restore(ctx, sqliteSettings(path), created, options); err == nil || !strings.Contains(err.Error(), errtext.ErrBackupMismatch) {
+		t.Fatalf("expected a server mismatch, got %v", err)
+	}
+	options.Force = true
+	if _, err := Restore(ctx, sqliteSettings(path), created, options); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	reopened := openMigrated(t, sqliteSettings(path), files)
+	if recorded, err := reopened.Meta(ctx, db.MetaServerID); err != nil || recorded != "server-a" {
+		t.Fatalf("expected the target server recorded, got %q, %v", recorded, err)
+	}
+}
+
+func TestRestoreRefusesTamperedBackup(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bms.db")
+	database := openMigrated(t, sqliteSettings(path), firstMigration)
+	created, err := Create(ctx, database, dir, "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	file, err := os.OpenFile(created.DataPath(), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("open copy: %v", err)
+	}
+	if _, err := file.WriteAt([]byte{0xff}, created.Manifest.Size/2); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	_ = file.Close()
+
+	if err := created.Verify(); err == nil || !strings.Contains(err.Error(), errtext.ErrBackupChecksum) {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	target := filepath.Join(dir, "target.db")
+	if _, err := Restore(ctx, sqliteSettings(target), created, RestoreOptions{Migrations: tree(firstMigration)}); err == nil {
+		t.Fatal("expected restore to refuse a tampered backup")
+	}
+	if _, err := os.Stat(target); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no target file, got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	migrations := []migrate.Migration{{Version: 1}, {Version: 3}}
+	valid := Manifest{Driver: config.DriverSQLite, ServerID: "a", SchemaVersion: 1, BMSVersion: "v1.0.0"}
+	cases := []struct {
+		name   string
+		edit   func(*Manifest)
+		server string
+		force  bool
+		want   string
+	}{
+		{"valid", func(*Manifest) {}, "a", false, ""},
+		{"empty database", func(manifest *Manifest) { manifest.SchemaVersion = 0 }, "a", false, ""},
+		{"driver", func(manifest *Manifest) { manifest.Driver = config.DriverPostgres }, "a", true, errtext.ErrBackupMismatch},
+		{"server", func(*Manifest) {}, "b", false, "--force"},
+		{"forced server", func(*Manifest) {}, "b", true, ""},
+		{"dirty", func(manifest *Manifest) { manifest.Dirty = true }, "a", false, errtext.ErrMigrationDirty},
+		{"newer", func(manifest *Manifest) { manifest.SchemaVersion = 4 }, "a", false, errtext.ErrBackupNewer},
+		{"unknown", func(manifest *Manifest) { manifest.SchemaVersion = 2 }, "a", false, errtext.ErrMigrationUnknown},
+	}
+	for _, test := range cases {
+		manifest := valid
+		test.edit(&manifest)
+		err := Check(manifest, config.DriverSQLite, test.server, test.force, migrations)
+		if test.want == "" && err != nil || test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)) {
+			t.Errorf("%s: expected %q, got %v", test.name, test.want, err)
+		}
+	}
+}
+
+func TestListAndPrune(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	database := openMigrated(t, sqliteSettings(filepath.Join(dir, "bms.db")), firstMigration)
+	backups := filepath.Join(dir, "backups")
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	for hour := range 3 {
+		if _, err := create(ctx, database, backups, "", start.Add(time.Duration(hour)*time.Hour)); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if _, err := create(ctx, database, backups, "", start); err == nil {
+		t.Fatal("expected an existing backup name to be refused")
+	}
+	if err := os.Mkdir(filepath.Join(backups, "bms-20261019T150000Z.partial"), dirMode); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(backups, "notes.txt"), nil, fileMode); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	list, err := List(backups)
+	if err != nil || len(list) != 3 || !list[0].Manifest.CreatedAt.Equal(start) {
+		t.Fatalf("expected three backups oldest first, got %+v, %v", list, err)
+	}
+	if removed, err := Prune(backups, 0); err != nil || len(removed) != 0 {
+		t.Fatalf("expected retention 0 to keep all, got %v, %v", removed, err)
+	}
+	removed, err := Prune(backups, 2)
+	if err != nil || len(removed) != 1 || removed[0].Path != list[0].Path {
+		t.Fatalf("expected the oldest backup removed, got %+v, %v", removed, err)
+	}
+	if list, _ := List(backups); len(list) != 2 {
+		t.Fatalf("expected two backups left, got %d", len(list))
+	}
+	if list, err := List(filepath.Join(dir, "missing")); err != nil || len(list) != 0 {
+		t.Fatalf("expected a missing directory to hold no backups, got %v, %v", list, err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
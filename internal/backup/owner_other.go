@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// File ownership elsewhere.
+// This file stubs ownership matching on platforms without Unix file owners;
+// a restored database file keeps the owner of the restoring process.
+
+//go:build !unix
+
+package backup
+
+import "io/fs"
+
+// File ownership. {{{
+
+func matchOwner(string, fs.FileInfo) error {
+	return nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// File ownership on Unix.
+// This file gives a restored database file the owner and group of the file
+// it replaces, so a restore run as root leaves a file the server account can
+// still open.
+
+//go:build unix
+
+package backup
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// File ownership. {{{
+
+// matchOwner sets the owner and group of path to those of reference.
+func matchOwner(path string, reference fs.FileInfo) error {
+	stat, ok := reference.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	return os.Chown(path, int(stat.Uid), int(stat.Gid))
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
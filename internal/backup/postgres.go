@@ -0,0 +1,367 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// PostgreSQL backups.
+// This file writes a logical dump of the bms tables, every table in the
+// current schema except schema_migrations, in one REPEATABLE READ READ ONLY
+// transaction. The dump uses the COPY text blocks and setval calls of
+// pg_dump --data-only, parents before children, so psql can load it too.
+// Restore needs a database without bms data: it migrates to the version of
+// the backup, loads the data in one transaction, and then migrates to the
+// latest version. Existing data is never dropped.
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+)
+
+// PostgreSQL. {{{
+
+const (
+	postgresTablesQuery = `SELECT c.relname FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema() AND c.relkind = 'r' AND c.relname <> 'schema_migrations' ORDER BY c.relname`
+	postgresForeignKeysQuery = `SELECT child.relname, parent.relname FROM pg_constraint k
+		JOIN pg_class child ON child.oid = k.conrelid JOIN pg_class parent ON parent.oid = k.confrelid
+		JOIN pg_namespace n ON n.oid = child.relnamespace
+		WHERE k.contype = 'f' AND n.nspname = current_schema() AND parent.relnamespace = child.relnamespace`
+	postgresColumnsQuery = `SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND is_generated = 'NEVER' ORDER BY ordinal_position`
+	postgresSequencesQuery = `SELECT sequencename, last_value FROM pg_sequences
+		WHERE schemaname = current_schema() AND last_value IS NOT NULL ORDER BY sequencename`
+	postgresVersionTableQuery = `SELECT count(*) FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name = 'schema_migrations'`
+)
+
+var (
+	copyStatement   = regexp.MustCompile(`^COPY (.+) FROM stdin;$`)
+	setvalStatement = regexp.MustCompile(`^SELECT pg_catalog\.setval\('(?:[^']|'')+', -?\d+, true\);$`)
+)
+
+// queryer is the query side of *sql.Conn and *db.DB.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func dumpPostgres(ctx context.Context, database *db.DB, path string) (uint64, bool, error) {
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN ISOLATION LEVEL REPEATABLE READ READ ONLY"); err != nil {
+		return 0, false, err
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+	}()
+
+	var exists int
+	if err := conn.QueryRowContext(ctx, postgresVersionTableQuery).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	version, dirty, err := scanVersion(conn.QueryRowContext(ctx, versionQuery), exists > 0)
+	if err != nil {
+		return 0, false, err
+	}
+	tables, err := postgresTables(ctx, conn)
+	if err != nil {
+		return 0, false, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return 0, false, err
+	}
+	writer := bufio.NewWriter(file)
+	err = writePostgres(ctx, conn, writer, tables, version)
+	if err == nil {
+		err = writer.Flush()
+	}
+	return version, dirty, errors.Join(err, file.Close())
+}
+
+// writePostgres writes the COPY blocks of tables and the sequence values.
+func writePostgres(ctx context.Context, conn *sql.Conn, writer io.Writer, tables []string, version uint64) error {
+	fmt.Fprintf(writer, "-- bms logical backup at migration %d\n\n", version)
+	for _, table := range tables {
+		columns, err := postgresColumns(ctx, conn, table)
+		if err != nil {
+			return err
+		}
+		target := pgx.Identifier{table}.Sanitize() + " (" + columns + ")"
+		fmt.Fprintf(writer, "COPY %s FROM stdin;\n", target)
+		err = conn.Raw(func(driverConn any) error {
+			_, err := pgConn(driverConn).CopyTo(ctx, writer, "COPY "+target+" TO STDOUT")
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", table, err)
+		}
+		fmt.Fprint(writer, "\\.\n\n")
+	}
+
+	rows, err := conn.QueryContext(ctx, postgresSequencesQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var value int64
+		if err := rows.Scan(&name, &value); err != nil {
+			return err
+		}
+		literal := strings.ReplaceAll(pgx.Identifier{name}.Sanitize(), "'", "''")
+		fmt.Fprintf(writer, "SELECT pg_catalog.setval('%s', %d, true);\n", literal, value)
+	}
+	return rows.Err()
+}
+
+// restorePostgres loads the backup into the database named by settings,
+// which must not hold bms data, and migrates it to the latest version.
+func restorePostgres(ctx context.Context, settings config.DatabaseConfig, backup Backup, options RestoreOptions) (Restored, error) {
+	restored := Restored{Backup: backup, Applied: []migrate.Migration{}}
+	database, err := db.Open(settings)
+	if err != nil {
+		return restored, err
+	}
+	defer database.Close()
+	if err := database.Connect(ctx); err != nil {
+		return restored, err
+	}
+	migrator, err := migrate.New(database, options.Migrations, options.Logger)
+	if err != nil {
+		return restored, err
+	}
+
+	status, err := migrator.Status(ctx)
+	switch {
+	case err != nil:
+		return restored, err
+	case status.Dirty:
+		return restored, fmt.Errorf("%s at version %d", errtext.ErrMigrationDirty, status.Version)
+	case status.Version > backup.Manifest.SchemaVersion:
+		return restored, fmt.Errorf("%s: at migration %d, newer than the backup", errtext.ErrRestoreNotEmpty, status.Version)
+	}
+	tables, err := postgresTables(ctx, database)
+	if err != nil {
+		return restored, err
+	}
+	for _, table := range tables {
+		var found bool
+		if err := database.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+pgx.Identifier{table}.Sanitize()+")").Scan(&found); err != nil {
+			return restored, err
+		}
+		if found {
+			return restored, fmt.Errorf("%s: table %s has rows", errtext.ErrRestoreNotEmpty, table)
+		}
+	}
+
+	if backup.Manifest.SchemaVersion > 0 {
+		if _, err := migrator.UpTo(ctx, backup.Manifest.SchemaVersion); err != nil {
+			return restored, err
+		}
+	}
+	if err := loadPostgres(ctx, database, backup.DataPath()); err != nil {
+		return restored, err
+	}
+	applied, err := migrator.Up(ctx)
+	restored.Applied = append(restored.Applied, applied...)
+	if err != nil {
+		return restored, err
+	}
+	return restored, recordServerID(ctx, database, options.ServerID)
+}
+
+// loadPostgres runs the dump at path in one transaction.
+func loadPostgres(ctx context.Context, database *db.DB, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN"); err != nil {
+		return err
+	}
+	if err := loadStatements(ctx, conn, bufio.NewReader(file)); err != nil {
+		_, rollback := conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+		return errors.Join(err, rollback)
+	}
+	_, err = conn.ExecContext(ctx, "COMMIT")
+	return err
+}
+
+// loadStatements runs the COPY blocks and setval calls read from reader.
+func loadStatements(ctx context.Context, conn *sql.Conn, reader *bufio.Reader) error {
+	for number := 1; ; number++ {
+		line, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) && line == "" {
+			return nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch match := copyStatement.FindStringSubmatch(line); {
+		case line == "" || strings.HasPrefix(line, "--"):
+		case match != nil:
+			data, lines, err := copyData(reader)
+			if err != nil {
+				return fmt.Errorf("%s: line %d: %w", errtext.ErrBackupInvalid, number, err)
+			}
+			err = conn.Raw(func(driverConn any) error {
+				_, err := pgConn(driverConn).CopyFrom(ctx, bytes.NewReader(data), "COPY "+match[1]+" FROM STDIN")
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("line %d: %w", number, err)
+			}
+			number += lines
+		case setvalStatement.MatchString(line):
+			if _, err := conn.ExecContext(ctx, line); err != nil {
+				return fmt.Errorf("line %d: %w", number, err)
+			}
+		default:
+			return fmt.Errorf("%s: line %d: unexpected statement", errtext.ErrBackupInvalid, number)
+		}
+	}
+}
+
+// copyData reads COPY text rows up to the \. terminator and returns them
+// with the number of lines read.
+func copyData(reader *bufio.Reader) ([]byte, int, error) {
+	var data bytes.Buffer
+	for lines := 1; ; lines++ {
+		line, err := reader.ReadString('\n')
+		if strings.TrimSuffix(line, "\n") == `\.` {
+			return data.Bytes(), lines, nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New(`COPY data without \. terminator`)
+			}
+			return nil, lines, err
+		}
+		data.WriteString(line)
+	}
+}
+
+// postgresTables lists the bms tables, parents before the tables that
+// reference them.
+func postgresTables(ctx context.Context, database queryer) ([]string, error) {
+	rows, err := database.QueryContext(ctx, postgresTablesQuery)
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = database.QueryContext(ctx, postgresForeignKeysQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	parents := make(map[string][]string)
+	for rows.Next() {
+		var child, parent string
+		if err := rows.Scan(&child, &parent); err != nil {
+			return nil, err
+		}
+		parents[child] = append(parents[child], parent)
+	}
+	return orderTables(tables, parents), rows.Err()
+}
+
+// orderTables sorts tables so that every table follows its parents. Self
+// references and cycles are left in the given order.
+func orderTables(tables []string, parents map[string][]string) []string {
+	known := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		known[table] = true
+	}
+	ordered := make([]string, 0, len(tables))
+	visited := make(map[string]bool, len(tables))
+	var visit func(table string)
+	visit = func(table string) {
+		if visited[table] {
+			return
+		}
+		visited[table] = true
+		for _, parent := range parents[table] {
+			if known[parent] {
+				visit(parent)
+			}
+		}
+		ordered = append(ordered, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+	return ordered
+}
+
+// postgresColumns returns the quoted, comma-separated stored columns of
+// table.
+func postgresColumns(ctx context.Context, conn *sql.Conn, table string) (string, error) {
+	rows, err := conn.QueryContext(ctx, postgresColumnsQuery, table)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return "", err
+		}
+		columns = append(columns, pgx.Identifier{column}.Sanitize())
+	}
+	return strings.Join(columns, ", "), rows.Err()
+}
+
+// pgConn returns the pgx connection behind a database/sql driver
+// connection of the pgx driver.
+func pgConn(driverConn any) *pgconn.PgConn {
+	return driverConn.(*stdlib.Conn).Conn().PgConn()
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// PostgreSQL backup tests.
+// This file verifies the table order and COPY block reading of the logical
+// dump. The round trip against a live server runs only when
+// BMS_TEST_POSTGRES_DSN names one; it backs up a scratch schema and
+// restores it into another.
+
+package backup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+)
+
+// PostgreSQL tests. {{{
+
+func TestOrderTables(t *testing.T) {
+	tables := []string{"contact", "log", "operator", "station"}
+	parents := map[string][]string{
+		"contact": {"log", "operator"},
+		"log":     {"station", "log"},
+		"station": {"external"},
+	}
+	got := orderTables(tables, parents)
+	want := []string{"station", "log", "operator", "contact"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCopyData(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("a\tb\\nc\n\\N\td\n\\.\nnext\n"))
+	data, lines, err := copyData(reader)
+	if err != nil || string(data) != "a\tb\\nc\n\\N\td\n" || lines != 3 {
+		t.Fatalf("unexpected rows %q, %d, %v", data, lines, err)
+	}
+	if _, _, err := copyData(bufio.NewReader(strings.NewReader("a\tb\n"))); err == nil {
+		t.Fatal("expected an unterminated block to fail")
+	}
+}
+
+// postgresScratch creates a schema on the server in dsn and returns
+// settings that use it.
+func postgresScratch(t *testing.T, dsn string, name string) config.DatabaseConfig {
+	t.Helper()
+	ctx := context.Background()
+	settings := config.DefaultConfig().Database
+	settings.Driver, settings.DSN = config.DriverPostgres, dsn
+	admin, err := db.Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = admin.ExecContext(context.Background(), "DROP SCHEMA "+name+" CASCADE")
+		_ = admin.Close()
+	})
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+name); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("BMS_TEST_POSTGRES_DSN must be a URL: %v", err)
+	}
+	query := parsed.Query()
+	query.Set("search_path", name)
+	parsed.RawQuery = query.Encode()
+	settings.DSN = parsed.String()
+	return settings
+}
+
+func TestPostgresRoundTrip(t *testing.T) {
+	dsn := os.Getenv("BMS_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("BMS_TEST_POSTGRES_DSN is not set")
+	}
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	files := map[string]string{
+		"1_station.up.sql":   "CREATE TABLE station (id bigserial PRIMARY KEY, callsign text NOT NULL);",
+		"1_station.down.sql": "-- IRREVERSIBLE: drops stations.\nDROP TABLE station;",
+		"2_contact.up.sql":   "CREATE TABLE contact (id bigserial PRIMARY KEY, station_id bigint NOT NULL REFERENCES station (id), remark text);",
+		"2_contact.down.sql": "-- IRREVERSIBLE: drops contacts.\nDROP TABLE contact;",
+	}
+
+	source := postgresScratch(t, dsn, fmt.Sprintf("bms_backup_%d", suffix))
+	database := openMigrated(t, source, files)
+	for _, statement := range []string{
+		"INSERT INTO station (callsign) VALUES ('HA5BMS')",
+		"INSERT INTO contact (station_id, remark) VALUES (1, E'tab\there, newline\nthere')",
+	} {
+		if _, err := database.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	created, err := Create(ctx, database, t.TempDir(), "server-a")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Manifest.SchemaVersion != 2 {
+		t.Fatalf("expected version 2, got %+v", created.Manifest)
+	}
+
+	target := postgresScratch(t, dsn, fmt.Sprintf("bms_restore_%d", suffix))
+	restored, err := Restore(ctx, target, created, RestoreOptions{Migrations: tree(files), ServerID: "server-a"})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if len(restored.Applied) != 0 {
+		t.Fatalf("expected no migrations after loading, got %+v", restored.Applied)
+	}
+	if _, err := Restore(ctx, target, created, RestoreOptions{Migrations: tree(files), ServerID: "server-a"}); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Fatalf("expected a second restore to be refused, got %v", err)
+	}
+
+	check := openMigrated(t, target, files)
+	var remark string
+	if err := check.QueryRowContext(ctx, "SELECT remark FROM contact").Scan(&remark); err != nil || remark != "tab\there, newline\nthere" {
+		t.Fatalf("unexpected remark %q, %v", remark, err)
+	}
+	var next int64
+	if err := check.QueryRowContext(ctx, "INSERT INTO station (callsign) VALUES ('HA5XYZ') RETURNING id").Scan(&next); err != nil || next != 2 {
+		t.Fatalf("expected the sequence restored, got %d, %v", next, err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
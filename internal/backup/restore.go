@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Restore.
+// This file defines Restore, which checks a backup against the configured
+// database and this build before touching anything: the checksum must
+// match, the driver must be the same, the server.id must match unless
+// forced, and the schema version must be clean and known to this build.
+// The data is loaded at the version it was taken at, and the migrations
+// added since then run afterwards.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+)
+
+// Restore. {{{
+
+// RestoreOptions configures Restore.
+type RestoreOptions struct {
+	Migrations fs.FS        // Migration tree, as returned by migrate.Source.
+	ServerID   string       // server.id of the target server, recorded in the restored database.
+	Force      bool         // Accept a backup taken on another server.
+	Logger     *slog.Logger // Migration logger.
+}
+
+// Restored is the result of a restore.
+type Restored struct {
+	Backup   Backup              `json:"backup"`
+	Previous string              `json:"previous,omitempty"` // Replaced SQLite file, moved aside.
+	Applied  []migrate.Migration `json:"applied"`            // Migrations run after loading the data.
+}
+
+// Check reports whether the backup described by manifest can be restored
+// into a database of driver on the server serverID with migrations.
+func Check(manifest Manifest, driver config.DatabaseDriver, serverID string, force bool, migrations []migrate.Migration) error {
+	if manifest.Driver != driver {
+		return fmt.Errorf("%s: backup is %s, database.driver is %s", errtext.ErrBackupMismatch, manifest.Driver, driver)
+	}
+	if !force && manifest.ServerID != "" && serverID != "" && manifest.ServerID != serverID {
+		return fmt.Errorf("%s: taken on server %s, this is %s; use --force to restore anyway", errtext.ErrBackupMismatch, manifest.ServerID, serverID)
+	}
+	if manifest.Dirty {
+		return fmt.Errorf("%s at version %d in the backup", errtext.ErrMigrationDirty, manifest.SchemaVersion)
+	}
+	if manifest.SchemaVersion == 0 {
+		return nil
+	}
+	for _, migration := range migrations {
+		if migration.Version == manifest.SchemaVersion {
+			return nil
+		}
+	}
+	if len(migrations) == 0 || manifest.SchemaVersion > migrations[len(migrations)-1].Version {
+		return fmt.Errorf("%s: version %d (written by bmsd %s)", errtext.ErrBackupNewer, manifest.SchemaVersion, manifest.BMSVersion)
+	}
+	return fmt.Errorf("%s: %d", errtext.ErrMigrationUnknown, manifest.SchemaVersion)
+}
+
+// Restore verifies and checks backup, loads it into the database named by
+// settings, and migrates it to the latest version. The server must not be
+// running; a SQLite restore refuses a database file another process has
+// open.
+func Restore(ctx context.Context, settings config.DatabaseConfig, backup Backup, options RestoreOptions) (Restored, error) {
+	restored, err := restore(ctx, settings, backup, options)
+	if err != nil {
+		return restored, fmt.Errorf("%s: %w", errtext.ErrRestoreFailed, err)
+	}
+	return restored, nil
+}
+
+func restore(ctx context.Context, settings config.DatabaseConfig, backup Backup, options RestoreOptions) (Restored, error) {
+	if err := backup.Verify(); err != nil {
+		return Restored{Backup: backup}, err
+	}
+	migrations, err := migrate.Load(options.Migrations, settings.Driver)
+	if err != nil {
+		return Restored{Backup: backup}, err
+	}
+	if err := Check(backup.Manifest, settings.Driver, options.ServerID, options.Force, migrations); err != nil {
+		return Restored{Backup: backup}, err
+	}
+
+	if options.Logger == nil {
+		options.Logger = slog.New(slog.DiscardHandler)
+	}
+	stamp := time.Now().UTC().Format(nameLayout)
+	switch settings.Driver {
+	case config.DriverSQLite:
+		return restoreSQLite(ctx, settings, backup, options, stamp)
+	case config.DriverPostgres:
+		return restorePostgres(ctx, settings, backup, options)
+	}
+	return Restored{Backup: backup}, fmt.Errorf("%s: %q", errtext.ErrDatabaseDriver, settings.Driver)
+}
+
+// recordServerID stores serverID in server_meta so the restored data belongs
+// to the target server, including after a forced restore. Schemas without
+// server_meta are left alone.
+func recordServerID(ctx context.Context, database *db.DB, serverID string) error {
+	if serverID == "" {
+		return nil
+	}
+	if _, err := database.Meta(ctx, db.MetaServerID); err != nil {
+		return nil
+	}
+	return database.SetMeta(ctx, db.MetaServerID, serverID)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// SQLite backups.
+// This file copies a SQLite database with VACUUM INTO, which writes a
+// compacted, consistent snapshot while the server keeps running, and reads
+// the schema version from the copy itself. Restore first locks the database
+// file, refusing while a server has it open, then migrates a staging copy
+// next to it and only then renames it into place with the mode and owner of
+// the file it replaces. The previous file is kept as
+// <file>.pre-restore-<time>, and put back if the final rename fails.
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+)
+
+// SQLite. {{{
+
+// sqliteSidecars are the files SQLite keeps next to a WAL database.
+var sqliteSidecars = []string{"-wal", "-shm"}
+
+// lockBusyTimeout is how long a restore waits for the database lock.
+const lockBusyTimeout = "100ms"
+
+func dumpSQLite(ctx context.Context, database *db.DB, path string) (uint64, bool, error) {
+	if _, err := database.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return 0, false, err
+	}
+	if err := os.Chmod(path, fileMode); err != nil {
+		return 0, false, err
+	}
+	return sqliteVersion(ctx, path)
+}
+
+// sqliteVersion reads the migration version of the SQLite file at path
+// without writing to it.
+func sqliteVersion(ctx context.Context, path string) (uint64, bool, error) {
+	settings := config.DefaultConfig().Database
+	settings.Driver = config.DriverSQLite
+	settings.DSN = "file:" + path + "?" + url.Values{"mode": {"ro"}, "_journal_mode": {""}}.Encode()
+	database, err := db.Open(settings)
+	if err != nil {
+		return 0, false, err
+	}
+	defer database.Close()
+
+	var exists int
+	if err := database.QueryRowContext(ctx, `SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	return scanVersion(database.QueryRowContext(ctx, versionQuery), exists > 0)
+}
+
+// restoreSQLite replaces the database file named by settings with the
+// backup, migrated to the latest version.
+func restoreSQLite(ctx context.Context, settings config.DatabaseConfig, backup Backup, options RestoreOptions, stamp string) (restored Restored, err error) {
+	restored = Restored{Backup: backup, Applied: []migrate.Migration{}}
+	target, query, _ := strings.Cut(strings.TrimPrefix(settings.DSN, "file:"), "?")
+	if target == "" || target == ":memory:" || strings.Contains(query, "mode=memory") {
+		return restored, fmt.Errorf("%s: %s", errtext.ErrRestoreInMemory, settings.DSN)
+	}
+
+	previous := target + ".pre-restore-" + stamp
+	if _, err := os.Lstat(previous); !errors.Is(err, fs.ErrNotExist) {
+		return restored, fmt.Errorf("%s: %s", errtext.ErrPathExists, previous)
+	}
+	current, err := os.Stat(target)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return restored, err
+	}
+	if current != nil {
+		var unlock func() error
+		if unlock, err = lockSQLite(ctx, settings, target, query); err != nil {
+			return restored, err
+		}
+		defer func() {
+			err = errors.Join(err, unlock())
+		}()
+	}
+
+	staging := target + ".restore-" + stamp
+	if err := copyFile(backup.DataPath(), staging); err != nil {
+		return restored, err
+	}
+	applied, err := migrateStaging(ctx, settings, staging, query, options)
+	if err != nil {
+		return restored, errors.Join(err, removeSQLite(staging))
+	}
+	restored.Applied = append(restored.Applied, applied...)
+
+	if current != nil {
+		if err := errors.Join(os.Chmod(staging, current.Mode().Perm()), matchOwner(staging, current)); err != nil {
+			return restored, errors.Join(err, removeSQLite(staging))
+		}
+		if err := moveSQLite(target, previous); err != nil {
+			return restored, errors.Join(err, removeSQLite(staging))
+		}
+		restored.Previous = previous
+	}
+	if err := os.Rename(staging, target); err != nil {
+		if restored.Previous != "" {
+			err = errors.Join(err, moveSQLite(previous, target))
+			restored.Previous = ""
+		}
+		return restored, errors.Join(err, removeSQLite(staging))
+	}
+	return restored, syncDir(filepath.Dir(target))
+}
+
+// lockSQLite takes an exclusive lock on the database file at path and
+// returns a function that releases it. Leaving WAL mode fails while any
+// other connection has the file open, so a running server is refused even
+// when idle; BEGIN EXCLUSIVE then keeps new connections out until release.
+// The switch also checkpoints the WAL, so the file is complete on its own
+// when it is moved aside.
+func lockSQLite(ctx context.Context, settings config.DatabaseConfig, path string, query string) (func() error, error) {
+	settings.DSN = "file:" + path
+	if query != "" {
+		settings.DSN += "?" + query
+	}
+	settings.BusyTimeout = lockBusyTimeout
+	settings.MaxOpenConns = 1
+	database, err := db.Open(settings)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("%s: %s: %w", errtext.ErrRestoreInUse, path, err), database.Close())
+	}
+	var mode string
+	err = conn.QueryRowContext(ctx, "PRAGMA journal_mode = DELETE").Scan(&mode)
+	if err == nil && mode != "delete" {
+		err = fmt.Errorf("journal mode is %s", mode)
+	}
+	if err == nil {
+		_, err = conn.ExecContext(ctx, "BEGIN EXCLUSIVE")
+	}
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("%s: %s: %w", errtext.ErrRestoreInUse, path, err), conn.Close(), database.Close())
+	}
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), "ROLLBACK")
+		return errors.Join(err, conn.Close(), database.Close())
+	}, nil
+}
+
+// migrateStaging applies pending migrations to the staging copy, records the
+// target server, and leaves it in rollback journal mode, so the file is
+// complete without a WAL.
+func migrateStaging(ctx context.Context, settings config.DatabaseConfig, staging string, query string, options RestoreOptions) ([]migrate.Migration, error) {
+	settings.DSN = "file:" + staging
+	if query != "" {
+		settings.DSN += "?" + query
+	}
+	settings.MaxOpenConns = 1
+	database, err := db.Open(settings)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := migrate.New(database, options.Migrations, options.Logger)
+	if err != nil {
+		return nil, errors.Join(err, database.Close())
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return nil, errors.Join(err, database.Close())
+	}
+	if err := recordServerID(ctx, database, options.ServerID); err != nil {
+		return nil, errors.Join(err, database.Close())
+	}
+	if _, err := database.ExecContext(ctx, "PRAGMA journal_mode = DELETE"); err != nil {
+		return nil, errors.Join(err, database.Close())
+	}
+	if err := database.Close(); err != nil {
+		return nil, err
+	}
+	return applied, syncFile(staging)
+}
+
+// copyFile copies the file at source to a new file at target.
+func copyFile(source string, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		return errors.Join(fmt.Errorf("copy %s: %w", source, err), out.Close(), os.Remove(target))
+	}
+	if err := errors.Join(out.Sync(), out.Close()); err != nil {
+		return errors.Join(err, os.Remove(target))
+	}
+	return nil
+}
+
+// moveSQLite renames a database file and its sidecars. When a rename fails,
+// the ones already done are undone so the files stay together.
+func moveSQLite(from string, to string) error {
+	var moved []string
+	for _, suffix := range append([]string{""}, sqliteSidecars...) {
+		if err := os.Rename(from+suffix, to+suffix); err != nil {
+			if errors.Is(err, fs.ErrNotExist) && suffix != "" {
+				continue
+			}
+			for _, done := range moved {
+				err = errors.Join(err, os.Rename(to+done, from+done))
+			}
+			return err
+		}
+		moved = append(moved, suffix)
+	}
+	return nil
+}
+
+// removeSQLite removes a database file and its sidecars.
+func removeSQLite(path string) error {
+	var errs []error
+	for _, suffix := range append([]string{""}, sqliteSidecars...) {
+		if err := os.Remove(path + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Build metadata.
+// This file defines Info, the version and build metadata reported by bms and
+// bmsd. Release builds set Version, Commit, and Date with -ldflags -X; fields
+// left empty fall back to runtime/debug.ReadBuildInfo (module version, VCS
+// revision, dirty flag, commit time). APIVersion is the client/server
+// protocol version: clients warn when the server reports a different one.
+
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Build metadata. {{{
+
+// Set with -ldflags "-X github.com/SandorMiskey/bms-core/internal/buildinfo.Version=v1.2.3".
+var (
+	Version = "" // Release version.
+	Commit  = "" // VCS revision.
+	Date    = "" // Build or commit time (RFC 3339).
+)
+
+const (
+	// APIVersion is bumped on incompatible client/server API changes.
+	APIVersion = 1
+
+	develVersion = "(devel)"
+	shortCommit  = 12
+)
+
+// Info describes a binary build.
+type Info struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	Dirty      bool   `json:"dirty,omitempty"`
+	Date       string `json:"date,omitempty"`
+	GoVersion  string `json:"go_version"`
+	APIVersion int    `json:"api_version"`
+}
+
+// Get returns the build metadata of the running binary.
+var Get = sync.OnceValue(func() Info {
+	return resolve(Version, Commit, Date, readBuildInfo())
+})
+
+func readBuildInfo() *debug.BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	return info
+}
+
+// resolve merges ldflags values with the embedded build info.
+func resolve(version string, commit string, date string, build *debug.BuildInfo) Info {
+	info := Info{Version: version, Commit: commit, Date: date, GoVersion: runtime.Version(), APIVersion: APIVersion}
+	if build == nil {
+		if info.Version == "" {
+			info.Version = develVersion
+		}
+		return info
+	}
+
+	if info.Version == "" {
+		info.Version = build.Main.Version
+	}
+	if info.Version == "" {
+		info.Version = develVersion
+	}
+	if build.GoVersion != "" {
+		info.GoVersion = build.GoVersion
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Dirty, _ = strconv.ParseBool(setting.Value)
+		}
+	}
+	return info
+}
+
+// String returns a one-line summary such as
+// `v1.2.0 (3f2a9c1b7d4e, dirty) go1.25.6 api/1`.
+func (info Info) String() string {
+	var details []string
+	if info.Commit != "" {
+		commit := info.Commit
+		if len(commit) > shortCommit {
+			commit = commit[:shortCommit]
+		}
+		details = append(details, commit)
+	}
+	if info.Dirty {
+		details = append(details, "dirty")
+	}
+	if info.Date != "" {
+		details = append(details, info.Date)
+	}
+
+	summary := info.Version
+	if len(details) > 0 {
+		summary += " (" + strings.Join(details, ", ") + ")"
+	}
+	return fmt.Sprintf("%s %s api/%d", summary, info.GoVersion, info.APIVersion)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
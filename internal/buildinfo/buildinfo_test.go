@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Build metadata tests.
+// This file verifies ldflags values win over embedded build info, the
+// fallbacks used for development builds, the one-line summary, the version
+// endpoint and headers, and API version compatibility checks.
+
+package buildinfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+// Build metadata tests. {{{
+
+func TestResolveFallsBackToBuildInfo(t *testing.T) {
+	build := &debug.BuildInfo{
+		GoVersion: "go1.25.6",
+		Main:      debug.Module{Path: "github.com/SandorMiskey/bms-core", Version: "v0.3.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "3f2a9c1b7d4e5f60718293a4b5c6d7e8f9a0b1c2"},
+			{Key: "vcs.time", Value: "2026-05-01T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	info := resolve("", "", "", build)
+	if info.Version != "v0.3.0" || info.Commit != "3f2a9c1b7d4e5f60718293a4b5c6d7e8f9a0b1c2" || info.Date != "2026-05-01T10:00:00Z" || !info.Dirty {
+		t.Fatalf("unexpected info from build info: %+v", info)
+	}
+	if info.GoVersion != "go1.25.6" || info.APIVersion != APIVersion {
+		t.Fatalf("unexpected go or api version: %+v", info)
+	}
+
+	info = resolve("v1.0.0", "abc123", "2026-06-01T00:00:00Z", build)
+	if info.Version != "v1.0.0" || info.Commit != "abc123" || info.Date != "2026-06-01T00:00:00Z" {
+		t.Fatalf("expected ldflags values to win, got %+v", info)
+	}
+}
+
+func TestResolveDevelopmentBuild(t *testing.T) {
+	if info := resolve("", "", "", nil); info.Version != develVersion || info.GoVersion == "" {
+		t.Fatalf("unexpected info without build info: %+v", info)
+	}
+	build := &debug.BuildInfo{Main: debug.Module{Version: ""}}
+	if info := resolve("", "", "", build); info.Version != develVersion {
+		t.Fatalf("expected %s, got %q", develVersion, info.Version)
+	}
+}
+
+func TestInfoString(t *testing.T) {
+	info := Info{Version: "v1.2.0", Commit: "3f2a9c1b7d4e5f60718293a4", Dirty: true, GoVersion: "go1.25.6", APIVersion: 1}
+	if got, want := info.String(), "v1.2.0 (3f2a9c1b7d4e, dirty) go1.25.6 api/1"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	info = Info{Version: develVersion, GoVersion: "go1.25.6", APIVersion: 1}
+	if got, want := info.String(), "(devel) go1.25.6 api/1"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+// }}}
+// Version endpoint tests. {{{
+
+func TestHandlerAndHeaders(t *testing.T) {
+	handler := Headers(Handler())
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, Path, nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	var info Info
+	if err := json.NewDecoder(recorder.Body).Decode(&info); err != nil {
+		t.Fatalf("decode version: %v", err)
+	}
+	if info != Get() {
+		t.Fatalf("expected %+v, got %+v", Get(), info)
+	}
+	if recorder.Header().Get(HeaderVersion) != info.Version {
+		t.Fatalf("unexpected version header: %q", recorder.Header().Get(HeaderVersion))
+	}
+	if err := CheckAPI(recorder.Header()); err != nil {
+		t.Fatalf("expected compatible headers, got %v", err)
+	}
+}
+
+func TestCheckAPI(t *testing.T) {
+	if err := CheckAPI(http.Header{}); err != nil {
+		t.Fatalf("expected missing header to be accepted, got %v", err)
+	}
+
+	header := http.Header{}
+	header.Set(HeaderAPIVersion, "99")
+	header.Set(HeaderVersion, "v9.0.0")
+	err := CheckAPI(header)
+	if err == nil || !strings.Contains(err.Error(), "v9.0.0") {
+		t.Fatalf("expected incompatible api error, got %v", err)
+	}
+
+	if err := CheckAPIVersion("x", ""); err == nil {
+		t.Fatalf("expected malformed api version to be rejected")
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Version endpoint and headers.
+// This file defines GET /version, which returns Info as JSON, middleware
+// that stamps the server version and API version on every response, and
+// CheckAPI, which clients use to compare those headers with their own API
+// version.
+
+package buildinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Version endpoint. {{{
+
+const (
+	Path = "/version"
+
+	HeaderAPIVersion = "X-BMS-API-Version"
+	HeaderVersion    = "X-BMS-Version"
+)
+
+// Handler serves the running binary's Info as JSON.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(Get())
+	})
+}
+
+// Headers sets the version headers on every response from next.
+func Headers(next http.Handler) http.Handler {
+	info := Get()
+	apiVersion := strconv.Itoa(info.APIVersion)
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set(HeaderVersion, info.Version)
+		writer.Header().Set(HeaderAPIVersion, apiVersion)
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// CheckAPI compares the API version a server reported with this binary's.
+// A missing header (older server or a proxy that strips it) is not an
+// error.
+func CheckAPI(header http.Header) error {
+	reported := header.Get(HeaderAPIVersion)
+	if reported == "" {
+		return nil
+	}
+	return CheckAPIVersion(reported, header.Get(HeaderVersion))
+}
+
+// CheckAPIVersion reports an incompatibility when the server API version
+// differs from APIVersion.
+func CheckAPIVersion(reported string, serverVersion string) error {
+	apiVersion, err := strconv.Atoi(reported)
+	if err != nil || apiVersion != APIVersion {
+		return fmt.Errorf("%s: client api/%d, server api/%s (server %s)", errtext.ErrIncompatibleAPIVersion, APIVersion, reported, serverVersion)
+	}
+	return nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,331 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Certificate tests.
+// This file verifies listener TLS against real handshakes: certificate
+// reload after the files change and its report to the watcher, mutual TLS
+// with a client CA, self-signed certificates saved and reused across starts,
+// and client trust through CA files and pinned fingerprints.
+
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Test helpers. {{{
+
+type testIssuer struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+// issue creates a certificate signed by issuer (self-signed when nil) and
+// writes it and its key as PEM files named prefix.crt and prefix.key.
+func issue(t *testing.T, dir string, prefix string, issuer *testIssuer, usage x509.ExtKeyUsage) (*testIssuer, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: prefix},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+		BasicConstraintsValid: true,
+		IsCA:                  issuer == nil,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	parent, signer := template, key
+	if issuer == nil {
+		template.ExtKeyUsage = nil
+	} else {
+		parent, signer = issuer.certificate, issuer.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certPath := filepath.Join(dir, prefix+".crt")
+	keyPath := filepath.Join(dir, prefix+".key")
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+	return &testIssuer{certificate: certificate, key: key}, certPath, keyPath
+}
+
+func writePEM(t *testing.T, path string, kind string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// serve runs an HTTPS server with server's config and returns its URL.
+func serve(t *testing.T, server *Server) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	httpServer := &http.Server{
+		TLSConfig: server.TLSConfig(),
+		Handler: http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			_, _ = writer.Write([]byte("ok"))
+		}),
+		ErrorLog: log.New(io.Discard, "", 0),
+	}
+	go func() {
+		_ = httpServer.ServeTLS(listener, "", "")
+	}()
+	t.Cleanup(func() {
+		_ = httpServer.Close()
+	})
+	return "https://" + listener.Addr().String()
+}
+
+// get fetches url with the client TLS config and returns the peer
+// certificate fingerprint.
+func get(url string, client *tls.Config) (string, error) {
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: client}, Timeout: 5 * time.Second}
+	defer httpClient.CloseIdleConnections()
+	response, err := httpClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+	return Fingerprint(response.TLS.PeerCertificates[0]), nil
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// }}}
+// Listener TLS tests. {{{
+
+func TestServerReload(t *testing.T) {
+	dir := t.TempDir()
+	ca, caPath, _ := issue(t, dir, "ca", nil, x509.ExtKeyUsageServerAuth)
+	first, certPath, keyPath := issue(t, dir, "server", ca, x509.ExtKeyUsageServerAuth)
+
+	server, err := NewServer("rest", config.TLSConfig{CertFile: certPath, KeyFile: keyPath}, nil, "", discardLogger())
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	if server.Fingerprint() != Fingerprint(first.certificate) {
+		t.Fatalf("unexpected fingerprint %s", server.Fingerprint())
+	}
+	url := serve(t, server)
+	client, err := ClientConfig(config.ClientServerConfig{CAFile: caPath})
+	if err != nil {
+		t.Fatalf("client config: %v", err)
+	}
+	if fingerprint, err := get(url, client); err != nil || fingerprint != Fingerprint(first.certificate) {
+		t.Fatalf("expected first certificate, got %s, %v", fingerprint, err)
+	}
+
+	if changed, err := server.Reload(); changed || err != nil {
+		t.Fatalf("expected no reload for unchanged files, got %v, %v", changed, err)
+	}
+	second, _, _ := issue(t, dir, "server", ca, x509.ExtKeyUsageServerAuth)
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(certPath, later, later)
+	if changed, err := server.Reload(); !changed || err != nil {
+		t.Fatalf("expected reload after rotation, got %v, %v", changed, err)
+	}
+	if fingerprint, err := get(url, client); err != nil || fingerprint != Fingerprint(second.certificate) {
+		t.Fatalf("expected rotated certificate, got %s, %v", fingerprint, err)
+	}
+
+	if err := os.WriteFile(keyPath, []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := server.Reload(); err == nil {
+		t.Fatal("expected a broken key to fail the reload")
+	}
+	if fingerprint, err := get(url, client); err != nil || fingerprint != Fingerprint(second.certificate) {
+		t.Fatalf("expected the previous certificate to stay, got %s, %v", fingerprint, err)
+	}
+}
+
+func TestServerWatchReports(t *testing.T) {
+	dir := t.TempDir()
+	_, certPath, keyPath := issue(t, dir, "server", nil, x509.ExtKeyUsageServerAuth)
+	server, err := NewServer("rest", config.TLSConfig{CertFile: certPath, KeyFile: keyPath}, nil, "", discardLogger())
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	results := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.Watch(ctx, 10*time.Millisecond, func(err error) {
+		select {
+		case results <- err:
+		default:
+		}
+	})
+
+	if err := os.WriteFile(keyPath, []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	select {
+	case err := <-results:
+		if err == nil {
+			t.Fatal("expected a failed reload to be reported")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the watcher to report the reload")
+	}
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caPath, _ := issue(t, dir, "ca", nil, x509.ExtKeyUsageServerAuth)
+	_, certPath, keyPath := issue(t, dir, "server", ca, x509.ExtKeyUsageServerAuth)
+	_, clientCert, clientKey := issue(t, dir, "client", ca, x509.ExtKeyUsageClientAuth)
+
+	settings := config.TLSConfig{CertFile: certPath, KeyFile: keyPath, ClientCA: caPath, MinVersion: config.TLSVersion13}
+	server, err := NewServer("rest", settings, nil, "", discardLogger())
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	url := serve(t, server)
+
+	anonymous, _ := ClientConfig(config.ClientServerConfig{CAFile: caPath})
+	if _, err := get(url, anonymous); err == nil {
+		t.Fatal("expected a client without a certificate to be rejected")
+	}
+	authenticated, err := ClientConfig(config.ClientServerConfig{CAFile: caPath, CertFile: clientCert, KeyFile: clientKey})
+	if err != nil {
+		t.Fatalf("client config: %v", err)
+	}
+	if _, err := get(url, authenticated); err != nil {
+		t.Fatalf("expected mutual TLS to succeed, got %v", err)
+	}
+
+	legacy := authenticated.Clone()
+	legacy.MaxVersion = tls.VersionTLS12
+	if _, err := get(url, legacy); err == nil {
+		t.Fatal("expected TLS 1.2 to be rejected with min_version 1.3")
+	}
+}
+
+func TestNewServerErrors(t *testing.T) {
+	settings := config.TLSConfig{CertFile: "/nonexistent.crt", KeyFile: "/nonexistent.key"}
+	if _, err := NewServer("rest", settings, nil, "", discardLogger()); err == nil {
+		t.Fatal("expected missing certificate files to fail")
+	}
+	settings = config.TLSConfig{SelfSigned: true, ClientCA: filepath.Join(t.TempDir(), "missing.pem")}
+	if _, err := NewServer("rest", settings, nil, "", discardLogger()); err == nil {
+		t.Fatal("expected a missing client CA to fail")
+	}
+}
+
+// }}}
+// Self-signed and pinning tests. {{{
+
+func TestSelfSignedPinning(t *testing.T) {
+	server, err := NewServer("rest", config.TLSConfig{SelfSigned: true}, []string{"0.0.0.0", "station.local"}, "", discardLogger())
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	leaf := server.current.Load().Certificates[0].Leaf
+	if !strings.Contains(strings.Join(leaf.DNSNames, ","), "station.local") || len(leaf.IPAddresses) != 2 {
+		t.Fatalf("unexpected names: %v %v", leaf.DNSNames, leaf.IPAddresses)
+	}
+	url := serve(t, server)
+
+	if _, err := get(url, &tls.Config{}); err == nil {
+		t.Fatal("expected an unpinned client to reject the self-signed certificate")
+	}
+	pinned, _ := ClientConfig(config.ClientServerConfig{Fingerprint: strings.ToLower(server.Fingerprint())})
+	if _, err := get(url, pinned); err != nil {
+		t.Fatalf("expected pinned fingerprint to be accepted, got %v", err)
+	}
+	wrong, _ := ClientConfig(config.ClientServerConfig{Fingerprint: strings.Repeat("00", 32)})
+	if _, err := get(url, wrong); err == nil || !strings.Contains(err.Error(), "fingerprint mismatch") {
+		t.Fatalf("expected fingerprint mismatch, got %v", err)
+	}
+}
+
+func TestSelfSignedSaved(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tls")
+	first, err := NewServer("rest", config.TLSConfig{SelfSigned: true}, nil, dir, discardLogger())
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	certPath, keyPath := SelfSignedPaths(dir, "rest")
+	for _, path := range []string{certPath, keyPath} {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+			t.Fatalf("expected %s saved with mode 0600, got %v", path, err)
+		}
+	}
+
+	second, err := NewServer("rest", config.TLSConfig{SelfSigned: true}, nil, dir, discardLogger())
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	if second.Fingerprint() != first.Fingerprint() {
+		t.Fatalf("expected the saved certificate to be reused")
+	}
+
+	expired := first.NotAfter().Add(time.Hour)
+	certificate, created, err := LoadSelfSigned(dir, "rest", nil, expired)
+	if err != nil || !created || Fingerprint(certificate.Leaf) == first.Fingerprint() {
+		t.Fatalf("expected an expired certificate to be replaced, got created=%v, %v", created, err)
+	}
+
+	if err := os.WriteFile(keyPath, []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	if _, _, err := LoadSelfSigned(dir, "rest", nil, time.Now()); err == nil {
+		t.Fatal("expected an unreadable key to fail instead of being replaced")
+	}
+}
+
+func TestClientConfigDefaults(t *testing.T) {
+	client, err := ClientConfig(config.ClientServerConfig{REST: "https://bms.example"})
+	if client != nil || err != nil {
+		t.Fatalf("expected no client config, got %v, %v", client, err)
+	}
+	if !SameFingerprint("AB:cd", "abCD") || SameFingerprint("ab", "cd") {
+		t.Fatal("unexpected fingerprint comparison")
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Client TLS.
+// This file builds the client-side tls.Config from [client.server]: extra
+// trusted CAs on top of the system roots, a client certificate for mTLS,
+// an explicit server name, and certificate pinning by fingerprint. A pinned
+// fingerprint replaces CA verification, which is what self-signed local
+// servers need.
+
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Client TLS. {{{
+
+// ClientConfig returns the TLS config for connecting to the server, or nil
+// when settings configure nothing beyond the defaults.
+func ClientConfig(settings config.ClientServerConfig) (*tls.Config, error) {
+	if !settings.TLSEnabled() && settings.ServerName == "" {
+		return nil, nil
+	}
+
+	client := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: settings.ServerName}
+	if settings.CAFile != "" {
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = nil
+		}
+		if client.RootCAs, err = loadPool(roots, settings.CAFile); err != nil {
+			return nil, err
+		}
+	}
+	if settings.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errtext.ErrLoadTLSCertificate, err)
+		}
+		client.Certificates = []tls.Certificate{certificate}
+	}
+	if settings.Fingerprint != "" {
+		// Chain and name checks are replaced by the pin below.
+		client.InsecureSkipVerify = true
+		client.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return fmt.Errorf("%s: no certificate presented", errtext.ErrTLSFingerprintMismatch)
+			}
+			presented := Fingerprint(state.PeerCertificates[0])
+			if !SameFingerprint(presented, settings.Fingerprint) {
+				return fmt.Errorf("%s: got %s", errtext.ErrTLSFingerprintMismatch, presented)
+			}
+			return nil
+		}
+	}
+	return client, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
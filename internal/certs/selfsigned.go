@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Self-signed certificates and fingerprints.
+// This file generates the certificate used by self_signed listeners in the
+// local environment and formats SHA-256 certificate fingerprints the way
+// `openssl x509 -fingerprint -sha256` prints them, so the value logged at
+// startup can be pasted into client.server.fingerprint. The certificate and
+// key are saved on first use and reused until they expire, so a pinned
+// fingerprint survives restarts.
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Self-signed certificates. {{{
+
+const (
+	selfSignedOrganization = "bms self-signed"
+	selfSignedValidity     = 90 * 24 * time.Hour
+	serialNumberBits       = 128
+
+	selfSignedDirMode  fs.FileMode = 0o700
+	selfSignedFileMode fs.FileMode = 0o600
+)
+
+// SelfSigned returns a certificate for hosts (names or IP addresses) valid
+// from now. localhost and the loopback addresses are always included.
+func SelfSigned(hosts []string, now time.Time) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%s: %w", errtext.ErrGenerateCertificate, err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialNumberBits))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%s: %w", errtext.ErrGenerateCertificate, err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{selfSignedOrganization}, CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	seen := map[string]bool{}
+	for _, host := range append([]string{"localhost", "127.0.0.1", "::1"}, hosts...) {
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		if ip := net.ParseIP(host); ip != nil {
+			if !ip.IsUnspecified() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+			continue
+		}
+		template.DNSNames = append(template.DNSNames, host)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%s: %w", errtext.ErrGenerateCertificate, err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("%s: %w", errtext.ErrGenerateCertificate, err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// SelfSignedPaths returns the certificate and key files kept for the
+// listener called name in dir.
+func SelfSignedPaths(dir string, name string) (string, string) {
+	return filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+}
+
+// LoadSelfSigned returns the self-signed certificate saved for the listener
+// called name in dir. When the files are missing or the certificate has
+// expired, a new one is generated for hosts and saved; created reports that.
+func LoadSelfSigned(dir string, name string, hosts []string, now time.Time) (certificate tls.Certificate, created bool, err error) {
+	certPath, keyPath := SelfSignedPaths(dir, name)
+	certificate, err = tls.LoadX509KeyPair(certPath, keyPath)
+	if err == nil && now.Before(certificate.Leaf.NotAfter) {
+		return certificate, false, nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return tls.Certificate{}, false, fmt.Errorf("%s: %w", errtext.ErrLoadTLSCertificate, err)
+	}
+
+	if certificate, err = SelfSigned(hosts, now); err != nil {
+		return tls.Certificate{}, false, err
+	}
+	if err := saveSelfSigned(certificate, certPath, keyPath); err != nil {
+		return tls.Certificate{}, false, fmt.Errorf("%s: %w", errtext.ErrSaveCertificate, err)
+	}
+	return certificate, true, nil
+}
+
+// saveSelfSigned writes certificate and its key as PEM files readable only by
+// the owner. Each file is written to a temporary name and renamed, so a
+// crash never leaves half a file behind.
+func saveSelfSigned(certificate tls.Certificate, certPath string, keyPath string) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(certificate.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(certPath), selfSignedDirMode); err != nil {
+		return err
+	}
+	if err := writePrivate(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})); err != nil {
+		return err
+	}
+	return writePrivate(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Certificate[0]}))
+}
+
+func writePrivate(path string, data []byte) error {
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, data, selfSignedFileMode); err != nil {
+		return err
+	}
+	if err := os.Chmod(temporary, selfSignedFileMode); err != nil {
+		return errors.Join(err, os.Remove(temporary))
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return errors.Join(err, os.Remove(temporary))
+	}
+	return nil
+}
+
+// }}}
+// Fingerprints. {{{
+
+// Fingerprint returns the SHA-256 fingerprint of certificate as colon
+// separated upper-case hex.
+func Fingerprint(certificate *x509.Certificate) string {
+	if certificate == nil {
+		return ""
+	}
+	sum := sha256.Sum256(certificate.Raw)
+	parts := make([]string, len(sum))
+	for index, value := range sum {
+		parts[index] = fmt.Sprintf("%02X", value)
+	}
+	return strings.Join(parts, ":")
+}
+
+// SameFingerprint compares fingerprints ignoring case and colons.
+func SameFingerprint(first string, second string) bool {
+	normalize := func(value string) string {
+		return strings.ToLower(strings.ReplaceAll(value, ":", ""))
+	}
+	return normalize(first) == normalize(second)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
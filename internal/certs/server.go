@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Listener TLS.
+// This file defines Server, which turns a listener's TLS settings into a
+// tls.Config. Certificates, keys, and the client CA bundle are read from
+// disk and re-read when their size or modification time changes; every
+// handshake picks up the latest set through GetConfigForClient, so rotated
+// certificates apply without restarting or dropping open connections. A
+// failed reload keeps the previous certificate.
+
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Listener TLS. {{{
+
+// DefaultReloadInterval is how often Watch checks the certificate files.
+const DefaultReloadInterval = 30 * time.Second
+
+// Server provides the TLS config for one listener.
+type Server struct {
+	name       string
+	settings   config.TLSConfig
+	logger     *slog.Logger
+	selfSigned *tls.Certificate
+
+	mu      sync.Mutex // Serializes reloads.
+	stamp   string
+	current atomic.Pointer[tls.Config]
+}
+
+// NewServer loads the TLS settings of the listener called name. With
+// self_signed, the certificate saved in dir is used instead, and one for
+// hosts is generated when it is missing or expired. An empty dir keeps a
+// generated certificate in memory only.
+func NewServer(name string, settings config.TLSConfig, hosts []string, dir string, logger *slog.Logger) (*Server, error) {
+	server := &Server{name: name, settings: settings, logger: logger}
+	if settings.SelfSigned {
+		certificate, err := server.selfSignedCertificate(dir, hosts)
+		if err != nil {
+			return nil, err
+		}
+		server.selfSigned = &certificate
+	}
+	if _, err := server.Reload(); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+func (server *Server) selfSignedCertificate(dir string, hosts []string) (tls.Certificate, error) {
+	if dir == "" {
+		return SelfSigned(hosts, time.Now())
+	}
+	certificate, created, err := LoadSelfSigned(dir, server.name, hosts, time.Now())
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if created {
+		certPath, _ := SelfSignedPaths(dir, server.name)
+		server.logger.Info("tls self-signed certificate generated", "listener", server.name, "path", certPath)
+	}
+	return certificate, nil
+}
+
+// TLSConfig returns the config to serve with. It resolves the current
+// certificates on every handshake.
+func (server *Server) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: minVersion(server.settings.MinVersion),
+		NextProtos: []string{"h2", "http/1.1"},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return server.current.Load(), nil
+		},
+	}
+}
+
+// Fingerprint returns the SHA-256 fingerprint of the current certificate.
+func (server *Server) Fingerprint() string {
+	current := server.current.Load()
+	if current == nil || len(current.Certificates) == 0 {
+		return ""
+	}
+	return Fingerprint(current.Certificates[0].Leaf)
+}
+
+// NotAfter returns the expiry of the current certificate.
+func (server *Server) NotAfter() time.Time {
+	current := server.current.Load()
+	if current == nil || len(current.Certificates) == 0 {
+		return time.Time{}
+	}
+	return current.Certificates[0].Leaf.NotAfter
+}
+
+// Reload re-reads the certificate files when they changed and reports
+// whether a new config was installed.
+func (server *Server) Reload() (bool, error) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	stamp := fileStamp(server.settings.CertFile, server.settings.KeyFile, server.settings.ClientCA)
+	if server.current.Load() != nil && stamp == server.stamp {
+		return false, nil
+	}
+	loaded, err := server.load()
+	if err != nil {
+		return false, err
+	}
+	server.current.Store(loaded)
+	server.stamp = stamp
+	return true, nil
+}
+
+// Name returns the name of the listener.
+func (server *Server) Name() string {
+	return server.name
+}
+
+// Watch calls Reload every interval until ctx is done, logging changes and
+// failures. reloaded, when not nil, is called after each reload that
+// installed new certificates (with a nil error) or failed.
+func (server *Server) Watch(ctx context.Context, interval time.Duration, reloaded func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := server.Reload()
+		if err != nil {
+			server.logger.Error(errtext.ErrTLSReloadFailed, "listener", server.name, "error", err)
+		} else if changed {
+			server.logger.Info("tls certificate reloaded", "listener", server.name, "fingerprint", server.Fingerprint(), "not_after", server.NotAfter())
+		}
+		if reloaded != nil && (changed || err != nil) {
+			reloaded(err)
+		}
+	}
+}
+
+// load builds a config from the current files.
+func (server *Server) load() (*tls.Config, error) {
+	var certificate tls.Certificate
+	if server.selfSigned != nil {
+		certificate = *server.selfSigned
+	} else {
+		var err error
+		certificate, err = tls.LoadX509KeyPair(server.settings.CertFile, server.settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errtext.ErrLoadTLSCertificate, err)
+		}
+	}
+
+	loaded := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   minVersion(server.settings.MinVersion),
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	if server.settings.ClientCA != "" {
+		pool, err := loadPool(nil, server.settings.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		loaded.ClientCAs = pool
+		loaded.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return loaded, nil
+}
+
+// }}}
+// Helpers. {{{
+
+func minVersion(version config.TLSVersion) uint16 {
+	if version == config.TLSVersion13 {
+		return tls.VersionTLS13
+	}
+	return tls.VersionTLS12
+}
+
+// loadPool adds the PEM certificates in path to pool (a new pool when nil).
+func loadPool(pool *x509.CertPool, path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errtext.ErrLoadTLSCA, err)
+	}
+	if pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: %q: no certificates found", errtext.ErrLoadTLSCA, path)
+	}
+	return pool, nil
+}
+
+// fileStamp summarizes the size and modification time of paths.
+func fileStamp(paths ...string) string {
+	var stamp string
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			stamp += path + ":missing;"
+			continue
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return stamp
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
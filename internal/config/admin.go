@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Admin configuration.
+// This file defines AdminConfig for the [admin] section, which controls the
+// separate admin listener. It serves the token-protected admin routes such as
+// runtime log level changes and the recent-log tail, and the opt-in pprof,
+// expvar, and debug endpoints. The admin listener defaults to loopback and
+// requires the admin token on the debug endpoints unless require_auth is
+// turned off; the admin routes always require it.
+
+package config
+
+// AdminConfig configures administrative endpoints. {{{
+
+type AdminConfig struct {
+	Address     string    `toml:"address"`      // Admin listener bind address (loopback by default).
+	Debug       bool      `toml:"debug"`        // Serve goroutine dumps and build info on the admin listener.
+	Expvar      bool      `toml:"expvar"`       // Serve expvar at /debug/vars on the admin listener.
+	Pprof       bool      `toml:"pprof"`        // Serve net/http/pprof on the admin listener.
+	RequireAuth bool      `toml:"require_auth"` // Require the admin token on the admin listener.
+	TLS         TLSConfig `toml:"tls"`          // Admin listener TLS.
+	Token       string    `toml:"token"`        // Bearer token required by admin routes (empty disables them).
+}
+
+// ListenerEnabled reports whether any admin listener endpoint is turned on.
+// A token enables the admin routes.
+func (admin AdminConfig) ListenerEnabled() bool {
+	return admin.Address != "" && (admin.Debug || admin.Expvar || admin.Pprof || admin.Token != "")
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
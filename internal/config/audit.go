@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Audit configuration.
+// This file defines AuditConfig for the [audit] section, which points the
+// server at its append-only, hash-chained audit log of security-relevant
+// events.
+
+package config
+
+// AuditConfig configures the audit log. {{{
+
+type AuditConfig struct {
+	Path string `toml:"path"` // JSON Lines audit file (empty disables auditing).
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
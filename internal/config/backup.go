@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Backup configuration.
+// This file defines BackupConfig for the [backup] section, which sets where
+// `bmsd backup` writes snapshots, how often the server takes one on its own,
+// and how many are kept.
+
+package config
+
+// BackupConfig configures database backups. {{{
+
+type BackupConfig struct {
+	Directory string `toml:"directory"` // Snapshot directory (required for scheduled backups).
+	Interval  string `toml:"interval"`  // Scheduled snapshot interval (empty disables scheduling).
+	Retention int    `toml:"retention"` // Snapshots kept in directory (0 keeps all).
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
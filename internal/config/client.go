@@ -22,10 +22,22 @@ type ClientConfig struct {
 // }}}
 // Client supporting configuration structs. {{{
 
-// ClientServerConfig configures client endpoints.
+// ClientServerConfig configures client endpoints and how the client trusts
+// them over TLS. A fingerprint pins the server certificate instead of
+// verifying it against CAs, for self-signed local servers.
 type ClientServerConfig struct {
-	Address string `toml:"address"` // gRPC endpoint.
-	REST    string `toml:"rest"`    // REST endpoint.
+	Address     string `toml:"address"`     // gRPC endpoint.
+	CAFile      string `toml:"ca_file"`     // PEM CA bundle trusted for the server certificate.
+	CertFile    string `toml:"cert_file"`   // PEM client certificate for mTLS.
+	Fingerprint string `toml:"fingerprint"` // SHA-256 fingerprint of a pinned server certificate.
+	KeyFile     string `toml:"key_file"`    // PEM client private key for mTLS.
+	REST        string `toml:"rest"`        // REST endpoint.
+	ServerName  string `toml:"server_name"` // Name to verify in the server certificate.
+}
+
+// TLSEnabled reports whether the client should connect over TLS by default.
+func (server ClientServerConfig) TLSEnabled() bool {
+	return server.CAFile != "" || server.CertFile != "" || server.Fingerprint != ""
 }
 
 // ClientAuthConfig configures client-side auth persistence.
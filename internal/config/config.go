@@ -11,7 +11,10 @@ package config
 // Config holds server and client configuration sections. {{{
 
 type Config struct {
+	Admin        AdminConfig        `toml:"admin"`        // Administrative endpoint settings.
+	Audit        AuditConfig        `toml:"audit"`        // Audit log settings.
 	Auth         AuthConfig         `toml:"auth"`         // Authentication settings.
+	Backup       BackupConfig       `toml:"backup"`       // Database backup settings.
 	Database     DatabaseConfig     `toml:"database"`     // Database connectivity settings.
 	GRPC         GRPCConfig         `toml:"grpc"`         // gRPC listener configuration.
 	Integrations IntegrationsConfig `toml:"integrations"` // External integration settings.
@@ -255,10 +255,405 @@ key_auth = { enabled = true }
 	}
 }
 
+func TestValidateConfigLoggingOutputs(t *testing.T) {
+	config := DefaultConfig()
+	config.Database.Driver = DriverSQLite
+	config.Database.DSN = "file:bms.db"
+	config.Logging.Output = []LogOutputConfig{
+		{Target: LogTargetStdout, MaxSizeMB: 10},
+		{Target: "/var/log/bms/bmsd.log", MaxAge: "soon", MaxBackups: -1},
+		{Target: "/var/log/bms/bmsd.log"},
+		{},
+	}
+
+	err := ValidateConfig(config)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got: %v", err)
+	}
+
+	expected := []string{
+		"logging.output[0].target",
+		"logging.output[1].max_backups",
+		"logging.output[1].max_age",
+		"logging.output[2].target",
+		"logging.output[3].target",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d validation errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for index, path := range expected {
+		if errs[index].Path != path {
+			t.Fatalf("expected validation error %d for %s, got: %s", index, path, errs[index].Path)
+		}
+	}
+}
+
+func TestDecodeConfigLoggingOutputs(t *testing.T) {
+	input := `
+[logging]
+level = "info"
+
+[[logging.output]]
+target = "stderr"
+format = "text"
+
+[[logging.output]]
+target = "/var/log/bms/bmsd.log"
+max_size_mb = 50
+max_age = "24h"
+max_backups = 7
+compress = true
+`
+
+	overlay, err := DecodeConfigOverlay(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	result := ApplyOverlay(DefaultConfig(), overlay)
+	if len(result.Logging.Output) != 2 {
+		t.Fatalf("expected 2 logging outputs, got %d", len(result.Logging.Output))
+	}
+	if result.Logging.Output[0].Target != LogTargetStderr || result.Logging.Output[0].Format != LogFormatText {
+		t.Fatalf("unexpected first output: %+v", result.Logging.Output[0])
+	}
+	file := result.Logging.Output[1]
+	if !file.IsFile() || file.MaxSizeMB != 50 || file.MaxBackups != 7 || !file.Compress {
+		t.Fatalf("unexpected file output: %+v", file)
+	}
+}
+
+func TestLoggingThrottleConfig(t *testing.T) {
+	input := `
+[logging]
+dedup_window = "10s"
+
+[logging.rate_limits.sync]
+rate = 5
+burst = 20
+`
+
+	overlay, err := DecodeConfigOverlay(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	base := DefaultConfig()
+	base.Logging.RateLimits = map[string]LogRateLimitConfig{"database": {Rate: 1}}
+	result := ApplyOverlay(base, overlay)
+	if result.Logging.DedupWindow != "10s" {
+		t.Fatalf("expected dedup window 10s, got %q", result.Logging.DedupWindow)
+	}
+	if len(result.Logging.RateLimits) != 2 || result.Logging.RateLimits["sync"] != (LogRateLimitConfig{Burst: 20, Rate: 5}) {
+		t.Fatalf("expected rate limits merged by key, got: %+v", result.Logging.RateLimits)
+	}
+
+	result.Database.Driver = DriverSQLite
+	result.Database.DSN = "file:bms.db"
+	result.Logging.DedupWindow = "-1s"
+	result.Logging.RateLimits["rest"] = LogRateLimitConfig{Burst: -1}
+	err = ValidateConfig(result)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got: %v", err)
+	}
+	expected := []string{
+		"logging.dedup_window",
+		"logging.rate_limits.rest.rate",
+		"logging.rate_limits.rest.burst",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d validation errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for index, path := range expected {
+		if errs[index].Path != path {
+			t.Fatalf("expected validation error %d for %s, got: %s", index, path, errs[index].Path)
+		}
+	}
+}
+
+func TestDecodeConfigAudit(t *testing.T) {
+	overlay, err := DecodeConfigOverlay(strings.NewReader("[audit]\npath = \"/var/lib/bms/audit.jsonl\"\n"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	result := ApplyOverlay(DefaultConfig(), overlay)
+	if result.Audit.Path != "/var/lib/bms/audit.jsonl" {
+		t.Fatalf("expected audit path override, got %q", result.Audit.Path)
+	}
+}
+
+func TestAdminListenerConfig(t *testing.T) {
+	defaults := DefaultConfig()
+	if defaults.Admin.Address != "127.0.0.1:6060" || !defaults.Admin.RequireAuth || defaults.Admin.ListenerEnabled() {
+		t.Fatalf("unexpected admin defaults: %+v", defaults.Admin)
+	}
+	if tokenOnly := (AdminConfig{Address: defaults.Admin.Address, Token: "s3cret"}); !tokenOnly.ListenerEnabled() {
+		t.Fatal("expected admin token to enable the admin listener")
+	}
+
+	input := `
+[admin]
+address = "0.0.0.0:6060"
+pprof = true
+require_auth = false
+`
+	overlay, err := DecodeConfigOverlay(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	result := ApplyOverlay(defaults, overlay)
+	if !result.Admin.Pprof || result.Admin.RequireAuth || !result.Admin.ListenerEnabled() {
+		t.Fatalf("expected admin overrides, got: %+v", result.Admin)
+	}
+	result.Database.Driver = DriverSQLite
+	result.Database.DSN = "file:bms.db"
+
+	var errs ValidationErrors
+	if err := ValidateConfig(result); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "admin.require_auth" {
+		t.Fatalf("expected admin.require_auth error, got: %v", err)
+	}
+	warnings := CollectConfigWarnings(result)
+	if len(warnings) != 1 || warnings[0].Path != "admin.address" {
+		t.Fatalf("expected admin.address warning, got: %v", warnings)
+	}
+
+	result.Admin.RequireAuth = true
+	if err := ValidateConfig(result); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "admin.token" {
+		t.Fatalf("expected admin.token error, got: %v", err)
+	}
+
+	result.Admin.Address = "localhost:6060"
+	result.Admin.RequireAuth = false
+	if err := ValidateConfig(result); err != nil {
+		t.Fatalf("expected loopback listener without auth to validate, got: %v", err)
+	}
+}
+
+func TestServerShutdownConfig(t *testing.T) {
+	defaults := DefaultConfig()
+	if defaults.Server.ShutdownTimeout != "15s" || defaults.Server.DrainDelay != "0s" {
+		t.Fatalf("unexpected server defaults: %+v", defaults.Server)
+	}
+
+	overlay, err := DecodeConfigOverlay(strings.NewReader("[server]\ndrain_delay = \"5s\"\nshutdown_timeout = \"0s\"\n"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	result := ApplyOverlay(defaults, overlay)
+	if result.Server.DrainDelay != "5s" {
+		t.Fatalf("expected drain delay override, got %q", result.Server.DrainDelay)
+	}
+	result.Database.Driver = DriverSQLite
+	result.Database.DSN = "file:bms.db"
+
+	var errs ValidationErrors
+	if err := ValidateConfig(result); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "server.shutdown_timeout" {
+		t.Fatalf("expected server.shutdown_timeout error, got: %v", err)
+	}
+}
+
+func TestListenerTLSConfig(t *testing.T) {
+	input := `
+[server]
+environment = "remote"
+
+[rest]
+address = "0.0.0.0:8080"
+
+[rest.tls]
+self_signed = true
+client_ca = "/etc/bms/clients.pem"
+min_version = "1.1"
+
+[websocket]
+address = "0.0.0.0:8081"
+
+[client.server]
+cert_file = "/etc/bms/client.pem"
+fingerprint = "AB:CD"
+`
+	overlay, err := DecodeConfigOverlay(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	result := ApplyOverlay(DefaultConfig(), overlay)
+	if !result.REST.TLS.Enabled() || result.REST.TLS.ClientCA != "/etc/bms/clients.pem" || !result.Client.Server.TLSEnabled() {
+		t.Fatalf("expected tls overrides, got: %+v %+v", result.REST.TLS, result.Client.Server)
+	}
+	result.Database.Driver = DriverSQLite
+	result.Database.DSN = "file:bms.db"
+
+	var errs ValidationErrors
+	if err := ValidateConfig(result); !errors.As(err, &errs) {
+		t.Fatalf("expected validation errors, got: %v", err)
+	}
+	paths := make([]string, 0, len(errs))
+	for _, fieldError := range errs {
+		paths = append(paths, fieldError.Path)
+	}
+	want := "rest.tls.self_signed,rest.tls.min_version,client.server.key_file,client.server.fingerprint"
+	if strings.Join(paths, ",") != want {
+		t.Fatalf("expected %s, got %v", want, paths)
+	}
+	warnings := CollectConfigWarnings(result)
+	if len(warnings) != 1 || warnings[0].Path != "websocket.tls" {
+		t.Fatalf("expected websocket.tls warning, got: %v", warnings)
+	}
+
+	result.Server.Environment = EnvLocal
+	result.REST.TLS.MinVersion = TLSVersion13
+	result.Client.Server = ClientServerConfig{Fingerprint: strings.Repeat("ab:", 31) + "ab"}
+	if err := ValidateConfig(result); err != nil {
+		t.Fatalf("expected local self-signed tls to validate, got: %v", err)
+	}
+
+	result.REST.TLS = TLSConfig{CertFile: "/etc/bms/server.pem", ClientCA: "/etc/bms/clients.pem"}
+	if err := ValidateConfig(result); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "rest.tls.key_file" {
+		t.Fatalf("expected rest.tls.key_file error, got: %v", err)
+	}
+}
+
 func boolPointer(value bool) *bool {
 	return &value
 }
 
+func TestUnixSocketConfig(t *testing.T) {
+	input := `
+[server]
+environment = "remote"
+
+[rest]
+address = "unix:///run/bms/bmsd.sock"
+
+[rest.socket]
+mode = "0640"
+group = "bms"
+
+[grpc]
+address = "unix://run/bms/grpc.sock"
+
+[grpc.socket]
+mode = "0999"
+
+[client.server]
+rest = "unix://bmsd.sock"
+`
+	overlay, err := DecodeConfigOverlay(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	result := ApplyOverlay(DefaultConfig(), overlay)
+	if result.REST.Socket.Mode != "0640" || result.REST.Socket.Group != "bms" {
+		t.Fatalf("expected rest socket overrides, got: %+v", result.REST.Socket)
+	}
+	if path, unix := UnixSocketPath(result.REST.Address); !unix || path != "/run/bms/bmsd.sock" {
+		t.Fatalf("expected unix socket path, got: %q %v", path, unix)
+	}
+	if DefaultConfig().GRPC.Socket.Mode != "0660" {
+		t.Fatalf("expected default socket mode 0660, got: %q", DefaultConfig().GRPC.Socket.Mode)
+	}
+	result.Database.Driver = DriverSQLite
+	result.Database.DSN = "file:bms.db"
+
+	var errs ValidationErrors
+	if err := ValidateConfig(result); !errors.As(err, &errs) {
+		t.Fatalf("expected validation errors, got: %v", err)
+	}
+	paths := make([]string, 0, len(errs))
+	for _, fieldError := range errs {
+		paths = append(paths, fieldError.Path)
+	}
+	want := "client.server.rest,grpc.address,grpc.socket.mode"
+	if strings.Join(paths, ",") != want {
+		t.Fatalf("expected %s, got %v", want, paths)
+	}
+
+	result.GRPC.Address = "127.0.0.1:9090"
+	result.GRPC.Socket.Mode = "0660"
+	result.Client.Server.REST = result.REST.Address
+	if err := ValidateConfig(result); err != nil {
+		t.Fatalf("expected unix socket config to validate, got: %v", err)
+	}
+	for _, warning := range CollectConfigWarnings(result) {
+		if warning.Path == "rest.tls" {
+			t.Fatalf("expected no tls warning for a unix socket, got: %v", warning)
+		}
+	}
+}
+
+func TestDatabasePoolConfig(t *testing.T) {
+	input := `
+[database]
+driver = "sqlite"
+dsn = "file:bms.db"
+auto_migrate = true
+busy_timeout = "250ms"
+conn_max_idle_time = "later"
+connect_timeout = "-1s"
+max_open_conns = 4
+max_idle_conns = -1
+`
+	overlay, err := DecodeConfigOverlay(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	result := ApplyOverlay(DefaultConfig(), overlay)
+	if result.Database.BusyTimeout != "250ms" || result.Database.MaxOpenConns != 4 || result.Database.ConnMaxLifetime != "30m" || !result.Database.AutoMigrate {
+		t.Fatalf("expected database overrides on top of defaults, got: %+v", result.Database)
+	}
+
+	var errs ValidationErrors
+	if err := ValidateConfig(result); !errors.As(err, &errs) {
+		t.Fatalf("expected validation errors, got: %v", err)
+	}
+	paths := make([]string, 0, len(errs))
+	for _, fieldError := range errs {
+		paths = append(paths, fieldError.Path)
+	}
+	want := "database.conn_max_idle_time,database.connect_timeout,database.max_idle_conns"
+	if strings.Join(paths, ",") != want {
+		t.Fatalf("expected %s, got %v", want, paths)
+	}
+}
+
+func TestBackupConfig(t *testing.T) {
+	result := DefaultConfig()
+	if result.Backup.Retention != defaultBackupRetention || result.Backup.Interval != "" {
+		t.Fatalf("expected manual backups with default retention, got: %+v", result.Backup)
+	}
+
+	input := `
+[backup]
+interval = "6h"
+retention = 0
+`
+	overlay, err := DecodeConfigOverlay(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	result = ApplyOverlay(result, overlay)
+	result.Database.Driver, result.Database.DSN = DriverSQLite, "file:bms.db"
+	var errs ValidationErrors
+	if err := ValidateConfig(result); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "backup.directory" {
+		t.Fatalf("expected backup.directory error, got: %v", err)
+	}
+
+	result.Backup.Directory = "/var/backups/bms"
+	if err := ValidateConfig(result); err != nil {
+		t.Fatalf("expected valid backup config, got: %v", err)
+	}
+	warnings := CollectConfigWarnings(result)
+	if len(warnings) != 1 || warnings[0].Path != "backup.retention" {
+		t.Fatalf("expected backup.retention warning, got: %v", warnings)
+	}
+
+	result.Backup.Interval, result.Backup.Retention = "0s", -1
+	errs = nil
+	if err := ValidateConfig(result); !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected interval and retention errors, got: %v", err)
+	}
+}
+
 // }}}
 
 // vim: set ts=4 sw=4 noet:
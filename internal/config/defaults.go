@@ -12,24 +12,58 @@ package config
 // Default values. {{{
 
 const (
+	defaultAdminAddress           = "127.0.0.1:6060"
 	defaultAuthTokenTTL           = "168h"
+	defaultBackupRetention        = 7
+	defaultDatabaseBusyTimeout    = "5s"
+	defaultDatabaseConnectTimeout = "5s"
+	defaultDatabaseMaxIdleConns   = 2
+	defaultDatabaseMaxLifetime    = "30m"
+	defaultDatabaseMaxOpenConns   = 10
 	defaultRefreshBeforeExpiry    = 0.8
 	defaultServerAuthTokenStorage = AuthTokenStorageKeychain
+	defaultServerDrainDelay       = "0s"
+	defaultServerShutdownTimeout  = "15s"
+	defaultSocketMode             = "0660"
 )
 
 // DefaultConfig returns the baseline configuration defaults.
 func DefaultConfig() Config {
 	return Config{
+		Admin: AdminConfig{
+			Address:     defaultAdminAddress,
+			RequireAuth: true,
+		},
 		Auth: AuthConfig{
 			RefreshBeforeExpiry: defaultRefreshBeforeExpiry,
 			TokenStorage:        defaultServerAuthTokenStorage,
 			TokenTTL:            defaultAuthTokenTTL,
 		},
+		Backup: BackupConfig{
+			Retention: defaultBackupRetention,
+		},
 		Client: ClientConfig{
 			Auth: ClientAuthConfig{
 				RefreshBeforeExpiry: defaultRefreshBeforeExpiry,
 			},
 		},
+		Database: DatabaseConfig{
+			BusyTimeout:     defaultDatabaseBusyTimeout,
+			ConnMaxLifetime: defaultDatabaseMaxLifetime,
+			ConnectTimeout:  defaultDatabaseConnectTimeout,
+			MaxIdleConns:    defaultDatabaseMaxIdleConns,
+			MaxOpenConns:    defaultDatabaseMaxOpenConns,
+		},
+		GRPC: GRPCConfig{
+			Socket: SocketConfig{Mode: defaultSocketMode},
+		},
+		REST: RESTConfig{
+			Socket: SocketConfig{Mode: defaultSocketMode},
+		},
+		Server: ServerConfig{
+			DrainDelay:      defaultServerDrainDelay,
+			ShutdownTimeout: defaultServerShutdownTimeout,
+		},
 	}
 }
 
@@ -13,6 +13,7 @@ import "os"
 // Env overrides. {{{
 
 const (
+	envAdminToken     = "BMS_ADMIN_TOKEN"
 	envAuthMode       = "BMS_AUTH_MODE"
 	envDatabaseDSN    = "BMS_DATABASE_DSN"
 	envDatabaseDriver = "BMS_DATABASE_DRIVER"
@@ -24,6 +25,10 @@ const (
 func ApplyEnvOverrides(base Config) (Config, error) {
 	overlay := ConfigOverlay{}
 
+	if value := os.Getenv(envAdminToken); value != "" {
+		overlay.Admin = ensureAdminOverlay(overlay.Admin)
+		overlay.Admin.Token = stringPointer(value)
+	}
 	if value := os.Getenv(envDatabaseDSN); value != "" {
 		overlay.Database = ensureDatabaseOverlay(overlay.Database)
 		overlay.Database.DSN = stringPointer(value)
@@ -51,6 +56,13 @@ func ApplyEnvOverrides(base Config) (Config, error) {
 // }}}
 // Env overlay helpers. {{{
 
+func ensureAdminOverlay(overlay *AdminConfigOverlay) *AdminConfigOverlay {
+	if overlay != nil {
+		return overlay
+	}
+	return &AdminConfigOverlay{}
+}
+
 func ensureAuthOverlay(overlay *AuthConfigOverlay) *AuthConfigOverlay {
 	if overlay != nil {
 		return overlay
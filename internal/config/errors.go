@@ -22,8 +22,8 @@ var ErrConfigNotFound = errors.New("config file not found")
 var ErrConfigPathIsDir = errors.New("config path is a directory")
 
 type FieldError struct {
-	Message string `toml:"-"` // Validation error message.
-	Path    string `toml:"-"` // Config field path.
+	Message string `toml:"-" json:"message"` // Validation error message.
+	Path    string `toml:"-" json:"path"`    // Config field path.
 }
 
 func (err FieldError) Error() string {
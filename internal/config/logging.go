@@ -4,6 +4,9 @@
 // Logging configuration.
 // This file defines log format and log level enums plus LoggingConfig for the
 // [logging] section, which controls structured log output at runtime.
+// Outputs are declared as [[logging.output]] entries; each entry targets
+// stdout, stderr, or a file path and may override the format and level.
+// Noisy events are throttled by dedup_window and [logging.rate_limits.<name>].
 
 package config
 
@@ -12,8 +15,9 @@ package config
 type LogFormat string
 
 const (
-	LogFormatJSON LogFormat = "json"
-	LogFormatText LogFormat = "text"
+	LogFormatConsole LogFormat = "console"
+	LogFormatJSON    LogFormat = "json"
+	LogFormatText    LogFormat = "text"
 )
 
 // }}}
@@ -32,8 +36,45 @@ const (
 // LoggingConfig configures structured logging output. {{{
 
 type LoggingConfig struct {
-	Format LogFormat `toml:"format"` // Log format (`json` or `text`).
-	Level  LogLevel  `toml:"level"`  // Minimum log level (`debug`, `info`, `warn`, `error`).
+	BufferSize  int                           `toml:"buffer_size"`  // Recent records kept in memory (0 uses the binary default).
+	Components  map[string]LogLevel           `toml:"components"`   // Per-component minimum levels.
+	DedupWindow string                        `toml:"dedup_window"` // Suppress identical records within this window (duration string, empty disables).
+	Format      LogFormat                     `toml:"format"`       // Log format (`console`, `json`, or `text`).
+	Level       LogLevel                      `toml:"level"`        // Minimum log level (`debug`, `info`, `warn`, `error`).
+	Output      []LogOutputConfig             `toml:"output"`       // Log outputs (empty means stdout).
+	RateLimits  map[string]LogRateLimitConfig `toml:"rate_limits"`  // Per-component token-bucket limits.
+	RedactKeys  []string                      `toml:"redact_keys"`  // Extra attribute keys to mask in log output.
+}
+
+// }}}
+// LogOutputConfig configures a single log output. {{{
+
+const (
+	LogTargetStderr = "stderr"
+	LogTargetStdout = "stdout"
+)
+
+type LogOutputConfig struct {
+	Compress   bool      `toml:"compress"`    // Gzip rotated files.
+	Format     LogFormat `toml:"format"`      // Output format override (defaults to logging.format).
+	Level      LogLevel  `toml:"level"`       // Output level override (defaults to logging.level).
+	MaxAge     string    `toml:"max_age"`     // Rotate when the file is older than this (duration string).
+	MaxBackups int       `toml:"max_backups"` // Rotated files to retain (0 keeps all).
+	MaxSizeMB  int       `toml:"max_size_mb"` // Rotate when the file exceeds this size (0 disables).
+	Target     string    `toml:"target"`      // `stdout`, `stderr`, or a file path.
+}
+
+// IsFile reports whether the output writes to a file path.
+func (output LogOutputConfig) IsFile() bool {
+	return output.Target != LogTargetStdout && output.Target != LogTargetStderr
+}
+
+// }}}
+// LogRateLimitConfig configures a per-component token bucket. {{{
+
+type LogRateLimitConfig struct {
+	Burst int     `toml:"burst"` // Records allowed in a burst (0 uses the rate, at least 1).
+	Rate  float64 `toml:"rate"`  // Sustained records per second.
 }
 
 // }}}
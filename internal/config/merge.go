@@ -18,9 +18,18 @@ package config
 
 // ApplyOverlay merges an overlay into a base Config.
 func ApplyOverlay(base Config, overlay ConfigOverlay) Config {
+	if overlay.Admin != nil {
+		base.Admin = mergeAdminConfig(base.Admin, *overlay.Admin)
+	}
+	if overlay.Audit != nil {
+		base.Audit = mergeAuditConfig(base.Audit, *overlay.Audit)
+	}
 	if overlay.Auth != nil {
 		base.Auth = mergeAuthConfig(base.Auth, *overlay.Auth)
 	}
+	if overlay.Backup != nil {
+		base.Backup = mergeBackupConfig(base.Backup, *overlay.Backup)
+	}
 	if overlay.Database != nil {
 		base.Database = mergeDatabaseConfig(base.Database, *overlay.Database)
 	}
@@ -61,28 +70,103 @@ func ApplyOverlay(base Config, overlay ConfigOverlay) Config {
 // }}}
 // Server merge helpers. {{{
 
-// This block merges server-side sections (server, database, logging, transport,
-// integrations, plugins, sync, telemetry). Each helper accepts a base section
+// This block merges server-side sections (server, admin, database, logging,
+// transport, integrations, plugins, sync, telemetry). Each helper accepts a base section
 // plus its overlay and returns the updated section, applying only non-nil fields.
 
 func mergeServerConfig(base ServerConfig, overlay ServerConfigOverlay) ServerConfig {
+	if overlay.DrainDelay != nil {
+		base.DrainDelay = *overlay.DrainDelay
+	}
 	if overlay.Environment != nil {
 		base.Environment = *overlay.Environment
 	}
 	if overlay.ID != nil {
 		base.ID = *overlay.ID
 	}
+	if overlay.ShutdownTimeout != nil {
+		base.ShutdownTimeout = *overlay.ShutdownTimeout
+	}
+
+	return base
+}
+
+func mergeAdminConfig(base AdminConfig, overlay AdminConfigOverlay) AdminConfig {
+	if overlay.Address != nil {
+		base.Address = *overlay.Address
+	}
+	if overlay.Debug != nil {
+		base.Debug = *overlay.Debug
+	}
+	if overlay.Expvar != nil {
+		base.Expvar = *overlay.Expvar
+	}
+	if overlay.Pprof != nil {
+		base.Pprof = *overlay.Pprof
+	}
+	if overlay.RequireAuth != nil {
+		base.RequireAuth = *overlay.RequireAuth
+	}
+	if overlay.TLS != nil {
+		base.TLS = mergeTLSConfig(base.TLS, *overlay.TLS)
+	}
+	if overlay.Token != nil {
+		base.Token = *overlay.Token
+	}
+
+	return base
+}
+
+func mergeAuditConfig(base AuditConfig, overlay AuditConfigOverlay) AuditConfig {
+	if overlay.Path != nil {
+		base.Path = *overlay.Path
+	}
+
+	return base
+}
+
+func mergeBackupConfig(base BackupConfig, overlay BackupConfigOverlay) BackupConfig {
+	if overlay.Directory != nil {
+		base.Directory = *overlay.Directory
+	}
+	if overlay.Interval != nil {
+		base.Interval = *overlay.Interval
+	}
+	if overlay.Retention != nil {
+		base.Retention = *overlay.Retention
+	}
 
 	return base
 }
 
 func mergeDatabaseConfig(base DatabaseConfig, overlay DatabaseConfigOverlay) DatabaseConfig {
+	if overlay.AutoMigrate != nil {
+		base.AutoMigrate = *overlay.AutoMigrate
+	}
+	if overlay.BusyTimeout != nil {
+		base.BusyTimeout = *overlay.BusyTimeout
+	}
+	if overlay.ConnMaxIdleTime != nil {
+		base.ConnMaxIdleTime = *overlay.ConnMaxIdleTime
+	}
+	if overlay.ConnMaxLifetime != nil {
+		base.ConnMaxLifetime = *overlay.ConnMaxLifetime
+	}
+	if overlay.ConnectTimeout != nil {
+		base.ConnectTimeout = *overlay.ConnectTimeout
+	}
 	if overlay.DSN != nil {
 		base.DSN = *overlay.DSN
 	}
 	if overlay.Driver != nil {
 		base.Driver = *overlay.Driver
 	}
+	if overlay.MaxIdleConns != nil {
+		base.MaxIdleConns = *overlay.MaxIdleConns
+	}
+	if overlay.MaxOpenConns != nil {
+		base.MaxOpenConns = *overlay.MaxOpenConns
+	}
 	if overlay.Migrations != nil {
 		base.Migrations = *overlay.Migrations
 	}
@@ -91,12 +175,44 @@ func mergeDatabaseConfig(base DatabaseConfig, overlay DatabaseConfigOverlay) Dat
 }
 
 func mergeLoggingConfig(base LoggingConfig, overlay LoggingConfigOverlay) LoggingConfig {
+	if overlay.BufferSize != nil {
+		base.BufferSize = *overlay.BufferSize
+	}
+	if overlay.Components != nil {
+		components := make(map[string]LogLevel, len(base.Components)+len(overlay.Components))
+		for component, level := range base.Components {
+			components[component] = level
+		}
+		for component, level := range overlay.Components {
+			components[component] = level
+		}
+		base.Components = components
+	}
+	if overlay.DedupWindow != nil {
+		base.DedupWindow = *overlay.DedupWindow
+	}
 	if overlay.Format != nil {
 		base.Format = *overlay.Format
 	}
 	if overlay.Level != nil {
 		base.Level = *overlay.Level
 	}
+	if overlay.Output != nil {
+		base.Output = append([]LogOutputConfig(nil), (*overlay.Output)...)
+	}
+	if overlay.RateLimits != nil {
+		limits := make(map[string]LogRateLimitConfig, len(base.RateLimits)+len(overlay.RateLimits))
+		for component, limit := range base.RateLimits {
+			limits[component] = limit
+		}
+		for component, limit := range overlay.RateLimits {
+			limits[component] = limit
+		}
+		base.RateLimits = limits
+	}
+	if overlay.RedactKeys != nil {
+		base.RedactKeys = append([]string(nil), (*overlay.RedactKeys)...)
+	}
 
 	return base
 }
@@ -105,6 +221,12 @@ func mergeGRPCConfig(base GRPCConfig, overlay GRPCConfigOverlay) GRPCConfig {
 	if overlay.Address != nil {
 		base.Address = *overlay.Address
 	}
+	if overlay.Socket != nil {
+		base.Socket = mergeSocketConfig(base.Socket, *overlay.Socket)
+	}
+	if overlay.TLS != nil {
+		base.TLS = mergeTLSConfig(base.TLS, *overlay.TLS)
+	}
 
 	return base
 }
@@ -113,6 +235,46 @@ func mergeRESTConfig(base RESTConfig, overlay RESTConfigOverlay) RESTConfig {
 	if overlay.Address != nil {
 		base.Address = *overlay.Address
 	}
+	if overlay.Socket != nil {
+		base.Socket = mergeSocketConfig(base.Socket, *overlay.Socket)
+	}
+	if overlay.TLS != nil {
+		base.TLS = mergeTLSConfig(base.TLS, *overlay.TLS)
+	}
+
+	return base
+}
+
+func mergeSocketConfig(base SocketConfig, overlay SocketConfigOverlay) SocketConfig {
+	if overlay.Group != nil {
+		base.Group = *overlay.Group
+	}
+	if overlay.Mode != nil {
+		base.Mode = *overlay.Mode
+	}
+	if overlay.User != nil {
+		base.User = *overlay.User
+	}
+
+	return base
+}
+
+func mergeTLSConfig(base TLSConfig, overlay TLSConfigOverlay) TLSConfig {
+	if overlay.CertFile != nil {
+		base.CertFile = *overlay.CertFile
+	}
+	if overlay.ClientCA != nil {
+		base.ClientCA = *overlay.ClientCA
+	}
+	if overlay.KeyFile != nil {
+		base.KeyFile = *overlay.KeyFile
+	}
+	if overlay.MinVersion != nil {
+		base.MinVersion = *overlay.MinVersion
+	}
+	if overlay.SelfSigned != nil {
+		base.SelfSigned = *overlay.SelfSigned
+	}
 
 	return base
 }
@@ -121,6 +283,9 @@ func mergeWebsocketConfig(base WebsocketConfig, overlay WebsocketConfigOverlay)
 	if overlay.Address != nil {
 		base.Address = *overlay.Address
 	}
+	if overlay.TLS != nil {
+		base.TLS = mergeTLSConfig(base.TLS, *overlay.TLS)
+	}
 
 	return base
 }
@@ -344,9 +509,24 @@ func mergeClientServerConfig(base ClientServerConfig, overlay ClientServerConfig
 	if overlay.Address != nil {
 		base.Address = *overlay.Address
 	}
+	if overlay.CAFile != nil {
+		base.CAFile = *overlay.CAFile
+	}
+	if overlay.CertFile != nil {
+		base.CertFile = *overlay.CertFile
+	}
+	if overlay.Fingerprint != nil {
+		base.Fingerprint = *overlay.Fingerprint
+	}
+	if overlay.KeyFile != nil {
+		base.KeyFile = *overlay.KeyFile
+	}
 	if overlay.REST != nil {
 		base.REST = *overlay.REST
 	}
+	if overlay.ServerName != nil {
+		base.ServerName = *overlay.ServerName
+	}
 
 	return base
 }
@@ -14,7 +14,10 @@ package config
 // Config overlay root. {{{
 
 type ConfigOverlay struct {
+	Admin        *AdminConfigOverlay        `toml:"admin"`        // Admin endpoint overrides.
+	Audit        *AuditConfigOverlay        `toml:"audit"`        // Audit log overrides.
 	Auth         *AuthConfigOverlay         `toml:"auth"`         // Authentication overrides.
+	Backup       *BackupConfigOverlay       `toml:"backup"`       // Backup overrides.
 	Database     *DatabaseConfigOverlay     `toml:"database"`     // Database overrides.
 	GRPC         *GRPCConfigOverlay         `toml:"grpc"`         // gRPC listener overrides.
 	Integrations *IntegrationsConfigOverlay `toml:"integrations"` // Integration overrides.
@@ -32,31 +35,85 @@ type ConfigOverlay struct {
 // Server overlay structs. {{{
 
 type ServerConfigOverlay struct {
-	Environment *Environment `toml:"environment"` // Runtime mode override.
-	ID          *string      `toml:"id"`          // Instance identifier override.
+	DrainDelay      *string      `toml:"drain_delay"`      // Drain delay override.
+	Environment     *Environment `toml:"environment"`      // Runtime mode override.
+	ID              *string      `toml:"id"`               // Instance identifier override.
+	ShutdownTimeout *string      `toml:"shutdown_timeout"` // Shutdown timeout override.
+}
+
+type AdminConfigOverlay struct {
+	Address     *string           `toml:"address"`      // Admin listener address override.
+	Debug       *bool             `toml:"debug"`        // Debug endpoints toggle override.
+	Expvar      *bool             `toml:"expvar"`       // Expvar toggle override.
+	Pprof       *bool             `toml:"pprof"`        // Pprof toggle override.
+	RequireAuth *bool             `toml:"require_auth"` // Admin listener auth requirement override.
+	TLS         *TLSConfigOverlay `toml:"tls"`          // Admin listener TLS overrides.
+	Token       *string           `toml:"token"`        // Admin bearer token override.
+}
+
+type AuditConfigOverlay struct {
+	Path *string `toml:"path"` // Audit file path override.
+}
+
+type BackupConfigOverlay struct {
+	Directory *string `toml:"directory"` // Snapshot directory override.
+	Interval  *string `toml:"interval"`  // Scheduled snapshot interval override.
+	Retention *int    `toml:"retention"` // Retained snapshot count override.
 }
 
 type DatabaseConfigOverlay struct {
-	DSN        *string         `toml:"dsn"`        // Connection string override.
-	Driver     *DatabaseDriver `toml:"driver"`     // Database engine override.
-	Migrations *string         `toml:"migrations"` // Migrations directory override.
+	AutoMigrate     *bool           `toml:"auto_migrate"`       // Startup migration override.
+	BusyTimeout     *string         `toml:"busy_timeout"`       // SQLite busy timeout override.
+	ConnMaxIdleTime *string         `toml:"conn_max_idle_time"` // Connection idle time override.
+	ConnMaxLifetime *string         `toml:"conn_max_lifetime"`  // Connection lifetime override.
+	ConnectTimeout  *string         `toml:"connect_timeout"`    // Connect timeout override.
+	DSN             *string         `toml:"dsn"`                // Connection string override.
+	Driver          *DatabaseDriver `toml:"driver"`             // Database engine override.
+	MaxIdleConns    *int            `toml:"max_idle_conns"`     // Idle connection limit override.
+	MaxOpenConns    *int            `toml:"max_open_conns"`     // Open connection limit override.
+	Migrations      *string         `toml:"migrations"`         // Migrations directory override.
 }
 
 type LoggingConfigOverlay struct {
-	Format *LogFormat `toml:"format"` // Log format override.
-	Level  *LogLevel  `toml:"level"`  // Minimum log level override.
+	BufferSize  *int                          `toml:"buffer_size"`  // Recent-log buffer size override.
+	Components  map[string]LogLevel           `toml:"components"`   // Per-component level overrides (merged by key).
+	DedupWindow *string                       `toml:"dedup_window"` // Dedup window override.
+	Format      *LogFormat                    `toml:"format"`       // Log format override.
+	Level       *LogLevel                     `toml:"level"`        // Minimum log level override.
+	Output      *[]LogOutputConfig            `toml:"output"`       // Log outputs override (replaces the list).
+	RateLimits  map[string]LogRateLimitConfig `toml:"rate_limits"`  // Per-component rate limit overrides (merged by key).
+	RedactKeys  *[]string                     `toml:"redact_keys"`  // Extra redaction keys override (replaces the list).
 }
 
 type GRPCConfigOverlay struct {
-	Address *string `toml:"address"` // gRPC bind address override.
+	Address *string              `toml:"address"` // gRPC bind address override.
+	Socket  *SocketConfigOverlay `toml:"socket"`  // gRPC unix socket overrides.
+	TLS     *TLSConfigOverlay    `toml:"tls"`     // gRPC listener TLS overrides.
 }
 
 type RESTConfigOverlay struct {
-	Address *string `toml:"address"` // REST bind address override.
+	Address *string              `toml:"address"` // REST bind address override.
+	Socket  *SocketConfigOverlay `toml:"socket"`  // REST unix socket overrides.
+	TLS     *TLSConfigOverlay    `toml:"tls"`     // REST listener TLS overrides.
 }
 
 type WebsocketConfigOverlay struct {
-	Address *string `toml:"address"` // WebSocket bind address override.
+	Address *string           `toml:"address"` // WebSocket bind address override.
+	TLS     *TLSConfigOverlay `toml:"tls"`     // WebSocket listener TLS overrides.
+}
+
+type SocketConfigOverlay struct {
+	Group *string `toml:"group"` // Socket group override.
+	Mode  *string `toml:"mode"`  // Socket mode override.
+	User  *string `toml:"user"`  // Socket owner override.
+}
+
+type TLSConfigOverlay struct {
+	CertFile   *string     `toml:"cert_file"`   // Certificate chain override.
+	ClientCA   *string     `toml:"client_ca"`   // Client CA bundle override.
+	KeyFile    *string     `toml:"key_file"`    // Private key override.
+	MinVersion *TLSVersion `toml:"min_version"` // Minimum TLS version override.
+	SelfSigned *bool       `toml:"self_signed"` // Self-signed certificate override.
 }
 
 type IntegrationsConfigOverlay struct {
@@ -155,8 +212,13 @@ type ClientConfigOverlay struct {
 }
 
 type ClientServerConfigOverlay struct {
-	Address *string `toml:"address"` // gRPC endpoint override.
-	REST    *string `toml:"rest"`    // REST endpoint override.
+	Address     *string `toml:"address"`     // gRPC endpoint override.
+	CAFile      *string `toml:"ca_file"`     // Trusted CA bundle override.
+	CertFile    *string `toml:"cert_file"`   // Client certificate override.
+	Fingerprint *string `toml:"fingerprint"` // Pinned server fingerprint override.
+	KeyFile     *string `toml:"key_file"`    // Client private key override.
+	REST        *string `toml:"rest"`        // REST endpoint override.
+	ServerName  *string `toml:"server_name"` // Server name override.
 }
 
 type ClientAuthConfigOverlay struct {
@@ -16,6 +16,7 @@ const redactedValue = "[redacted]"
 // RedactConfig returns a sanitized copy of config with sensitive fields removed.
 func RedactConfig(config Config) Config {
 	redacted := config
+	redacted.Admin.Token = redactValue(redacted.Admin.Token)
 	redacted.Database.DSN = redactValue(redacted.Database.DSN)
 	redacted.Auth.Remote.Endpoint = redactValue(redacted.Auth.Remote.Endpoint)
 	redacted.Client.Auth.Token = redactValue(redacted.Client.Auth.Token)
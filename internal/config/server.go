@@ -31,17 +31,26 @@ const (
 // ServerConfig holds top-level server settings. {{{
 
 type ServerConfig struct {
-	Environment Environment `toml:"environment"` // Runtime mode (`local` or `remote`).
-	ID          string      `toml:"id"`          // Instance identifier (ULID string).
+	DrainDelay      string      `toml:"drain_delay"`      // Time between dropping readiness and stopping listeners.
+	Environment     Environment `toml:"environment"`      // Runtime mode (`local` or `remote`).
+	ID              string      `toml:"id"`               // Instance identifier (ULID string).
+	ShutdownTimeout string      `toml:"shutdown_timeout"` // Upper bound for stopping all components.
 }
 
 // }}}
 // DatabaseConfig configures database connectivity and migrations. {{{
 
 type DatabaseConfig struct {
-	DSN        string         `toml:"dsn"`        // Connection string for the selected driver.
-	Driver     DatabaseDriver `toml:"driver"`     // Database engine (`sqlite` or `postgres`).
-	Migrations string         `toml:"migrations"` // Migrations directory.
+	AutoMigrate     bool           `toml:"auto_migrate"`       // Apply pending migrations at startup before reporting ready.
+	BusyTimeout     string         `toml:"busy_timeout"`       // SQLite lock wait before SQLITE_BUSY (duration string).
+	ConnMaxIdleTime string         `toml:"conn_max_idle_time"` // Idle time before a pooled connection closes (duration string).
+	ConnMaxLifetime string         `toml:"conn_max_lifetime"`  // Maximum pooled connection age (duration string).
+	ConnectTimeout  string         `toml:"connect_timeout"`    // Time allowed to open and ping the database (duration string).
+	DSN             string         `toml:"dsn"`                // Connection string for the selected driver.
+	Driver          DatabaseDriver `toml:"driver"`             // Database engine (`sqlite` or `postgres`).
+	MaxIdleConns    int            `toml:"max_idle_conns"`     // Idle connections kept in the pool.
+	MaxOpenConns    int            `toml:"max_open_conns"`     // Open connection limit.
+	Migrations      string         `toml:"migrations"`         // Migrations tree replacing the embedded one (shared/, sqlite/, postgres/).
 }
 
 // }}}
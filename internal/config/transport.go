@@ -3,29 +3,80 @@
 
 // Transport listener configuration.
 // This file defines gRPC, REST, and WebSocket listener configs for the server.
-// The types map to transport sections and provide bind addresses for each
-// listener.
+// The types map to transport sections and provide bind addresses and TLS
+// settings for each listener. gRPC and REST also accept unix:///path
+// addresses, with [*.socket] controlling the socket file's mode and owner.
+// TLS is on when a certificate is configured or self_signed is set;
+// client_ca additionally requires client certificates (mutual TLS).
 
 package config
 
+import "strings"
+
 // GRPCConfig configures the gRPC server listener. {{{
 
 type GRPCConfig struct {
-	Address string `toml:"address"` // gRPC bind address.
+	Address string       `toml:"address"` // gRPC bind address (host:port or unix:///path).
+	Socket  SocketConfig `toml:"socket"`  // Unix socket file settings.
+	TLS     TLSConfig    `toml:"tls"`     // gRPC listener TLS.
 }
 
 // }}}
 // RESTConfig configures the REST gateway listener. {{{
 
 type RESTConfig struct {
-	Address string `toml:"address"` // REST bind address.
+	Address string       `toml:"address"` // REST bind address (host:port or unix:///path).
+	Socket  SocketConfig `toml:"socket"`  // Unix socket file settings.
+	TLS     TLSConfig    `toml:"tls"`     // REST listener TLS.
 }
 
 // }}}
 // WebsocketConfig configures the WebSocket listener. {{{
 
 type WebsocketConfig struct {
-	Address string `toml:"address"` // WebSocket bind address.
+	Address string    `toml:"address"` // WebSocket bind address.
+	TLS     TLSConfig `toml:"tls"`     // WebSocket listener TLS.
+}
+
+// }}}
+// SocketConfig configures a unix socket listener. {{{
+
+type SocketConfig struct {
+	Group string `toml:"group"` // Group owning the socket file (name or gid).
+	Mode  string `toml:"mode"`  // Socket file mode in octal (default 0660).
+	User  string `toml:"user"`  // User owning the socket file (name or uid).
+}
+
+// UnixSocketPrefix marks unix socket addresses.
+const UnixSocketPrefix = "unix://"
+
+// UnixSocketPath returns the socket path of a unix:///path address.
+func UnixSocketPath(address string) (string, bool) {
+	path, ok := strings.CutPrefix(address, UnixSocketPrefix)
+	return path, ok
+}
+
+// }}}
+// TLSConfig configures TLS for a listener. {{{
+
+type TLSVersion string
+
+const (
+	TLSVersion12 TLSVersion = "1.2"
+	TLSVersion13 TLSVersion = "1.3"
+)
+
+type TLSConfig struct {
+	CertFile   string     `toml:"cert_file"`   // PEM certificate chain (reloaded on change).
+	ClientCA   string     `toml:"client_ca"`   // PEM CA bundle; requires client certificates (mTLS).
+	KeyFile    string     `toml:"key_file"`    // PEM private key (reloaded on change).
+	MinVersion TLSVersion `toml:"min_version"` // Minimum TLS version (`1.2` or `1.3`, default 1.2).
+	SelfSigned bool       `toml:"self_signed"` // Use a self-signed certificate kept in tls/ next to the config (local only).
+}
+
+// Enabled reports whether the listener serves TLS.
+func (tls TLSConfig) Enabled() bool {
+	return tls.CertFile != "" || tls.SelfSigned
 }
 
 // }}}
@@ -9,7 +9,17 @@
 
 package config
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Config validation. {{{
 // This block defines ValidateConfig and section-specific validators that
@@ -20,9 +30,20 @@ func ValidateConfig(config Config) error {
 	var errs ValidationErrors
 
 	validateDatabaseConfig(config.Database, &errs)
+	validateAdminConfig(config.Admin, &errs)
+	validateBackupConfig(config.Backup, &errs)
+	validateTLSConfig("admin.tls", config.Admin.TLS, config.Server, &errs)
+	validateTLSConfig("grpc.tls", config.GRPC.TLS, config.Server, &errs)
+	validateTLSConfig("rest.tls", config.REST.TLS, config.Server, &errs)
+	validateTLSConfig("websocket.tls", config.Websocket.TLS, config.Server, &errs)
+	validateClientServerConfig(config.Client.Server, &errs)
+	validateListenAddress("grpc", config.GRPC.Address, config.GRPC.Socket, &errs)
+	validateListenAddress("rest", config.REST.Address, config.REST.Socket, &errs)
+	validateServerConfig(config.Server, &errs)
 	validateAuthConfig(config.Auth, config.Server, &errs)
 	validateSyncConfig(config.Sync, &errs)
 	validateAuthDurations(config.Auth, &errs)
+	validateLoggingConfig(config.Logging, &errs)
 
 	if len(errs) > 0 {
 		return errs
@@ -39,6 +60,130 @@ func validateDatabaseConfig(database DatabaseConfig, errs *ValidationErrors) {
 	if database.DSN == "" {
 		appendFieldError(errs, "database.dsn", "is required when database.driver is set")
 	}
+	for _, field := range []struct{ path, value string }{
+		{"database.busy_timeout", database.BusyTimeout},
+		{"database.conn_max_idle_time", database.ConnMaxIdleTime},
+		{"database.conn_max_lifetime", database.ConnMaxLifetime},
+		{"database.connect_timeout", database.ConnectTimeout},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(field.value); err != nil || duration < 0 {
+			appendFieldError(errs, field.path, "must be a valid duration")
+		}
+	}
+	if database.MaxOpenConns < 0 {
+		appendFieldError(errs, "database.max_open_conns", "must not be negative")
+	}
+	if database.MaxIdleConns < 0 {
+		appendFieldError(errs, "database.max_idle_conns", "must not be negative")
+	}
+}
+
+func validateServerConfig(server ServerConfig, errs *ValidationErrors) {
+	if server.DrainDelay != "" {
+		if delay, err := time.ParseDuration(server.DrainDelay); err != nil || delay < 0 {
+			appendFieldError(errs, "server.drain_delay", "must be a valid duration")
+		}
+	}
+	if server.ShutdownTimeout != "" {
+		if timeout, err := time.ParseDuration(server.ShutdownTimeout); err != nil || timeout <= 0 {
+			appendFieldError(errs, "server.shutdown_timeout", "must be a positive duration")
+		}
+	}
+}
+
+func validateAdminConfig(admin AdminConfig, errs *ValidationErrors) {
+	if !admin.ListenerEnabled() {
+		return
+	}
+	if _, _, err := net.SplitHostPort(admin.Address); err != nil {
+		appendFieldError(errs, "admin.address", "must be host:port")
+		return
+	}
+	if admin.RequireAuth && admin.Token == "" {
+		appendFieldError(errs, "admin.token", "is required when admin.require_auth is true and a debug endpoint is enabled")
+	}
+	if !admin.RequireAuth && !IsLoopbackAddress(admin.Address) {
+		appendFieldError(errs, "admin.require_auth", "must be true when admin.address is not a loopback address")
+	}
+}
+
+func validateBackupConfig(backup BackupConfig, errs *ValidationErrors) {
+	if backup.Interval != "" {
+		if interval, err := time.ParseDuration(backup.Interval); err != nil || interval <= 0 {
+			appendFieldError(errs, "backup.interval", "must be a positive duration")
+		} else if backup.Directory == "" {
+			appendFieldError(errs, "backup.directory", "is required when backup.interval is set")
+		}
+	}
+	if backup.Retention < 0 {
+		appendFieldError(errs, "backup.retention", "must not be negative")
+	}
+}
+
+// IsLoopbackAddress reports whether a host:port address binds only to
+// loopback. An empty host binds every interface and is not loopback.
+func IsLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func validateTLSConfig(prefix string, tls TLSConfig, server ServerConfig, errs *ValidationErrors) {
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		appendFieldError(errs, prefix+".key_file", "cert_file and key_file must be set together")
+	}
+	if tls.SelfSigned {
+		if tls.CertFile != "" {
+			appendFieldError(errs, prefix+".self_signed", "cannot be combined with cert_file")
+		}
+		if server.Environment != EnvLocal {
+			appendFieldError(errs, prefix+".self_signed", "requires server.environment=local")
+		}
+	}
+	if tls.ClientCA != "" && !tls.Enabled() {
+		appendFieldError(errs, prefix+".client_ca", "requires cert_file or self_signed")
+	}
+	if tls.MinVersion != "" && tls.MinVersion != TLSVersion12 && tls.MinVersion != TLSVersion13 {
+		appendFieldError(errs, prefix+".min_version", "must be 1.2 or 1.3")
+	}
+}
+
+func validateListenAddress(prefix string, address string, socket SocketConfig, errs *ValidationErrors) {
+	path, unix := UnixSocketPath(address)
+	if unix && !filepath.IsAbs(path) {
+		appendFieldError(errs, prefix+".address", "unix socket path must be absolute")
+	}
+	if socket.Mode != "" {
+		if mode, err := strconv.ParseUint(socket.Mode, 8, 32); err != nil || mode > 0o777 {
+			appendFieldError(errs, prefix+".socket.mode", "must be an octal file mode such as 0660")
+		}
+	}
+}
+
+func validateClientServerConfig(server ClientServerConfig, errs *ValidationErrors) {
+	for _, field := range []struct{ path, address string }{{"client.server.address", server.Address}, {"client.server.rest", server.REST}} {
+		if path, unix := UnixSocketPath(field.address); unix && !filepath.IsAbs(path) {
+			appendFieldError(errs, field.path, "unix socket path must be absolute")
+		}
+	}
+	if (server.CertFile == "") != (server.KeyFile == "") {
+		appendFieldError(errs, "client.server.key_file", "cert_file and key_file must be set together")
+	}
+	if server.Fingerprint != "" {
+		digest := strings.ReplaceAll(server.Fingerprint, ":", "")
+		if _, err := hex.DecodeString(digest); err != nil || len(digest) != sha256.Size*2 {
+			appendFieldError(errs, "client.server.fingerprint", "must be a hex SHA-256 fingerprint")
+		}
+	}
 }
 
 func validateAuthConfig(auth AuthConfig, server ServerConfig, errs *ValidationErrors) {
@@ -78,6 +223,60 @@ func validateAuthDurations(auth AuthConfig, errs *ValidationErrors) {
 	}
 }
 
+func validateLoggingConfig(logging LoggingConfig, errs *ValidationErrors) {
+	if logging.BufferSize < 0 {
+		appendFieldError(errs, "logging.buffer_size", "must not be negative")
+	}
+	if logging.DedupWindow != "" {
+		if window, err := time.ParseDuration(logging.DedupWindow); err != nil || window < 0 {
+			appendFieldError(errs, "logging.dedup_window", "must be a valid duration")
+		}
+	}
+	components := make([]string, 0, len(logging.RateLimits))
+	for component := range logging.RateLimits {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	for _, component := range components {
+		limit := logging.RateLimits[component]
+		prefix := fmt.Sprintf("logging.rate_limits.%s", component)
+		if limit.Rate <= 0 {
+			appendFieldError(errs, prefix+".rate", "must be positive")
+		}
+		if limit.Burst < 0 {
+			appendFieldError(errs, prefix+".burst", "must not be negative")
+		}
+	}
+
+	targets := make(map[string]bool, len(logging.Output))
+	for index, output := range logging.Output {
+		prefix := fmt.Sprintf("logging.output[%d]", index)
+		if output.Target == "" {
+			appendFieldError(errs, prefix+".target", "is required")
+			continue
+		}
+		if targets[output.Target] {
+			appendFieldError(errs, prefix+".target", "duplicates another logging output")
+		}
+		targets[output.Target] = true
+
+		if output.MaxSizeMB < 0 {
+			appendFieldError(errs, prefix+".max_size_mb", "must not be negative")
+		}
+		if output.MaxBackups < 0 {
+			appendFieldError(errs, prefix+".max_backups", "must not be negative")
+		}
+		if output.MaxAge != "" {
+			if age, err := time.ParseDuration(output.MaxAge); err != nil || age < 0 {
+				appendFieldError(errs, prefix+".max_age", "must be a valid duration")
+			}
+		}
+		if !output.IsFile() && (output.MaxSizeMB != 0 || output.MaxAge != "" || output.MaxBackups != 0 || output.Compress) {
+			appendFieldError(errs, prefix+".target", "rotation settings require a file target")
+		}
+	}
+}
+
 func appendFieldError(errs *ValidationErrors, path string, message string) {
 	*errs = append(*errs, FieldError{Path: path, Message: message})
 }
@@ -13,8 +13,8 @@ import "strings"
 // Config warnings. {{{
 
 type FieldWarning struct {
-	Message string `toml:"-"`	// Warning message.
-	Path    string `toml:"-"`	// Config field path.
+	Message string `toml:"-" json:"message"`	// Warning message.
+	Path    string `toml:"-" json:"path"`	// Config field path.
 }
 
 func (warn FieldWarning) String() string {
@@ -55,6 +55,41 @@ func CollectConfigWarnings(config Config) WarningList {
 		})
 	}
 
+	if config.Admin.ListenerEnabled() && !IsLoopbackAddress(config.Admin.Address) {
+		warnings = append(warnings, FieldWarning{
+			Path:    "admin.address",
+			Message: "exposes debug endpoints beyond loopback; prefer 127.0.0.1",
+		})
+	}
+
+	if config.Backup.Interval != "" && config.Backup.Retention == 0 {
+		warnings = append(warnings, FieldWarning{
+			Path:    "backup.retention",
+			Message: "keeps every scheduled snapshot; the backup directory grows without bound",
+		})
+	}
+
+	listeners := []struct {
+		path    string
+		address string
+		tls     TLSConfig
+	}{
+		{"grpc", config.GRPC.Address, config.GRPC.TLS},
+		{"rest", config.REST.Address, config.REST.TLS},
+		{"websocket", config.Websocket.Address, config.Websocket.TLS},
+	}
+	for _, listener := range listeners {
+		if _, unix := UnixSocketPath(listener.address); unix {
+			continue
+		}
+		if config.Server.Environment == EnvRemote && listener.address != "" && !listener.tls.Enabled() && !IsLoopbackAddress(listener.address) {
+			warnings = append(warnings, FieldWarning{
+				Path:    listener.path + ".tls",
+				Message: "is off in the remote environment; tokens are sent in cleartext",
+			})
+		}
+	}
+
 	return warnings
 }
 
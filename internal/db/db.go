@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Database access.
+// This file defines DB, a connection pool opened from DatabaseConfig for the
+// pure-Go SQLite driver or PostgreSQL (pgx). SQLite connections run in WAL
+// mode with a busy timeout, foreign keys on, and immediate write
+// transactions, so concurrent writers queue instead of failing; DSN
+// parameters the operator sets take precedence. Open only prepares the pool;
+// Connect dials within database.connect_timeout and Check serves as the
+// readiness check.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the pgx driver.
+	_ "modernc.org/sqlite"             // Registers the sqlite driver.
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Connection pool. {{{
+
+const (
+	driverNamePostgres = "pgx"
+	driverNameSQLite   = "sqlite"
+)
+
+// DB is an open connection pool with the dialect of its driver.
+type DB struct {
+	*sql.DB
+	dialect        Dialect
+	connectTimeout time.Duration
+}
+
+// Open prepares a pool for settings without connecting. Durations are
+// expected to be validated already; unparsable ones fall back to zero.
+func Open(settings config.DatabaseConfig) (*DB, error) {
+	var driverName, dsn string
+	var dialect Dialect
+	switch settings.Driver {
+	case config.DriverSQLite:
+		driverName, dialect = driverNameSQLite, SQLite
+		dsn = sqliteDSN(settings.DSN, parseDuration(settings.BusyTimeout))
+	case config.DriverPostgres:
+		driverName, dialect = driverNamePostgres, Postgres
+		dsn = settings.DSN
+	default:
+		return nil, fmt.Errorf("%s: %q", errtext.ErrDatabaseDriver, settings.Driver)
+	}
+
+	pool, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errtext.ErrDatabaseOpen, err)
+	}
+	maxOpen := settings.MaxOpenConns
+	if settings.Driver == config.DriverSQLite && sqliteMemory(settings.DSN) {
+		// Every connection to :memory: is a separate database.
+		maxOpen = 1
+	}
+	pool.SetMaxOpenConns(maxOpen)
+	pool.SetMaxIdleConns(settings.MaxIdleConns)
+	pool.SetConnMaxLifetime(parseDuration(settings.ConnMaxLifetime))
+	pool.SetConnMaxIdleTime(parseDuration(settings.ConnMaxIdleTime))
+
+	return &DB{DB: pool, dialect: dialect, connectTimeout: parseDuration(settings.ConnectTimeout)}, nil
+}
+
+// Dialect returns the SQL dialect of the pool's driver.
+func (db *DB) Dialect() Dialect {
+	return db.dialect
+}
+
+// Connect opens the first connection within the connect timeout, so a bad
+// DSN or an unreachable server fails startup instead of the first query.
+func (db *DB) Connect(ctx context.Context) error {
+	if db.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, db.connectTimeout)
+		defer cancel()
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", errtext.ErrDatabaseConnect, err)
+	}
+	return nil
+}
+
+// Check pings the database; it implements health.Checker.
+func (db *DB) Check(ctx context.Context) error {
+	return db.PingContext(ctx)
+}
+
+// }}}
+// SQLite DSN. {{{
+
+// sqliteDSN adds the WAL, busy timeout, foreign key, and transaction lock
+// parameters to dsn unless it already sets them.
+func sqliteDSN(dsn string, busyTimeout time.Duration) string {
+	_, query, _ := strings.Cut(dsn, "?")
+	values, _ := url.ParseQuery(query)
+	defaults := []struct{ key, alias, value string }{
+		{"_busy_timeout", "_timeout", strconv.FormatInt(busyTimeout.Milliseconds(), 10)},
+		{"_foreign_keys", "_fk", "1"},
+		{"_journal_mode", "_journal", "WAL"},
+		{"_txlock", "", "immediate"},
+	}
+
+	var added []string
+	for _, parameter := range defaults {
+		if values.Has(parameter.key) || (parameter.alias != "" && values.Has(parameter.alias)) {
+			continue
+		}
+		if parameter.key == "_journal_mode" && sqliteMemory(dsn) {
+			continue
+		}
+		added = append(added, parameter.key+"="+parameter.value)
+	}
+	if len(added) == 0 {
+		return dsn
+	}
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + strings.Join(added, "&")
+}
+
+// sqliteMemory reports whether dsn names an in-memory database.
+func sqliteMemory(dsn string) bool {
+	path, query, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+	return path == "" || path == ":memory:" || strings.Contains(query, "mode=memory")
+}
+
+func parseDuration(value string) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Database pool tests.
+// This file verifies SQLite DSN defaults, pool setup, connecting, and the
+// health check. The PostgreSQL test runs only when BMS_TEST_POSTGRES_DSN
+// names a server.
+
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Pool tests. {{{
+
+// openSQLite opens and connects a file database in a temp dir.
+func openSQLite(t *testing.T, busyTimeout string) *DB {
+	t.Helper()
+	settings := config.DefaultConfig().Database
+	settings.Driver = config.DriverSQLite
+	settings.DSN = "file:" + filepath.Join(t.TempDir(), "bms.db")
+	settings.BusyTimeout = busyTimeout
+	database, err := Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = database.Close()
+	})
+	if err := database.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	return database
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	cases := map[string]string{
+		"file:bms.db":                   "file:bms.db?_busy_timeout=5000&_foreign_keys=1&_journal_mode=WAL&_txlock=immediate",
+		"bms.db?_journal=DELETE&_fk=0":  "bms.db?_journal=DELETE&_fk=0&_busy_timeout=5000&_txlock=immediate",
+		":memory:":                      ":memory:?_busy_timeout=5000&_foreign_keys=1&_txlock=immediate",
+		"file:x?mode=memory&_timeout=1": "file:x?mode=memory&_timeout=1&_foreign_keys=1&_txlock=immediate",
+	}
+	for dsn, want := range cases {
+		if got := sqliteDSN(dsn, parseDuration("5s")); got != want {
+			t.Fatalf("%s: expected %s, got %s", dsn, want, got)
+		}
+	}
+}
+
+func TestOpenSQLite(t *testing.T) {
+	database := openSQLite(t, "250ms")
+	if database.Dialect() != SQLite {
+		t.Fatalf("expected the sqlite dialect, got %v", database.Dialect().Driver())
+	}
+
+	var mode string
+	var timeout, foreignKeys int
+	row := database.QueryRow("SELECT journal_mode, timeout, foreign_keys FROM pragma_journal_mode, pragma_busy_timeout, pragma_foreign_keys")
+	if err := row.Scan(&mode, &timeout, &foreignKeys); err != nil {
+		t.Fatalf("pragmas: %v", err)
+	}
+	if mode != "wal" || timeout != 250 || foreignKeys != 1 {
+		t.Fatalf("unexpected pragmas journal_mode=%s busy_timeout=%d foreign_keys=%d", mode, timeout, foreignKeys)
+	}
+	if err := database.Check(context.Background()); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if database.Stats().MaxOpenConnections != 10 {
+		t.Fatalf("expected the default pool size, got %d", database.Stats().MaxOpenConnections)
+	}
+
+	_ = database.Close()
+	if err := database.Check(context.Background()); err == nil {
+		t.Fatal("expected the check to fail on a closed pool")
+	}
+}
+
+func TestOpenSQLiteMemory(t *testing.T) {
+	database, err := Open(config.DatabaseConfig{Driver: config.DriverSQLite, DSN: ":memory:", MaxOpenConns: 8})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer database.Close()
+	if database.Stats().MaxOpenConnections != 1 {
+		t.Fatalf("expected a single connection for :memory:, got %d", database.Stats().MaxOpenConnections)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	if _, err := Open(config.DatabaseConfig{Driver: "mysql", DSN: "x"}); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Fatalf("expected an unsupported driver error, got %v", err)
+	}
+
+	settings := config.DatabaseConfig{Driver: config.DriverSQLite, DSN: filepath.Join(t.TempDir(), "missing", "bms.db")}
+	database, err := Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer database.Close()
+	if err := database.Connect(context.Background()); err == nil || !strings.Contains(err.Error(), "connect") {
+		t.Fatalf("expected a connect error, got %v", err)
+	}
+}
+
+func TestOpenPostgres(t *testing.T) {
+	dsn := os.Getenv("BMS_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("BMS_TEST_POSTGRES_DSN is not set")
+	}
+	settings := config.DefaultConfig().Database
+	settings.Driver = config.DriverPostgres
+	settings.DSN = dsn
+	database, err := Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer database.Close()
+	if err := database.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if database.Dialect() != Postgres {
+		t.Fatalf("expected the postgres dialect, got %v", database.Dialect().Driver())
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
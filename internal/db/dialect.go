@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// SQL dialects.
+// This file defines Dialect, which hides the SQL differences between SQLite
+// and PostgreSQL that queries run into: bind placeholders (? against $n),
+// upsert statements, and which errors mean a transaction should be retried.
+// Queries are written with ? placeholders and passed through Rebind.
+
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Dialects. {{{
+
+// Dialect describes the SQL differences between supported databases.
+type Dialect interface {
+	// Driver returns the config driver the dialect belongs to.
+	Driver() config.DatabaseDriver
+	// Placeholder returns the bind placeholder for the 1-based argument n.
+	Placeholder(n int) string
+	// Rebind rewrites ? placeholders outside quotes into the dialect's form.
+	Rebind(query string) string
+	// Upsert returns an insert of columns into table that updates the update
+	// columns when a row with the same conflict columns exists, or does
+	// nothing when update is empty.
+	Upsert(table string, columns []string, conflict []string, update []string) string
+	// Retryable reports whether err is a lock or serialization failure
+	// after which the whole transaction can be run again.
+	Retryable(err error) bool
+}
+
+var (
+	// SQLite is the dialect of the sqlite driver.
+	SQLite Dialect = sqliteDialect{}
+	// Postgres is the dialect of the postgres driver.
+	Postgres Dialect = postgresDialect{}
+)
+
+// Postgres SQLSTATE codes worth retrying.
+const (
+	sqlStateDeadlock      = "40P01"
+	sqlStateSerialization = "40001"
+)
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Driver() config.DatabaseDriver { return config.DriverSQLite }
+
+func (sqliteDialect) Placeholder(int) string { return "?" }
+
+func (sqliteDialect) Rebind(query string) string { return query }
+
+func (dialect sqliteDialect) Upsert(table string, columns []string, conflict []string, update []string) string {
+	return upsert(dialect, table, columns, conflict, update)
+}
+
+// Retryable matches SQLITE_BUSY and SQLITE_LOCKED, including their extended
+// codes.
+func (sqliteDialect) Retryable(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Driver() config.DatabaseDriver { return config.DriverPostgres }
+
+func (postgresDialect) Placeholder(n int) string { return "$" + strconv.Itoa(n) }
+
+func (dialect postgresDialect) Rebind(query string) string {
+	var builder strings.Builder
+	builder.Grow(len(query) + 8)
+	n := 0
+	var quote rune
+	for _, char := range query {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case char == '?':
+			n++
+			builder.WriteString(dialect.Placeholder(n))
+			continue
+		}
+		builder.WriteRune(char)
+	}
+	return builder.String()
+}
+
+func (dialect postgresDialect) Upsert(table string, columns []string, conflict []string, update []string) string {
+	return upsert(dialect, table, columns, conflict, update)
+}
+
+// Retryable matches serialization failures and deadlocks.
+func (postgresDialect) Retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == sqlStateSerialization || pgErr.Code == sqlStateDeadlock
+}
+
+// upsert builds INSERT ... ON CONFLICT, which both databases accept; only
+// the placeholders differ.
+func upsert(dialect Dialect, table string, columns []string, conflict []string, update []string) string {
+	placeholders := make([]string, len(columns))
+	for index := range columns {
+		placeholders[index] = dialect.Placeholder(index + 1)
+	}
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s)",
+		table, strings.Join(columns, ", "), strings.Join(placeholders, ", "), strings.Join(conflict, ", "))
+	if len(update) == 0 {
+		return statement + " DO NOTHING"
+	}
+	assignments := make([]string, len(update))
+	for index, column := range update {
+		assignments[index] = column + " = excluded." + column
+	}
+	return statement + " DO UPDATE SET " + strings.Join(assignments, ", ")
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
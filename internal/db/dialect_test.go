@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Dialect tests.
+// This file verifies placeholder rebinding, upsert statements against a
+// real SQLite database, and retryable error classification.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Dialect tests. {{{
+
+func TestRebind(t *testing.T) {
+	query := `SELECT * FROM qso WHERE call = ? AND note <> 'why?' AND "odd?" = ? LIMIT ?`
+	want := `SELECT * FROM qso WHERE call = $1 AND note <> 'why?' AND "odd?" = $2 LIMIT $3`
+	if got := Postgres.Rebind(query); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if got := SQLite.Rebind(query); got != query {
+		t.Fatalf("expected sqlite to keep the query, got %s", got)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	want := "INSERT INTO station (id, call, grid) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET call = excluded.call, grid = excluded.grid"
+	if got := Postgres.Upsert("station", []string{"id", "call", "grid"}, []string{"id"}, []string{"call", "grid"}); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	database := openSQLite(t, "1s")
+	if _, err := database.Exec("CREATE TABLE station (id INTEGER PRIMARY KEY, call TEXT, grid TEXT)"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	update := SQLite.Upsert("station", []string{"id", "call", "grid"}, []string{"id"}, []string{"grid"})
+	keep := SQLite.Upsert("station", []string{"id", "call", "grid"}, []string{"id"}, nil)
+	for _, step := range []struct {
+		query string
+		args  []any
+	}{
+		{update, []any{1, "HA5BMS", "JN97"}},
+		{update, []any{1, "ignored", "JN96"}},
+		{keep, []any{1, "ignored", "JN95"}},
+	} {
+		if _, err := database.Exec(step.query, step.args...); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+
+	var call, grid string
+	if err := database.QueryRow("SELECT call, grid FROM station WHERE id = 1").Scan(&call, &grid); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if call != "HA5BMS" || grid != "JN96" {
+		t.Fatalf("unexpected row %s %s", call, grid)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	database := openSQLite(t, "0s")
+	if _, err := database.Exec("CREATE TABLE log (id INTEGER)"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	holder, err := database.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer holder.Rollback()
+
+	conn, err := database.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("conn: %v", err)
+	}
+	defer conn.Close()
+	_, busy := conn.ExecContext(context.Background(), "INSERT INTO log VALUES (1)")
+	if !SQLite.Retryable(busy) {
+		t.Fatalf("expected SQLITE_BUSY to be retryable, got %v", busy)
+	}
+	if SQLite.Retryable(errors.New("syntax error")) || SQLite.Retryable(nil) {
+		t.Fatal("expected other errors not to be retryable")
+	}
+
+	serialization := fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40001"})
+	if !Postgres.Retryable(serialization) || Postgres.Retryable(&pgconn.PgError{Code: "23505"}) {
+		t.Fatal("unexpected postgres retry classification")
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
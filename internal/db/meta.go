@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Server metadata.
+// This file reads and writes the server_meta key/value table that the first
+// migration creates. bmsd records its server.id there, so a database and its
+// backups carry the identity of the server that owns them.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Server metadata. {{{
+
+const (
+	// MetaServerID is the server_meta key holding the owner's server.id.
+	MetaServerID = "server.id"
+
+	metaTable      = "server_meta"
+	metaTimeLayout = "2006-01-02T15:04:05.000Z"
+)
+
+// Meta returns the value stored under key, or "" when it is not set.
+func (db *DB) Meta(ctx context.Context, key string) (string, error) {
+	var value string
+	err := db.QueryRowContext(ctx, db.dialect.Rebind("SELECT value FROM "+metaTable+" WHERE key = ?"), key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %s: %w", errtext.ErrServerMeta, key, err)
+	}
+	return value, nil
+}
+
+// SetMeta stores value under key, replacing an earlier value.
+func (db *DB) SetMeta(ctx context.Context, key string, value string) error {
+	statement := db.dialect.Upsert(metaTable, []string{"key", "value", "updated_at"}, []string{"key"}, []string{"value", "updated_at"})
+	if _, err := db.ExecContext(ctx, statement, key, value, time.Now().UTC().Format(metaTimeLayout)); err != nil {
+		return fmt.Errorf("%s: %s: %w", errtext.ErrServerMeta, key, err)
+	}
+	return nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Server metadata tests.
+// This file verifies that unset keys read as empty and that SetMeta inserts
+// and then replaces a value.
+
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+// Server metadata tests. {{{
+
+func TestMeta(t *testing.T) {
+	ctx := context.Background()
+	database := openSQLite(t, "")
+	if _, err := database.ExecContext(ctx, `CREATE TABLE server_meta (
+		key        TEXT NOT NULL PRIMARY KEY,
+		value      TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	if value, err := database.Meta(ctx, MetaServerID); err != nil || value != "" {
+		t.Fatalf("expected unset key, got %q, %v", value, err)
+	}
+	for _, want := range []string{"bms-1", "bms-2"} {
+		if err := database.SetMeta(ctx, MetaServerID, want); err != nil {
+			t.Fatalf("set: %v", err)
+		}
+		if value, err := database.Meta(ctx, MetaServerID); err != nil || value != want {
+			t.Fatalf("expected %q, got %q, %v", want, value, err)
+		}
+	}
+}
+
+func TestMetaMissingTable(t *testing.T) {
+	if _, err := openSQLite(t, "").Meta(context.Background(), MetaServerID); err == nil {
+		t.Fatal("expected error without server_meta")
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
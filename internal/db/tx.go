@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Transactions.
+// This file defines InTx, which runs a function in a transaction, commits
+// when it returns nil, and rolls back otherwise. Lock and serialization
+// failures (SQLITE_BUSY, Postgres 40001 and 40P01) rerun the whole function
+// with backoff, so the function must not have side effects outside the
+// transaction.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Transaction helper. {{{
+
+const (
+	// DefaultTxAttempts is how often InTx runs a function before giving up.
+	DefaultTxAttempts = 5
+
+	txBackoffBase = 10 * time.Millisecond
+	txBackoffMax  = 500 * time.Millisecond
+)
+
+// InTx runs fn in a transaction started with options (nil for the driver
+// default), retrying retryable failures up to DefaultTxAttempts times.
+func (db *DB) InTx(ctx context.Context, options *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	backoff := txBackoffBase
+	for attempt := 1; ; attempt++ {
+		err := db.runTx(ctx, options, fn)
+		if err == nil || !db.dialect.Retryable(err) {
+			return err
+		}
+		if attempt == DefaultTxAttempts {
+			return fmt.Errorf("%s after %d attempts: %w", errtext.ErrDatabaseRetriesExhausted, attempt, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, txBackoffMax)
+	}
+}
+
+// runTx makes one attempt. A panic in fn rolls back and propagates.
+func (db *DB) runTx(ctx context.Context, options *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, options)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			_ = tx.Rollback()
+			panic(recovered)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return errors.Join(err, ignoreDone(tx.Rollback()))
+	}
+	return tx.Commit()
+}
+
+// ignoreDone drops the error from rolling back a transaction fn already
+// ended.
+func ignoreDone(err error) error {
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Transaction tests.
+// This file verifies commit, rollback on error and panic, and retrying
+// transactions that hit SQLITE_BUSY.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Transaction tests. {{{
+
+func count(t *testing.T, database *DB) int {
+	t.Helper()
+	var rows int
+	if err := database.QueryRow("SELECT count(*) FROM log").Scan(&rows); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	return rows
+}
+
+func insert(tx *sql.Tx) error {
+	_, err := tx.Exec("INSERT INTO log VALUES (1)")
+	return err
+}
+
+func TestInTxCommitAndRollback(t *testing.T) {
+	database := openSQLite(t, "1s")
+	if _, err := database.Exec("CREATE TABLE log (id INTEGER)"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := database.InTx(ctx, nil, insert); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	failure := errors.New("stop")
+	err := database.InTx(ctx, nil, func(tx *sql.Tx) error {
+		if err := insert(tx); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the function error, got %v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected the panic to propagate")
+			}
+		}()
+		_ = database.InTx(ctx, nil, func(tx *sql.Tx) error {
+			_ = insert(tx)
+			panic("boom")
+		})
+	}()
+	if rows := count(t, database); rows != 1 {
+		t.Fatalf("expected only the committed row, got %d", rows)
+	}
+}
+
+func TestInTxRetriesBusy(t *testing.T) {
+	database := openSQLite(t, "0s")
+	if _, err := database.Exec("CREATE TABLE log (id INTEGER)"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	holder, err := database.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = holder.Rollback()
+	}()
+
+	if err := database.InTx(context.Background(), nil, insert); err != nil {
+		t.Fatalf("expected the transaction to succeed after the lock was released, got %v", err)
+	}
+	if rows := count(t, database); rows != 1 {
+		t.Fatalf("expected one row, got %d", rows)
+	}
+
+	holder, err = database.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer holder.Rollback()
+	err = database.InTx(context.Background(), nil, insert)
+	if err == nil || !strings.Contains(err.Error(), "retries exhausted") || !database.Dialect().Retryable(err) {
+		t.Fatalf("expected exhausted retries, got %v", err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
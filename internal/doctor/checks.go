@@ -0,0 +1,391 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Environment checks.
+// This file defines the individual doctor checks: listener addresses are
+// bound and released, the database file or server is probed and its
+// migration version compared with the known migrations, plugin
+// directories are listed, and token storage and the backup directory are
+// test-written. Checks only touch the filesystem with temporary files they
+// remove again.
+
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/backup"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+	"github.com/SandorMiskey/bms-core/internal/migrate/lint"
+	"github.com/SandorMiskey/bms-core/internal/socket"
+)
+
+// Listener checks. {{{
+
+// checkListeners binds every configured listener address and releases it
+// again. A running bmsd makes its own addresses fail as in use.
+func checkListeners(cfg config.Config) []Result {
+	listeners := []struct {
+		name     string
+		address  string
+		settings config.SocketConfig
+		enabled  bool
+	}{
+		{"admin", cfg.Admin.Address, config.SocketConfig{}, cfg.Admin.ListenerEnabled()},
+		{"grpc", cfg.GRPC.Address, cfg.GRPC.Socket, cfg.GRPC.Address != ""},
+		{"rest", cfg.REST.Address, cfg.REST.Socket, cfg.REST.Address != ""},
+		{"websocket", cfg.Websocket.Address, config.SocketConfig{}, cfg.Websocket.Address != ""},
+	}
+
+	var results []Result
+	for _, listener := range listeners {
+		name := "listener." + listener.name
+		if !listener.enabled {
+			results = append(results, Result{Name: name, Status: StatusSkip, Message: "not enabled"})
+			continue
+		}
+		bound, err := socket.Listen(listener.address, listener.settings)
+		if err != nil {
+			results = append(results, Result{Name: name, Status: StatusFail, Message: err.Error()})
+			continue
+		}
+		_ = bound.Close()
+		results = append(results, Result{Name: name, Status: StatusOK, Message: listener.address + " is bindable"})
+	}
+	return results
+}
+
+// }}}
+// Database checks. {{{
+
+// sqliteHeader starts every SQLite 3 database file.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+const defaultPostgresPort = "5432"
+
+func checkDatabase(ctx context.Context, database config.DatabaseConfig, timeout time.Duration) Result {
+	result := Result{Name: "database"}
+	switch database.Driver {
+	case config.DriverSQLite:
+		result.Status, result.Message = checkSQLite(database.DSN)
+	case config.DriverPostgres:
+		result.Status, result.Message = checkPostgres(ctx, database.DSN, timeout)
+	default:
+		result.Status, result.Message = StatusSkip, "database.driver is not set"
+	}
+	return result
+}
+
+// checkSQLite checks that an existing database file is a readable and
+// writable SQLite file, or that its directory accepts a new one.
+func checkSQLite(dsn string) (Status, string) {
+	if sqliteMemoryDSN(dsn) {
+		return StatusOK, "in-memory database"
+	}
+	path, _, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := writableDir(filepath.Dir(path)); err != nil {
+			return StatusFail, err.Error()
+		}
+		return StatusOK, path + " will be created"
+	}
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	if !info.Mode().IsRegular() {
+		return StatusFail, path + " is not a regular file"
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	defer file.Close()
+	if info.Size() > 0 {
+		header := make([]byte, len(sqliteHeader))
+		if _, err := io.ReadFull(file, header); err != nil || !bytes.Equal(header, sqliteHeader) {
+			return StatusFail, path + " is not a SQLite database"
+		}
+	}
+	return StatusOK, fmt.Sprintf("%s opens read-write (%s)", path, info.Mode().Perm())
+}
+
+// checkPostgres dials the server named by a URL or key=value DSN; it does
+// not authenticate.
+func checkPostgres(ctx context.Context, dsn string, timeout time.Duration) (Status, string) {
+	network, address, err := postgresAddress(dsn)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	_ = conn.Close()
+	return StatusOK, address + " is reachable (credentials not checked)"
+}
+
+// postgresAddress returns the network and address of the first host in dsn.
+// A host starting with / is a unix socket directory, as in libpq.
+func postgresAddress(dsn string) (string, string, error) {
+	host, port := "", ""
+	if strings.Contains(dsn, "://") {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return "", "", err
+		}
+		host, port = parsed.Hostname(), parsed.Port()
+		if socketHost := parsed.Query().Get("host"); socketHost != "" {
+			host = socketHost
+		}
+	} else {
+		for _, field := range strings.Fields(dsn) {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "host":
+				host = value
+			case "port":
+				port = value
+			}
+		}
+	}
+	host, _, _ = strings.Cut(host, ",")
+	port, _, _ = strings.Cut(port, ",")
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = defaultPostgresPort
+	}
+	if strings.HasPrefix(host, "/") {
+		return "unix", filepath.Join(host, ".s.PGSQL."+port), nil
+	}
+	return "tcp", net.JoinHostPort(host, port), nil
+}
+
+// checkMigrations loads the migrations and compares them with the version
+// recorded in the database. It only reads: a SQLite file is opened read-only
+// and a missing one counts as having every migration pending.
+func checkMigrations(ctx context.Context, database config.DatabaseConfig, reachable bool, timeout time.Duration) Result {
+	result := Result{Name: "migrations"}
+	if database.Driver == "" {
+		result.Status, result.Message = StatusSkip, "database.driver is not set"
+		return result
+	}
+	source := migrate.Source(database)
+	if problems := lint.Check(source); len(problems) > 0 {
+		result.Status, result.Message = StatusFail, fmt.Sprintf("%s: %v", errtext.ErrMigrationLint, problems)
+		return result
+	}
+	migrations, err := migrate.Load(source, database.Driver)
+	if err != nil {
+		result.Status, result.Message = StatusFail, err.Error()
+		return result
+	}
+	if !reachable {
+		result.Status, result.Message = StatusSkip, fmt.Sprintf("%d known; the database is not reachable", len(migrations))
+		return result
+	}
+
+	status, err := migrationStatus(ctx, database, timeout)
+	if err != nil {
+		result.Status, result.Message = StatusFail, err.Error()
+		return result
+	}
+	switch {
+	case status.Dirty:
+		result.Status, result.Message = StatusFail, fmt.Sprintf("%s at version %d", errtext.ErrMigrationDirty, status.Version)
+	case status.Unknown:
+		result.Status, result.Message = StatusFail, fmt.Sprintf("%s: %d", errtext.ErrMigrationUnknown, status.Version)
+	case status.Pending > 0 && database.AutoMigrate:
+		result.Status, result.Message = StatusOK, fmt.Sprintf("%d pending; applied at startup (database.auto_migrate)", status.Pending)
+	case status.Pending > 0:
+		result.Status, result.Message = StatusWarn, fmt.Sprintf("%d pending; run bmsd migrate up", status.Pending)
+	default:
+		result.Status, result.Message = StatusOK, fmt.Sprintf("current at version %d", status.Version)
+	}
+	return result
+}
+
+// migrationStatus reads the migration status. A SQLite file that does not
+// exist yet is not created.
+func migrationStatus(ctx context.Context, settings config.DatabaseConfig, timeout time.Duration) (migrate.Status, error) {
+	if settings.Driver == config.DriverSQLite && !sqliteMemoryDSN(settings.DSN) {
+		path, query, _ := strings.Cut(strings.TrimPrefix(settings.DSN, "file:"), "?")
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			settings.DSN = "file::memory:"
+		} else {
+			values, _ := url.ParseQuery(query)
+			values.Set("mode", "ro")
+			values.Set("_journal_mode", "")
+			settings.DSN = "file:" + path + "?" + values.Encode()
+		}
+	}
+	database, err := db.Open(settings)
+	if err != nil {
+		return migrate.Status{}, err
+	}
+	defer database.Close()
+	migrator, err := migrate.New(database, migrate.Source(settings), slog.New(slog.DiscardHandler))
+	if err != nil {
+		return migrate.Status{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return migrator.Status(ctx)
+}
+
+// sqliteMemoryDSN reports whether dsn names an in-memory database.
+func sqliteMemoryDSN(dsn string) bool {
+	path, query, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+	return path == "" || path == ":memory:" || strings.Contains(query, "mode=memory")
+}
+
+// }}}
+// Filesystem checks. {{{
+
+// checkPlugins checks that a plugin directory exists and can be listed. A
+// problem with a disabled plugin directory is only a warning.
+func checkPlugins(name string, path string, enabled bool) Result {
+	result := Result{Name: name}
+	if path == "" {
+		result.Status, result.Message = StatusSkip, name+".path is not set"
+		return result
+	}
+	if err := readableDir(path); err != nil {
+		result.Status, result.Message = StatusFail, err.Error()
+		if !enabled {
+			result.Status = StatusWarn
+		}
+		return result
+	}
+	result.Status, result.Message = StatusOK, path+" is readable"
+	return result
+}
+
+// checkBackup checks that backups can be written to backup.directory, or
+// to its parent when it does not exist yet, and reports the newest one. A
+// problem is only a warning unless backups are scheduled.
+func checkBackup(settings config.BackupConfig) Result {
+	result := Result{Name: "backup"}
+	if settings.Directory == "" {
+		result.Status, result.Message = StatusSkip, "backup.directory is not set"
+		return result
+	}
+	target := settings.Directory
+	if _, err := os.Stat(target); errors.Is(err, fs.ErrNotExist) {
+		target = filepath.Dir(target)
+	}
+	if err := writableDir(target); err != nil {
+		result.Status, result.Message = StatusFail, err.Error()
+		if settings.Interval == "" {
+			result.Status = StatusWarn
+		}
+		return result
+	}
+	backups, err := backup.List(settings.Directory)
+	switch {
+	case err != nil:
+		result.Status, result.Message = StatusWarn, err.Error()
+	case len(backups) == 0:
+		result.Status, result.Message = StatusOK, settings.Directory+" is writable; no backups yet"
+	default:
+		newest := backups[len(backups)-1].Manifest.CreatedAt
+		result.Status, result.Message = StatusOK, fmt.Sprintf("%s is writable; %d backups, newest %s", settings.Directory, len(backups), newest.Format(time.RFC3339))
+	}
+	return result
+}
+
+// checkTokenStorage checks that the server can persist tokens: config
+// storage writes the config file and file storage writes next to it.
+// Keychain access is platform specific and not checked.
+func checkTokenStorage(auth config.AuthConfig, configPath string) Result {
+	result := Result{Name: "token_storage", Status: StatusSkip}
+	switch {
+	case !auth.Enabled:
+		result.Message = "auth is disabled"
+		return result
+	case auth.TokenStorage == config.AuthTokenStorageKeychain:
+		result.Message = "keychain storage is not checked"
+		return result
+	case configPath == "":
+		result.Status, result.Message = StatusFail, "no config path to store tokens next to"
+		return result
+	}
+
+	var err error
+	target := filepath.Dir(configPath)
+	if _, statErr := os.Stat(configPath); auth.TokenStorage == config.AuthTokenStorageConfig && statErr == nil {
+		target = configPath
+		err = writableFile(configPath)
+	} else {
+		err = writableDir(target)
+	}
+	if err != nil {
+		result.Status, result.Message = StatusFail, err.Error()
+		return result
+	}
+	result.Status, result.Message = StatusOK, fmt.Sprintf("%s storage: %s is writable", auth.TokenStorage, target)
+	return result
+}
+
+// readableDir checks that path is a directory that can be listed.
+func readableDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	info, err := dir.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	if _, err := dir.ReadDir(1); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// writableDir checks that a file can be created in dir.
+func writableDir(dir string) error {
+	file, err := os.CreateTemp(dir, ".bmsd-doctor-*")
+	if err != nil {
+		return err
+	}
+	_ = file.Close()
+	return os.Remove(file.Name())
+}
+
+// writableFile checks that path opens for writing without changing it.
+func writableFile(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Environment self-diagnosis.
+// This file defines the doctor report: Run resolves the config and Check
+// validates it (reusing ValidationErrors and WarningList) before running the
+// environment checks in checks.go. The report renders as plain text for
+// operators and as JSON for support requests and scripts.
+
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Report types. {{{
+
+// DefaultTimeout bounds each network check.
+const DefaultTimeout = 3 * time.Second
+
+// Status is the outcome of a check, ordered by severity.
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusSkip Status = "skip"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// severity ranks statuses for the report summary; skips count as ok.
+func (status Status) severity() int {
+	switch status {
+	case StatusWarn:
+		return 1
+	case StatusFail:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Result is one check outcome.
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+// Report collects the config diagnostics and check results. Status is the
+// most severe check status.
+type Report struct {
+	ConfigPath string                  `json:"config_path,omitempty"`
+	Status     Status                  `json:"status"`
+	Errors     config.ValidationErrors `json:"errors,omitempty"`
+	Warnings   config.WarningList      `json:"warnings,omitempty"`
+	Checks     []Result                `json:"checks"`
+}
+
+// Options tune the checks.
+type Options struct {
+	Timeout time.Duration // Network check timeout (DefaultTimeout when zero).
+}
+
+// }}}
+// Running checks. {{{
+
+// Run resolves the config at configPath and checks it. A config that does
+// not resolve yields a report with a single failed config check.
+func Run(ctx context.Context, configPath string, options Options) Report {
+	cfg, path, err := config.ResolveConfig(configPath, config.ConfigOverlay{}, config.ConfigOverlay{})
+	if err != nil {
+		report := Report{ConfigPath: path, Status: StatusOK, Checks: []Result{}}
+		report.add(Result{Name: "config", Status: StatusFail, Message: err.Error()})
+		return report
+	}
+	return Check(ctx, cfg, path, options)
+}
+
+// Check validates cfg and runs every environment check against it. Checks
+// run even when validation fails, so one pass reports every problem.
+func Check(ctx context.Context, cfg config.Config, path string, options Options) Report {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+	report := Report{ConfigPath: path, Status: StatusOK, Checks: []Result{}}
+	report.add(checkConfig(cfg, &report))
+	for _, result := range checkListeners(cfg) {
+		report.add(result)
+	}
+	databaseResult := checkDatabase(ctx, cfg.Database, options.Timeout)
+	report.add(databaseResult)
+	report.add(checkMigrations(ctx, cfg.Database, databaseResult.Status == StatusOK, options.Timeout))
+	report.add(checkPlugins("plugins", cfg.Plugins.Path, cfg.Plugins.Enabled))
+	report.add(checkPlugins("client.plugins", cfg.Client.Plugins.Path, cfg.Client.Plugins.Enabled))
+	report.add(checkTokenStorage(cfg.Auth, path))
+	report.add(checkBackup(cfg.Backup))
+	return report
+}
+
+func (report *Report) add(result Result) {
+	report.Checks = append(report.Checks, result)
+	if result.Status.severity() > report.Status.severity() {
+		report.Status = result.Status
+	}
+}
+
+// checkConfig records validation errors and warnings on report.
+func checkConfig(cfg config.Config, report *Report) Result {
+	report.Warnings = config.CollectConfigWarnings(cfg)
+	if err := config.ValidateConfig(cfg); err != nil {
+		var errs config.ValidationErrors
+		if errors.As(err, &errs) {
+			report.Errors = errs
+		} else {
+			report.Errors = config.ValidationErrors{{Message: err.Error()}}
+		}
+		return Result{Name: "config", Status: StatusFail, Message: fmt.Sprintf("%d validation errors", len(report.Errors))}
+	}
+	if len(report.Warnings) > 0 {
+		return Result{Name: "config", Status: StatusWarn, Message: fmt.Sprintf("valid with %d warnings", len(report.Warnings))}
+	}
+	return Result{Name: "config", Status: StatusOK, Message: "valid"}
+}
+
+// }}}
+// Rendering. {{{
+
+// WriteText writes the report for operators.
+func (report Report) WriteText(writer io.Writer) {
+	if report.ConfigPath != "" {
+		fmt.Fprintf(writer, "config: %s\n", report.ConfigPath)
+	}
+	for _, fieldError := range report.Errors {
+		fmt.Fprintf(writer, "  error    %s\n", fieldError.Error())
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(writer, "  warning  %s\n", warning.String())
+	}
+	for _, result := range report.Checks {
+		fmt.Fprintf(writer, "%-6s%-20s%s\n", result.Status, result.Name, result.Message)
+	}
+	fmt.Fprintf(writer, "result: %s\n", report.Status)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
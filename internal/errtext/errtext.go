@@ -11,21 +11,105 @@ package errtext
 // Error text constants. {{{
 
 const (
+	ErrAdminRequestFailed         = "admin request failed"
+	ErrAdminUnauthorized          = "admin authentication required"
+	ErrAuditChainBroken           = "audit chain broken"
+	ErrAuditLogClosed             = "audit log is closed"
+	ErrAuditLogCorrupt            = "audit log is corrupt"
+	ErrAuditPathRequired          = "audit path is required"
+	ErrAuditRecordFailed          = "audit record failed"
+	ErrBackupChecksum             = "backup checksum mismatch"
+	ErrBackupCreate               = "backup failed"
+	ErrBackupInvalid              = "invalid backup"
+	ErrBackupMismatch             = "backup does not match this server"
+	ErrBackupNewer                = "backup schema is newer than this build"
+	ErrBuildInfoUnavailable       = "build info is not available"
+	ErrCloseLogFile               = "close log file"
+	ErrCommandFailed              = "command failed"
+	ErrCompressLogFile            = "compress log file"
 	ErrConfigResolutionFailed     = "config resolution failed"
 	ErrConfigValidationFailed     = "config validation failed"
+	ErrDatabaseConnect            = "failed to connect to database"
+	ErrDatabaseDriver             = "unsupported database driver"
+	ErrDatabaseOpen               = "failed to open database"
+	ErrDatabaseRetriesExhausted   = "database transaction retries exhausted"
+	ErrGenerateCertificate        = "failed to generate self-signed certificate"
+	ErrHealthCheckConflict        = "health check already registered"
+	ErrHealthCheckTimeout         = "health check timed out"
 	ErrHealthServerServeFailed    = "health server failed"
 	ErrHealthServerShutdownFailed = "health server shutdown failed"
+	ErrIncompatibleAPIVersion     = "incompatible server API version"
+	ErrInvalidAdminRequest        = "invalid admin request"
+	ErrInvalidAuditTime           = "invalid audit time"
 	ErrInvalidConfigKeys          = "invalid config keys"
+	ErrInvalidHealthCheck         = "invalid health check name"
+	ErrInvalidLevelTTL            = "invalid level ttl"
 	ErrInvalidLogComponent        = "invalid log component"
+	ErrInvalidLogDedupWindow      = "invalid logging dedup window"
 	ErrInvalidLogFormat           = "invalid log format"
 	ErrInvalidLogLevel            = "invalid log level"
+	ErrInvalidLogMaxAge           = "invalid log max age"
+	ErrInvalidLogRateLimit        = "invalid logging rate limit"
+	ErrInvalidWatchdog            = "invalid systemd watchdog settings"
+	ErrLifecycleComponentFailed   = "component failed"
+	ErrLifecycleCycle             = "component dependency cycle"
+	ErrLifecycleDuplicate         = "component already registered"
+	ErrLifecycleNameRequired      = "component name is required"
+	ErrLifecycleStartFailed       = "failed to start component"
+	ErrLifecycleStopFailed        = "failed to stop component"
+	ErrLifecycleUnknownDependency = "unknown component dependency"
+	ErrLoadTLSCA                  = "failed to load TLS CA bundle"
+	ErrLoadTLSCertificate         = "failed to load TLS certificate"
+	ErrLogComponentConflict       = "log component already registered"
+	ErrLogComponentNamespace      = "log component requires a namespace"
+	ErrLogEventAttrs              = "log event is missing attributes"
+	ErrLogFileClosed              = "log file is closed"
 	ErrLoggerInitFailed           = "logger init failed"
 	ErrLogFormatRequired          = "log format is required"
 	ErrLogLevelRequired           = "log level is required"
+	ErrLogReopenFailed            = "log reopen failed"
+	ErrLogTargetRequired          = "log target is required"
+	ErrMigrationDirty             = "database migration state is dirty"
+	ErrMigrationFailed            = "migration failed"
+	ErrMigrationInvalid           = "invalid migration file"
+	ErrMigrationLint              = "migration tree failed checks"
+	ErrMigrationLock              = "failed to acquire migration lock"
+	ErrMigrationUnknown           = "unknown migration version"
+	ErrNoMigrationsApplied        = "no migrations applied"
+	ErrOpenAuditLog               = "open audit log"
 	ErrOpenConfig                 = "open config"
 	ErrOpenConfigOverlay          = "open config overlay"
+	ErrOpenLogFile                = "open log file"
+	ErrPathExists                 = "path already exists"
+	ErrPeerCredentials            = "peer credentials unavailable"
+	ErrRegisterHealthCheck        = "failed to register health check"
+	ErrRestoreFailed              = "restore failed"
+	ErrRestoreInMemory            = "cannot restore into an in-memory database"
+	ErrRestoreInUse               = "database is in use; stop the server first"
+	ErrRestoreNotEmpty            = "restore target database is not empty"
+	ErrRotateLogFile              = "rotate log file"
+	ErrSaveCertificate            = "failed to save self-signed certificate"
+	ErrSchemaDumpInvalid          = "invalid schema dump"
+	ErrSchemaDumpMissing          = "no schema dump for migration version"
+	ErrSchemaIntrospect           = "failed to read database schema"
+	ErrServerAddressRequired      = "server address is not configured"
+	ErrServerIDMismatch           = "database belongs to another server"
+	ErrServerMeta                 = "failed to access server metadata"
+	ErrServerStartFailed          = "server failed to start"
+	ErrServerUnreachable          = "server is unreachable"
+	ErrServerVersionFailed        = "failed to read server version"
+	ErrSocketActivation           = "invalid socket activation"
+	ErrSocketInUse                = "socket is in use by another process"
+	ErrSocketNotSocket            = "socket path exists and is not a socket"
+	ErrSocketOwner                = "failed to set socket owner"
 	ErrStatConfig                 = "stat config"
 	ErrStatConfigOverlay          = "stat config overlay"
+	ErrSystemdNotifyFailed        = "failed to notify systemd"
+	ErrTLSFingerprintMismatch     = "server certificate fingerprint mismatch"
+	ErrTLSReloadFailed            = "failed to reload TLS certificate"
+	ErrUnexpectedHealthStatus     = "unexpected health endpoint status"
+	ErrUnknownCommand             = "unknown command"
+	ErrWriteAuditLog              = "write audit log"
 )
 
 // }}}
@@ -0,0 +1,273 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Health check registry.
+// This file defines the Checker interface and Registry, which runs named
+// readiness checks with per-check timeouts and result caching. Critical
+// checks gate readiness; non-critical checks are reported but never make the
+// service unready. Checks run concurrently so one slow dependency does not
+// delay the others beyond its own timeout.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Checkers. {{{
+
+const (
+	DefaultCheckTimeout = 2 * time.Second
+
+	StatusFailed = "failed"
+	StatusOK     = "ok"
+)
+
+var checkNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// Checker reports whether a dependency is usable.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to Checker.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls the function.
+func (check CheckerFunc) Check(ctx context.Context) error {
+	return check(ctx)
+}
+
+// CheckOptions configures how a check runs and counts.
+type CheckOptions struct {
+	Critical bool          // Failing makes the service unready.
+	Timeout  time.Duration // Per-run timeout (0 uses DefaultCheckTimeout).
+	CacheTTL time.Duration // Reuse the last result for this long (0 disables caching).
+}
+
+// CheckResult reports a single check run.
+type CheckResult struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached,omitempty"`
+}
+
+// OK reports whether the check passed.
+func (result CheckResult) OK() bool {
+	return result.Status == StatusOK
+}
+
+// withoutErrors returns a copy of the report with check error text removed.
+func (report Report) withoutErrors() Report {
+	checks := make([]CheckResult, len(report.Checks))
+	for i, result := range report.Checks {
+		result.Error = ""
+		checks[i] = result
+	}
+	report.Checks = checks
+	return report
+}
+
+// Report aggregates check results.
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// }}}
+// Registry. {{{
+
+// Registry holds named checks.
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]*registeredCheck
+	now    func() time.Time
+}
+
+type registeredCheck struct {
+	name    string
+	checker Checker
+	options CheckOptions
+
+	mu   sync.Mutex
+	last *CheckResult
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]*registeredCheck), now: time.Now}
+}
+
+// Register adds a named check. Names are lowercase identifiers and must be
+// unique.
+func (registry *Registry) Register(name string, checker Checker, options CheckOptions) error {
+	if !checkNamePattern.MatchString(name) {
+		return fmt.Errorf("%s: %q", errtext.ErrInvalidHealthCheck, name)
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultCheckTimeout
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, exists := registry.checks[name]; exists {
+		return fmt.Errorf("%s: %q", errtext.ErrHealthCheckConflict, name)
+	}
+	registry.checks[name] = &registeredCheck{name: name, checker: checker, options: options}
+	return nil
+}
+
+// Names returns the registered check names, sorted.
+func (registry *Registry) Names() []string {
+	if registry == nil {
+		return nil
+	}
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	names := make([]string, 0, len(registry.checks))
+	for name := range registry.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run runs every check not in exclude concurrently and returns the results
+// sorted by name. The report fails when any critical check fails.
+func (registry *Registry) Run(ctx context.Context, exclude ...string) Report {
+	report := Report{Status: StatusOK, Checks: []CheckResult{}}
+	if registry == nil {
+		return report
+	}
+
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[name] = true
+	}
+	registry.mu.RLock()
+	checks := make([]*registeredCheck, 0, len(registry.checks))
+	for name, check := range registry.checks {
+		if !skip[name] {
+			checks = append(checks, check)
+		}
+	}
+	registry.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var group sync.WaitGroup
+	for index, check := range checks {
+		group.Go(func() {
+			results[index] = check.run(ctx, registry.now)
+		})
+	}
+	group.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	for _, result := range results {
+		if result.Critical && !result.OK() {
+			report.Status = StatusFailed
+		}
+	}
+	report.Checks = results
+	return report
+}
+
+// RunCheck runs a single check by name.
+func (registry *Registry) RunCheck(ctx context.Context, name string) (CheckResult, bool) {
+	if registry == nil {
+		return CheckResult{}, false
+	}
+	registry.mu.RLock()
+	check, ok := registry.checks[name]
+	registry.mu.RUnlock()
+	if !ok {
+		return CheckResult{}, false
+	}
+	return check.run(ctx, registry.now), true
+}
+
+// Results returns the most recent result of every check that has run,
+// sorted by name, without running anything.
+func (registry *Registry) Results() []CheckResult {
+	if registry == nil {
+		return nil
+	}
+	registry.mu.RLock()
+	checks := make([]*registeredCheck, 0, len(registry.checks))
+	for _, check := range registry.checks {
+		checks = append(checks, check)
+	}
+	registry.mu.RUnlock()
+
+	results := make([]CheckResult, 0, len(checks))
+	for _, check := range checks {
+		check.mu.Lock()
+		if check.last != nil {
+			results = append(results, *check.last)
+		}
+		check.mu.Unlock()
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+// run executes the check under its timeout or returns the cached result.
+func (check *registeredCheck) run(ctx context.Context, now func() time.Time) CheckResult {
+	check.mu.Lock()
+	defer check.mu.Unlock()
+
+	started := now()
+	if check.last != nil && check.options.CacheTTL > 0 && started.Sub(check.last.CheckedAt) < check.options.CacheTTL {
+		cached := *check.last
+		cached.Cached = true
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, check.options.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("%s after %s", errtext.ErrHealthCheckTimeout, check.options.Timeout)
+	}
+
+	result := CheckResult{
+		Name:      check.name,
+		Status:    StatusOK,
+		Critical:  check.options.Critical,
+		Duration:  now().Sub(started).Round(time.Microsecond).String(),
+		CheckedAt: started,
+	}
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	}
+	check.last = &result
+	return result
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
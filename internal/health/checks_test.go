@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Health check registry tests.
+// This file verifies registration rules, critical versus non-critical
+// results, per-check timeouts, result caching, and the verbose and
+// single-check readiness endpoints, which never show check errors in the
+// degraded reason.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Registry tests. {{{
+
+func passing(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("connection refused") }
+
+func TestRegistryRegister(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register("database", CheckerFunc(passing), CheckOptions{}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	err := registry.Register("database", CheckerFunc(passing), CheckOptions{})
+	if err == nil || !strings.Contains(err.Error(), errtext.ErrHealthCheckConflict) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	for _, name := range []string{"", "Database", "data base", "db/primary"} {
+		err := registry.Register(name, CheckerFunc(passing), CheckOptions{})
+		if err == nil || !strings.Contains(err.Error(), errtext.ErrInvalidHealthCheck) {
+			t.Fatalf("expected invalid name error for %q, got %v", name, err)
+		}
+	}
+	if names := registry.Names(); len(names) != 1 || names[0] != "database" {
+		t.Fatalf("unexpected names: %v", names)
+	}
+}
+
+func TestRegistryRunCritical(t *testing.T) {
+	registry := NewRegistry()
+	_ = registry.Register("database", CheckerFunc(passing), CheckOptions{Critical: true})
+	_ = registry.Register("clublog", CheckerFunc(failing), CheckOptions{})
+	if results := registry.Results(); len(results) != 0 {
+		t.Fatalf("expected no results before a run, got %+v", results)
+	}
+
+	report := registry.Run(context.Background())
+	if results := registry.Results(); len(results) != 2 || results[0].Name != "clublog" {
+		t.Fatalf("expected the last results, got %+v", results)
+	}
+	if report.Status != StatusOK {
+		t.Fatalf("non-critical failure should not fail the report: %+v", report)
+	}
+	if len(report.Checks) != 2 || report.Checks[0].Name != "clublog" || report.Checks[0].OK() {
+		t.Fatalf("unexpected checks: %+v", report.Checks)
+	}
+	if report.Checks[0].Error != "connection refused" {
+		t.Fatalf("unexpected error: %q", report.Checks[0].Error)
+	}
+
+	_ = registry.Register("migrations", CheckerFunc(failing), CheckOptions{Critical: true})
+	if report := registry.Run(context.Background()); report.Status != StatusFailed {
+		t.Fatalf("critical failure should fail the report: %+v", report)
+	}
+	if report := registry.Run(context.Background(), "migrations"); report.Status != StatusOK {
+		t.Fatalf("excluded check should not count: %+v", report)
+	}
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	registry := NewRegistry()
+	blocking := CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	_ = registry.Register("slow", blocking, CheckOptions{Critical: true, Timeout: 20 * time.Millisecond})
+
+	result, ok := registry.RunCheck(context.Background(), "slow")
+	if !ok || result.OK() || !strings.Contains(result.Error, errtext.ErrHealthCheckTimeout) {
+		t.Fatalf("expected timeout, got %+v", result)
+	}
+}
+
+func TestRegistryCache(t *testing.T) {
+	registry := NewRegistry()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+
+	var calls atomic.Int32
+	counting := CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+	_ = registry.Register("cached", counting, CheckOptions{CacheTTL: time.Minute})
+
+	first, _ := registry.RunCheck(context.Background(), "cached")
+	second, _ := registry.RunCheck(context.Background(), "cached")
+	if calls.Load() != 1 || first.Cached || !second.Cached {
+		t.Fatalf("expected one call and a cached second result, got %d calls", calls.Load())
+	}
+
+	now = now.Add(time.Minute)
+	if result, _ := registry.RunCheck(context.Background(), "cached"); result.Cached || calls.Load() != 2 {
+		t.Fatalf("expected the cache to expire, got %d calls", calls.Load())
+	}
+}
+
+// }}}
+// Readiness endpoint tests. {{{
+
+func TestReadyzVerbose(t *testing.T) {
+	state := NewState(nil)
+	state.Set(PhaseReady, "test")
+	registry := NewRegistry()
+	_ = registry.Register("database", CheckerFunc(failing), CheckOptions{Critical: true})
+	mux := NewMux(state, registry)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadyzPath, nil))
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Body.String() != "not ready\n" {
+		t.Fatalf("unexpected response: %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadyzPath+"?verbose", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", recorder.Code)
+	}
+	var response ReadyzResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if response.State != PhaseReady || response.Status != StatusFailed || len(response.Checks) != 1 || response.Checks[0].Error != "" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	recorder = httptest.NewRecorder()
+	ReadyzHandler(state, registry, true)(recorder, httptest.NewRequest(http.MethodGet, ReadyzPath+"?verbose", nil))
+	response = ReadyzResponse{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(response.Checks) != 1 || response.Checks[0].Error != "connection refused" {
+		t.Fatalf("expected check error with detail, got: %+v", response)
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadyzPath+"?exclude=database", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200 with database excluded, got %d", recorder.Code)
+	}
+}
+
+func TestReadyzCheck(t *testing.T) {
+	registry := NewRegistry()
+	_ = registry.Register("database", CheckerFunc(passing), CheckOptions{Critical: true})
+	_ = registry.Register("clublog", CheckerFunc(failing), CheckOptions{})
+	mux := NewMux(NewState(nil), registry)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz/database", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "ok\n" {
+		t.Fatalf("unexpected response: %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz/clublog?verbose", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", recorder.Code)
+	}
+	var result CheckResult
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if result.Name != "clublog" || result.Critical || result.Error != "" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz/clublog", nil))
+	if recorder.Body.String() != "failed\n" {
+		t.Fatalf("unexpected public body: %q", recorder.Body.String())
+	}
+
+	detail := http.NewServeMux()
+	detail.HandleFunc("/readyz/{check}", ReadyzCheckHandler(registry, true))
+	recorder = httptest.NewRecorder()
+	detail.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz/clublog", nil))
+	if recorder.Body.String() != "failed: connection refused\n" {
+		t.Fatalf("unexpected detail body: %q", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz/missing", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", recorder.Code)
+	}
+}
+
+func TestReadyzDegradedReason(t *testing.T) {
+	registry := NewRegistry()
+	_ = registry.Register("audit", CheckerFunc(failing), CheckOptions{})
+	state := NewState(nil)
+	state.Set(PhaseReady, "test")
+	mux := NewMux(state, registry)
+
+	for _, path := range []string{ReadyzPath + "?verbose", StartupzPath + "?verbose"} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		body := recorder.Body.String()
+		if strings.Contains(body, "connection refused") || !strings.Contains(body, "optional checks failing: audit") {
+			t.Fatalf("unexpected %s body: %s", path, body)
+		}
+	}
+	if state.Snapshot().State != PhaseDegraded {
+		t.Fatalf("expected degraded: %+v", state.Snapshot())
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
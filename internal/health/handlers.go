@@ -2,37 +2,115 @@
 // Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
 
 // Health HTTP handlers.
-// This file defines HTTP handlers and a mux for liveness and readiness checks.
-// The handlers return plain text responses with appropriate status codes.
+// This file defines HTTP handlers and a mux for liveness, startup, and
+// readiness checks. The handlers return plain text responses with appropriate
+// status codes; `/readyz?verbose` returns the lifecycle state and per-check
+// JSON and `/readyz/<check>` runs a single registered check, following the
+// Kubernetes health endpoint conventions. The mux serves the public REST
+// listener, so its responses carry check status only; the admin listener
+// mounts detailed readiness handlers that include check error text.
 
 package health
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
+)
 
 // Health handlers. {{{
 
 const (
-	healthzPath = "/healthz"
-	readyzPath  = "/readyz"
+	HealthzPath     = "/healthz"
+	ReadyzPath      = "/readyz"
+	readyzCheckPath = "/readyz/{check}"
+	StartupzPath    = "/startupz"
 )
 
-func NewMux(state *State) *http.ServeMux {
+// ReadyzResponse is the verbose readiness body: the lifecycle state followed
+// by the check report.
+type ReadyzResponse struct {
+	Snapshot
+	Report
+}
+
+func NewMux(state *State, checks *Registry) *http.ServeMux {
 	mux := http.NewServeMux()
-	mux.HandleFunc(healthzPath, HealthzHandler)
-	mux.HandleFunc(readyzPath, ReadyzHandler(state))
+	mux.HandleFunc(HealthzPath, HealthzHandler(state))
+	mux.HandleFunc(StartupzPath, StartupzHandler(state))
+	mux.HandleFunc(ReadyzPath, ReadyzHandler(state, checks, false))
+	mux.HandleFunc(readyzCheckPath, ReadyzCheckHandler(checks, false))
 	return mux
 }
 
-func HealthzHandler(writer http.ResponseWriter, _ *http.Request) {
-	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	writer.WriteHeader(http.StatusOK)
-	_, _ = writer.Write([]byte("ok\n"))
+// HealthzHandler reports the process alive in every phase but stopped.
+func HealthzHandler(state *State) http.HandlerFunc {
+	return func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if state != nil && state.Snapshot().State == PhaseStopped {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = writer.Write([]byte("stopped\n"))
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte("ok\n"))
+	}
 }
 
-func ReadyzHandler(state *State) http.HandlerFunc {
-	return func(writer http.ResponseWriter, _ *http.Request) {
+// StartupzHandler reports started once the service has left the starting
+// phase.
+func StartupzHandler(state *State) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		snapshot := state.Snapshot()
+		started := snapshot.State != PhaseStarting
+		if request.URL.Query().Has("verbose") {
+			writeJSON(writer, readyStatus(started), snapshot)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if started {
+			writer.WriteHeader(http.StatusOK)
+			_, _ = writer.Write([]byte("started\n"))
+			return
+		}
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = writer.Write([]byte("starting\n"))
+	}
+}
+
+// ReadyzHandler reports ready when the state is ready or degraded and every
+// critical check passes; it returns 503 while starting, draining, or
+// stopped. Checks named by `exclude` query parameters are skipped. A full run
+// also updates the state, so a failing optional check shows up as degraded.
+// Without detail, verbose responses omit check error text.
+func ReadyzHandler(state *State, checks *Registry, detail bool) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		exclude := query["exclude"]
+
+		report := Report{Status: StatusOK, Checks: []CheckResult{}}
+		if state.IsReady() {
+			report = checks.Run(request.Context(), exclude...)
+			if state != nil && len(exclude) == 0 {
+				state.Observe(report)
+			}
+		}
+		snapshot := state.Snapshot()
+		ready := snapshot.Serving() && report.Status == StatusOK
+
+		if query.Has("verbose") {
+			if !snapshot.Serving() {
+				report.Status = StatusFailed
+			}
+			if !detail {
+				report = report.withoutErrors()
+			}
+			writeJSON(writer, readyStatus(ready), ReadyzResponse{Snapshot: snapshot, Report: report})
+			return
+		}
+
 		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		if state != nil && state.IsReady() {
+		if ready {
 			writer.WriteHeader(http.StatusOK)
 			_, _ = writer.Write([]byte("ready\n"))
 			return
@@ -42,6 +120,52 @@ func ReadyzHandler(state *State) http.HandlerFunc {
 	}
 }
 
+// ReadyzCheckHandler runs the check named in the path regardless of whether
+// it is critical. Without detail, responses omit the check error text.
+func ReadyzCheckHandler(checks *Registry, detail bool) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		result, ok := checks.RunCheck(request.Context(), request.PathValue("check"))
+		if !ok {
+			http.Error(writer, "unknown health check", http.StatusNotFound)
+			return
+		}
+		if !detail {
+			result.Error = ""
+		}
+
+		if request.URL.Query().Has("verbose") {
+			writeJSON(writer, readyStatus(result.OK()), result)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if result.OK() {
+			writer.WriteHeader(http.StatusOK)
+			_, _ = writer.Write([]byte("ok\n"))
+			return
+		}
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		if result.Error == "" {
+			_, _ = writer.Write([]byte("failed\n"))
+			return
+		}
+		_, _ = writer.Write([]byte("failed: " + result.Error + "\n"))
+	}
+}
+
+func readyStatus(ok bool) int {
+	if ok {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+func writeJSON(writer http.ResponseWriter, status int, payload any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(payload)
+}
+
 // }}}
 
 // vim: set ts=4 sw=4 noet:
@@ -2,8 +2,8 @@
 // Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
 
 // Health handler tests.
-// This file verifies health and readiness handlers return expected status codes
-// and response bodies for ready and not-ready states.
+// This file verifies health, startup, and readiness handlers return expected
+// status codes and response bodies across lifecycle states.
 
 package health
 
@@ -17,9 +17,10 @@ import (
 
 func TestHealthzHandler(t *testing.T) {
 	recorder := httptest.NewRecorder()
-	request := httptest.NewRequest(http.MethodGet, healthzPath, nil)
+	request := httptest.NewRequest(http.MethodGet, HealthzPath, nil)
 
-	HealthzHandler(recorder, request)
+	state := NewState(nil)
+	HealthzHandler(state)(recorder, request)
 
 	if recorder.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d", recorder.Code)
@@ -27,14 +28,40 @@ func TestHealthzHandler(t *testing.T) {
 	if recorder.Body.String() != "ok\n" {
 		t.Fatalf("unexpected body: %q", recorder.Body.String())
 	}
+
+	state.Set(PhaseStopped, "test")
+	recorder = httptest.NewRecorder()
+	HealthzHandler(state)(recorder, request)
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 when stopped, got %d", recorder.Code)
+	}
+}
+
+func TestStartupzHandler(t *testing.T) {
+	state := NewState(nil)
+	handler := StartupzHandler(state)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, StartupzPath, nil))
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Body.String() != "starting\n" {
+		t.Fatalf("unexpected response: %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	state.Set(PhaseReady, "test")
+	state.Set(PhaseDraining, "test")
+	recorder = httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, StartupzPath, nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "started\n" {
+		t.Fatalf("unexpected response: %d %q", recorder.Code, recorder.Body.String())
+	}
 }
 
 func TestReadyzHandler(t *testing.T) {
-	state := NewState()
-	handler := ReadyzHandler(state)
+	state := NewState(nil)
+	handler := ReadyzHandler(state, nil, false)
 
 	recorder := httptest.NewRecorder()
-	request := httptest.NewRequest(http.MethodGet, readyzPath, nil)
+	request := httptest.NewRequest(http.MethodGet, ReadyzPath, nil)
 	handler(recorder, request)
 
 	if recorder.Code != http.StatusServiceUnavailable {
@@ -44,9 +71,9 @@ func TestReadyzHandler(t *testing.T) {
 		t.Fatalf("unexpected body: %q", recorder.Body.String())
 	}
 
-	state.SetReady(true)
+	state.Set(PhaseReady, "test")
 	recorder = httptest.NewRecorder()
-	request = httptest.NewRequest(http.MethodGet, readyzPath, nil)
+	request = httptest.NewRequest(http.MethodGet, ReadyzPath, nil)
 	handler(recorder, request)
 
 	if recorder.Code != http.StatusOK {
@@ -55,6 +82,15 @@ func TestReadyzHandler(t *testing.T) {
 	if recorder.Body.String() != "ready\n" {
 		t.Fatalf("unexpected body: %q", recorder.Body.String())
 	}
+
+	state.Set(PhaseDraining, "test")
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, ReadyzPath, nil)
+	handler(recorder, request)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 while draining, got %d", recorder.Code)
+	}
 }
 
 // }}}
@@ -2,34 +2,190 @@
 // Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
 
 // Health state tracking.
-// This file defines a readiness state used by health handlers to report
-// whether the service is ready to accept traffic.
+// This file defines the service lifecycle state used by health handlers:
+// starting, ready, degraded, draining, and stopped. Degraded means optional
+// dependencies (QRZ, Clublog) are failing while the logbook still works; it
+// is derived from non-critical check results. Every transition carries a
+// reason, is logged as a health_state_changed event, and is passed to the
+// listeners registered with OnChange.
 
 package health
 
-import "sync"
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/SandorMiskey/bms-core/internal/logging"
+)
+
+// Lifecycle phases. {{{
+
+// Phase is a lifecycle state.
+type Phase string
+
+const (
+	PhaseStarting Phase = "starting"
+	PhaseReady    Phase = "ready"
+	PhaseDegraded Phase = "degraded"
+	PhaseDraining Phase = "draining"
+	PhaseStopped  Phase = "stopped"
+)
+
+// Snapshot is the current phase and why it was entered.
+type Snapshot struct {
+	State  Phase     `json:"state"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+// Serving reports whether the phase accepts traffic.
+func (snapshot Snapshot) Serving() bool {
+	return snapshot.State == PhaseReady || snapshot.State == PhaseDegraded
+}
+
+// }}}
 // Health state. {{{
 
 type State struct {
-	mu    sync.RWMutex
-	ready bool
+	mu        sync.RWMutex
+	notify    sync.Mutex // Serializes transitions so listeners see them in order.
+	snapshot  Snapshot
+	logger    *slog.Logger
+	now       func() time.Time
+	listeners []func(previous Snapshot, current Snapshot)
+}
+
+// NewState returns a state in the starting phase. Transitions are logged to
+// logger when it is non-nil.
+func NewState(logger *slog.Logger) *State {
+	state := &State{logger: logger, now: time.Now}
+	state.snapshot = Snapshot{State: PhaseStarting, Reason: "process started", Since: state.now()}
+	return state
 }
 
-func NewState() *State {
-	return &State{}
+// Set moves to phase and reports whether the phase changed. Setting the
+// current phase again only updates the reason.
+func (state *State) Set(phase Phase, reason string) bool {
+	return state.transition(phase, reason, false)
 }
 
-func (state *State) SetReady(ready bool) {
+// OnChange registers listener to be called after every phase change, in the
+// goroutine that made it. Listeners run one transition at a time, in order,
+// and must not change the state themselves. Reason-only updates are not
+// reported.
+func (state *State) OnChange(listener func(previous Snapshot, current Snapshot)) {
 	state.mu.Lock()
 	defer state.mu.Unlock()
-	state.ready = ready
+	state.listeners = append(state.listeners, listener)
 }
 
-func (state *State) IsReady() bool {
+// Follow calls listener with the current snapshot and then after every phase
+// change. No change can slip in between, so listener sees every phase once,
+// in order.
+func (state *State) Follow(listener func(current Snapshot)) {
+	state.notify.Lock()
+	defer state.notify.Unlock()
+	listener(state.Snapshot())
+	state.OnChange(func(_ Snapshot, current Snapshot) {
+		listener(current)
+	})
+}
+
+// transition applies a phase change; with servingOnly it is skipped unless
+// the service is currently ready or degraded.
+func (state *State) transition(phase Phase, reason string, servingOnly bool) bool {
+	state.notify.Lock()
+	defer state.notify.Unlock()
+
+	state.mu.Lock()
+	previous := state.snapshot
+	if servingOnly && !previous.Serving() {
+		state.mu.Unlock()
+		return false
+	}
+	if previous.State == phase {
+		state.snapshot.Reason = reason
+		state.mu.Unlock()
+		return false
+	}
+	state.snapshot = Snapshot{State: phase, Reason: reason, Since: state.now()}
+	current := state.snapshot
+	listeners := state.listeners
+	state.mu.Unlock()
+
+	if state.logger != nil {
+		logging.EventHealthStateChanged.Log(context.Background(), state.logger,
+			logging.FieldHealthState, string(phase),
+			logging.FieldHealthPrevious, string(previous.State),
+			logging.FieldReason, reason,
+		)
+	}
+	for _, listener := range listeners {
+		listener(previous, current)
+	}
+	return true
+}
+
+// Snapshot returns the current phase.
+func (state *State) Snapshot() Snapshot {
+	if state == nil {
+		return Snapshot{State: PhaseStarting}
+	}
 	state.mu.RLock()
 	defer state.mu.RUnlock()
-	return state.ready
+	return state.snapshot
+}
+
+// IsReady reports whether the service accepts traffic (ready or degraded).
+func (state *State) IsReady() bool {
+	return state.Snapshot().Serving()
+}
+
+// IsStarted reports whether startup has finished.
+func (state *State) IsStarted() bool {
+	return state.Snapshot().State != PhaseStarting
+}
+
+// Observe moves between ready and degraded from a check report: any failing
+// non-critical check degrades the service. The reason names the failing
+// checks but not their errors, since it is served on the public probes.
+// Other phases are left alone, so a late report cannot pull a draining
+// server back into rotation.
+func (state *State) Observe(report Report) {
+	var failed []string
+	for _, result := range report.Checks {
+		if !result.Critical && !result.OK() {
+			failed = append(failed, result.Name)
+		}
+	}
+	sort.Strings(failed)
+
+	if len(failed) > 0 {
+		state.transition(PhaseDegraded, "optional checks failing: "+strings.Join(failed, ", "), true)
+		return
+	}
+	if state.Snapshot().State == PhaseDegraded {
+		state.transition(PhaseReady, "optional checks recovered", true)
+	}
+}
+
+// Watch runs the registry every interval and feeds the results to Observe
+// until ctx is done.
+func (state *State) Watch(ctx context.Context, registry *Registry, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		state.Observe(registry.Run(ctx))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 // }}}
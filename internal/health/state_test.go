@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Health state tests.
+// This file verifies lifecycle transitions, degraded detection from
+// non-critical checks, the transition events logged on each change, and
+// change listeners.
+
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SandorMiskey/bms-core/internal/logging"
+)
+
+// Health state tests. {{{
+
+func TestStateTransitions(t *testing.T) {
+	var buffer bytes.Buffer
+	state := NewState(slog.New(slog.NewJSONHandler(&buffer, nil)))
+	if state.IsStarted() || state.IsReady() {
+		t.Fatalf("new state should be starting: %+v", state.Snapshot())
+	}
+
+	if !state.Set(PhaseReady, "startup complete") {
+		t.Fatal("expected a transition to ready")
+	}
+	if state.Set(PhaseReady, "again") {
+		t.Fatal("setting the same phase should not transition")
+	}
+	if !state.IsStarted() || !state.IsReady() {
+		t.Fatalf("expected ready: %+v", state.Snapshot())
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one transition event, got %d", len(lines))
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if record[logging.FieldEvent] != logging.EventHealthStateChanged.Name ||
+		record[logging.FieldHealthState] != string(PhaseReady) ||
+		record[logging.FieldHealthPrevious] != string(PhaseStarting) ||
+		record[logging.FieldReason] != "startup complete" {
+		t.Fatalf("unexpected event: %v", record)
+	}
+	var args []any
+	for key, value := range record {
+		args = append(args, key, value)
+	}
+	if err := logging.EventHealthStateChanged.Check(args...); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+}
+
+func TestStateOnChange(t *testing.T) {
+	state := NewState(nil)
+	var changes []string
+	state.OnChange(func(previous Snapshot, current Snapshot) {
+		changes = append(changes, string(previous.State)+"->"+string(current.State)+": "+current.Reason)
+	})
+
+	state.Set(PhaseReady, "startup complete")
+	state.Set(PhaseReady, "reason only")
+	state.Set(PhaseDraining, "signal")
+
+	want := []string{"starting->ready: startup complete", "ready->draining: signal"}
+	if strings.Join(changes, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %v, got %v", want, changes)
+	}
+}
+
+func TestStateFollowOrder(t *testing.T) {
+	state := NewState(nil)
+	var phases []Phase
+	state.Follow(func(current Snapshot) {
+		phases = append(phases, current.State)
+	})
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				state.Set(PhaseReady, "ready")
+			} else {
+				state.Set(PhaseDegraded, "degraded")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if phases[0] != PhaseStarting {
+		t.Fatalf("expected initial phase first, got %v", phases)
+	}
+	for i := 1; i < len(phases); i++ {
+		if phases[i] == phases[i-1] {
+			t.Fatalf("expected each change once and in order, got %v", phases)
+		}
+	}
+	if phases[len(phases)-1] != state.Snapshot().State {
+		t.Fatalf("expected last notification %s, got %v", state.Snapshot().State, phases)
+	}
+}
+
+func TestStateObserve(t *testing.T) {
+	state := NewState(nil)
+	failingReport := Report{Checks: []CheckResult{
+		{Name: "database", Status: StatusOK, Critical: true},
+		{Name: "qrz", Status: StatusFailed, Error: "timeout"},
+	}}
+	passingReport := Report{Checks: []CheckResult{{Name: "qrz", Status: StatusOK}}}
+
+	state.Observe(failingReport)
+	if state.Snapshot().State != PhaseStarting {
+		t.Fatalf("observe should not leave starting: %+v", state.Snapshot())
+	}
+
+	state.Set(PhaseReady, "test")
+	state.Observe(failingReport)
+	snapshot := state.Snapshot()
+	if snapshot.State != PhaseDegraded || snapshot.Reason != "optional checks failing: qrz" || !state.IsReady() {
+		t.Fatalf("expected degraded: %+v", snapshot)
+	}
+
+	state.Observe(passingReport)
+	if state.Snapshot().State != PhaseReady {
+		t.Fatalf("expected recovery to ready: %+v", state.Snapshot())
+	}
+
+	state.Set(PhaseDraining, "shutdown")
+	state.Observe(failingReport)
+	if state.Snapshot().State != PhaseDraining || state.IsReady() {
+		t.Fatalf("observe should not leave draining: %+v", state.Snapshot())
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
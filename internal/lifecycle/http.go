@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// HTTP server components.
+// This file adapts an http.Server to a Component. The listener is bound (or
+// taken from the caller) in Start, so an address already in use fails
+// startup instead of leaving the process running without it; serve errors
+// afterwards are reported as fatal. A server with a TLSConfig serves TLS on
+// the listener. Stop shuts down gracefully and closes remaining connections
+// when the context expires.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// HTTP server components. {{{
+
+// ListenFunc returns the listener a server component accepts on.
+type ListenFunc func() (net.Listener, error)
+
+// HTTPServer returns a component that serves server on server.Addr.
+func (manager *Manager) HTTPServer(name string, server *http.Server, dependsOn ...string) Component {
+	listen := func() (net.Listener, error) {
+		return net.Listen("tcp", server.Addr)
+	}
+	return manager.HTTPServerListen(name, server, listen, dependsOn...)
+}
+
+// HTTPServerListen returns a component that serves server on the listener
+// from listen, such as one passed in by socket activation.
+func (manager *Manager) HTTPServerListen(name string, server *http.Server, listen ListenFunc, dependsOn ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func(context.Context) error {
+			listener, err := listen()
+			if err != nil {
+				return err
+			}
+			// Serve configures HTTP/2 on server.TLSConfig, so read it first.
+			secure := server.TLSConfig != nil
+			serve := server.Serve
+			if secure {
+				serve = func(listener net.Listener) error {
+					return server.ServeTLS(listener, "", "")
+				}
+			}
+			go func() {
+				if err := serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					manager.Fail(name, err)
+				}
+			}()
+			manager.logger.Info("listener started", "name", name, "address", listener.Addr().String(), "tls", secure)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			if err := server.Shutdown(ctx); err != nil {
+				return errors.Join(err, server.Close())
+			}
+			return nil
+		},
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Component lifecycle manager.
+// This file defines Manager, which starts server components (database,
+// listeners, integrations, plugins) in dependency order and stops them in
+// reverse. Start hooks return once the component is up; work that keeps
+// running reports fatal errors through Fail, which the caller watches via
+// Fatal to begin a clean shutdown. A failed start stops whatever already
+// started before returning.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Components. {{{
+
+// Hook starts or stops a component.
+type Hook func(ctx context.Context) error
+
+// Component is a unit the manager starts and stops.
+type Component struct {
+	Name      string   // Unique name used in logs and dependencies.
+	DependsOn []string // Components that must start first and stop after.
+	Start     Hook     // Brings the component up (nil for none).
+	Stop      Hook     // Shuts the component down (nil for none).
+}
+
+// FatalError reports a running component that failed.
+type FatalError struct {
+	Component string
+	Err       error
+}
+
+func (err *FatalError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", errtext.ErrLifecycleComponentFailed, err.Component, err.Err)
+}
+
+func (err *FatalError) Unwrap() error {
+	return err.Err
+}
+
+// }}}
+// Manager. {{{
+
+// Manager owns the component graph.
+type Manager struct {
+	mu         sync.Mutex
+	logger     *slog.Logger
+	components []Component
+	started    []Component
+	fatal      chan error
+	failOnce   sync.Once
+}
+
+// New returns an empty manager that logs to logger.
+func New(logger *slog.Logger) *Manager {
+	return &Manager{logger: logger, fatal: make(chan error, 1)}
+}
+
+// Add registers a component. Names must be unique; dependencies are checked
+// when Start orders the graph.
+func (manager *Manager) Add(component Component) error {
+	if component.Name == "" {
+		return fmt.Errorf("%s", errtext.ErrLifecycleNameRequired)
+	}
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	for _, existing := range manager.components {
+		if existing.Name == component.Name {
+			return fmt.Errorf("%s: %q", errtext.ErrLifecycleDuplicate, component.Name)
+		}
+	}
+	manager.components = append(manager.components, component)
+	return nil
+}
+
+// Start starts every component in dependency order. When one fails, the
+// components already started are stopped in reverse under ctx and the start
+// error is returned.
+func (manager *Manager) Start(ctx context.Context) error {
+	manager.mu.Lock()
+	order, err := sortComponents(manager.components)
+	manager.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, component := range order {
+		began := time.Now()
+		if component.Start != nil {
+			if err := component.Start(ctx); err != nil {
+				err = fmt.Errorf("%s: %s: %w", errtext.ErrLifecycleStartFailed, component.Name, err)
+				return errors.Join(err, manager.Stop(ctx))
+			}
+		}
+		manager.mu.Lock()
+		manager.started = append(manager.started, component)
+		manager.mu.Unlock()
+		manager.logger.Info("component started", "name", component.Name, "duration", time.Since(began).String())
+	}
+	return nil
+}
+
+// Stop stops started components in reverse start order. Every component is
+// stopped even when ctx expires or an earlier stop fails; the errors are
+// joined.
+func (manager *Manager) Stop(ctx context.Context) error {
+	manager.mu.Lock()
+	started := manager.started
+	manager.started = nil
+	manager.mu.Unlock()
+
+	var errs []error
+	for _, component := range slices.Backward(started) {
+		if component.Stop == nil {
+			continue
+		}
+		began := time.Now()
+		if err := component.Stop(ctx); err != nil {
+			err = fmt.Errorf("%s: %s: %w", errtext.ErrLifecycleStopFailed, component.Name, err)
+			manager.logger.Error(errtext.ErrLifecycleStopFailed, "name", component.Name, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		manager.logger.Info("component stopped", "name", component.Name, "duration", time.Since(began).String())
+	}
+	return errors.Join(errs...)
+}
+
+// Fail reports a fatal error from a running component. Only the first
+// failure is delivered on Fatal; later ones are logged.
+func (manager *Manager) Fail(name string, err error) {
+	fatal := &FatalError{Component: name, Err: err}
+	manager.logger.Error(errtext.ErrLifecycleComponentFailed, "name", name, "error", err)
+	manager.failOnce.Do(func() {
+		manager.fatal <- fatal
+	})
+}
+
+// Fatal delivers the first fatal component error.
+func (manager *Manager) Fatal() <-chan error {
+	return manager.fatal
+}
+
+// }}}
+// Dependency ordering. {{{
+
+// sortComponents orders components so dependencies come first, keeping
+// registration order among independent components.
+func sortComponents(components []Component) ([]Component, error) {
+	index := make(map[string]int, len(components))
+	for position, component := range components {
+		index[component.Name] = position
+	}
+	for _, component := range components {
+		for _, dependency := range component.DependsOn {
+			if _, ok := index[dependency]; !ok {
+				return nil, fmt.Errorf("%s: %s depends on %q", errtext.ErrLifecycleUnknownDependency, component.Name, dependency)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(components))
+	order := make([]Component, 0, len(components))
+	var visit func(position int, path []string) error
+	visit = func(position int, path []string) error {
+		component := components[position]
+		switch marks[position] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%s: %v", errtext.ErrLifecycleCycle, append(path, component.Name))
+		}
+		marks[position] = visiting
+		for _, dependency := range component.DependsOn {
+			if err := visit(index[dependency], append(path, component.Name)); err != nil {
+				return err
+			}
+		}
+		marks[position] = visited
+		order = append(order, component)
+		return nil
+	}
+	for position := range components {
+		if err := visit(position, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
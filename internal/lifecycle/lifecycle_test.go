@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Lifecycle manager tests.
+// This file verifies dependency ordering, reverse shutdown, rollback after
+// a failed start, graph errors, fatal error delivery, and HTTP server
+// components that fail to bind.
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Manager tests. {{{
+
+func newTestManager() *Manager {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// recorded returns a component that appends start and stop events to events.
+func recorded(events *[]string, name string, dependsOn ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func(context.Context) error {
+			*events = append(*events, "start "+name)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			*events = append(*events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestManagerOrder(t *testing.T) {
+	var events []string
+	manager := newTestManager()
+	for _, component := range []Component{
+		recorded(&events, "rest", "database", "plugins"),
+		recorded(&events, "plugins", "database"),
+		recorded(&events, "database"),
+		recorded(&events, "integrations"),
+	} {
+		if err := manager.Add(component); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := manager.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	expected := []string{
+		"start database", "start plugins", "start rest", "start integrations",
+		"stop integrations", "stop rest", "stop plugins", "stop database",
+	}
+	if !slices.Equal(events, expected) {
+		t.Fatalf("unexpected order:\n got %v\nwant %v", events, expected)
+	}
+}
+
+func TestManagerStartRollback(t *testing.T) {
+	var events []string
+	manager := newTestManager()
+	_ = manager.Add(recorded(&events, "database"))
+	_ = manager.Add(Component{
+		Name:      "rest",
+		DependsOn: []string{"database"},
+		Start:     func(context.Context) error { return errors.New("address in use") },
+		Stop:      func(context.Context) error { t.Fatal("a failed component must not be stopped"); return nil },
+	})
+	_ = manager.Add(recorded(&events, "plugins", "rest"))
+
+	err := manager.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), errtext.ErrLifecycleStartFailed) || !strings.Contains(err.Error(), "address in use") {
+		t.Fatalf("expected start failure, got %v", err)
+	}
+	if expected := []string{"start database", "stop database"}; !slices.Equal(events, expected) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+}
+
+func TestManagerGraphErrors(t *testing.T) {
+	manager := newTestManager()
+	_ = manager.Add(Component{Name: "a", DependsOn: []string{"b"}})
+	_ = manager.Add(Component{Name: "b", DependsOn: []string{"a"}})
+	if err := manager.Start(context.Background()); err == nil || !strings.Contains(err.Error(), errtext.ErrLifecycleCycle) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+
+	manager = newTestManager()
+	_ = manager.Add(Component{Name: "a", DependsOn: []string{"missing"}})
+	if err := manager.Start(context.Background()); err == nil || !strings.Contains(err.Error(), errtext.ErrLifecycleUnknownDependency) {
+		t.Fatalf("expected unknown dependency error, got %v", err)
+	}
+
+	if err := manager.Add(Component{Name: "a"}); err == nil || !strings.Contains(err.Error(), errtext.ErrLifecycleDuplicate) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	if err := manager.Add(Component{}); err == nil || !strings.Contains(err.Error(), errtext.ErrLifecycleNameRequired) {
+		t.Fatalf("expected name error, got %v", err)
+	}
+}
+
+func TestManagerFail(t *testing.T) {
+	manager := newTestManager()
+	cause := errors.New("connection lost")
+	manager.Fail("sync", cause)
+	manager.Fail("plugins", errors.New("later"))
+
+	select {
+	case err := <-manager.Fatal():
+		var fatal *FatalError
+		if !errors.As(err, &fatal) || fatal.Component != "sync" || !errors.Is(err, cause) {
+			t.Fatalf("unexpected fatal error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a fatal error")
+	}
+	select {
+	case err := <-manager.Fatal():
+		t.Fatalf("only the first failure should be delivered, got %v", err)
+	default:
+	}
+}
+
+// }}}
+// HTTP server component tests. {{{
+
+func TestHTTPServerBindFailure(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() {
+		_ = occupied.Close()
+	}()
+
+	manager := newTestManager()
+	_ = manager.Add(manager.HTTPServer("rest", &http.Server{Addr: occupied.Addr().String()}))
+	if err := manager.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "rest") {
+		t.Fatalf("expected bind failure, got %v", err)
+	}
+}
+
+func TestHTTPServerServeAndStop(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	address := probe.Addr().String()
+	_ = probe.Close()
+
+	manager := newTestManager()
+	server := &http.Server{
+		Addr: address,
+		Handler: http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			_, _ = writer.Write([]byte("ok\n"))
+		}),
+	}
+	_ = manager.Add(manager.HTTPServer("rest", server))
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	response, err := http.Get("http://" + address + "/")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := manager.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	http.DefaultClient.CloseIdleConnections()
+	if _, err := http.Get("http://" + address + "/"); err == nil {
+		t.Fatal("expected the listener to be closed")
+	}
+}
+
+func TestHTTPServerListen(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	manager := newTestManager()
+	server := &http.Server{Handler: http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte("ok\n"))
+	})}
+	listen := func() (net.Listener, error) { return listener, nil }
+	_ = manager.Add(manager.HTTPServerListen("rest", server, listen))
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer func() {
+		_ = manager.Stop(context.Background())
+	}()
+
+	response, err := http.Get("http://" + listener.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.StatusCode)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
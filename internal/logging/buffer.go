@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Recent-log ring buffer.
+// This file defines RingBuffer, a bounded in-memory store of recent log
+// records, and the slog.Handler tee that feeds it. Records are flattened into
+// JSON-friendly entries after redaction, so the buffer never holds secrets.
+// Readers can query a filtered snapshot or subscribe to new entries for a
+// follow mode; slow subscribers drop entries instead of blocking logging.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Ring buffer. {{{
+
+const subscriberBuffer = 256
+
+// Entry is a flattened log record kept in the ring buffer.
+type Entry struct {
+	Seq       uint64         `json:"seq"`
+	Time      time.Time      `json:"time"`
+	Level     string         `json:"level"`
+	Message   string         `json:"msg"`
+	Component string         `json:"component,omitempty"`
+	Attrs     map[string]any `json:"attrs,omitempty"`
+
+	level slog.Level
+}
+
+// LogFilter selects entries from the ring buffer.
+type LogFilter struct {
+	Component string       // Only entries for this component (empty matches all).
+	Level     slog.Leveler // Minimum level (nil matches all).
+	Limit     int          // Keep only the newest N matches (0 keeps all).
+	Since     time.Time    // Only entries at or after this time (zero matches all).
+}
+
+// Match reports whether entry passes the filter.
+func (filter LogFilter) Match(entry Entry) bool {
+	if filter.Level != nil && entry.level < filter.Level.Level() {
+		return false
+	}
+	if filter.Component != "" && entry.Component != filter.Component {
+		return false
+	}
+	if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+		return false
+	}
+	return true
+}
+
+// RingBuffer keeps the most recent log entries.
+type RingBuffer struct {
+	mu          sync.RWMutex
+	entries     []Entry
+	next        int
+	full        bool
+	seq         uint64
+	subscribers map[chan Entry]struct{}
+}
+
+// NewRingBuffer returns a buffer that keeps up to size entries.
+func NewRingBuffer(size int) *RingBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &RingBuffer{
+		entries:     make([]Entry, size),
+		subscribers: make(map[chan Entry]struct{}),
+	}
+}
+
+// Len returns the number of buffered entries.
+func (buffer *RingBuffer) Len() int {
+	buffer.mu.RLock()
+	defer buffer.mu.RUnlock()
+	if buffer.full {
+		return len(buffer.entries)
+	}
+	return buffer.next
+}
+
+// Entries returns buffered entries matching filter, oldest first.
+func (buffer *RingBuffer) Entries(filter LogFilter) []Entry {
+	buffer.mu.RLock()
+	defer buffer.mu.RUnlock()
+
+	var matches []Entry
+	collect := func(entries []Entry) {
+		for _, entry := range entries {
+			if filter.Match(entry) {
+				matches = append(matches, entry)
+			}
+		}
+	}
+	if buffer.full {
+		collect(buffer.entries[buffer.next:])
+	}
+	collect(buffer.entries[:buffer.next])
+
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[len(matches)-filter.Limit:]
+	}
+	return matches
+}
+
+// Subscribe returns a channel of new entries and a function that cancels the
+// subscription. Entries are dropped for a subscriber that falls behind.
+func (buffer *RingBuffer) Subscribe() (<-chan Entry, func()) {
+	channel := make(chan Entry, subscriberBuffer)
+
+	buffer.mu.Lock()
+	buffer.subscribers[channel] = struct{}{}
+	buffer.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			buffer.mu.Lock()
+			delete(buffer.subscribers, channel)
+			buffer.mu.Unlock()
+			close(channel)
+		})
+	}
+	return channel, cancel
+}
+
+// Handler returns a slog.Handler that records every record it receives.
+func (buffer *RingBuffer) Handler() slog.Handler {
+	return &ringHandler{buffer: buffer}
+}
+
+func (buffer *RingBuffer) add(entry Entry) {
+	buffer.mu.Lock()
+	defer buffer.mu.Unlock()
+
+	buffer.seq++
+	entry.Seq = buffer.seq
+	buffer.entries[buffer.next] = entry
+	buffer.next++
+	if buffer.next == len(buffer.entries) {
+		buffer.next = 0
+		buffer.full = true
+	}
+
+	for channel := range buffer.subscribers {
+		select {
+		case channel <- entry:
+		default:
+		}
+	}
+}
+
+// }}}
+// Ring buffer handler. {{{
+
+// ringFrame holds attributes added by WithAttrs under an open group path.
+type ringFrame struct {
+	groups []string
+	attrs  []slog.Attr
+}
+
+// ringHandler flattens records into entries for the ring buffer.
+type ringHandler struct {
+	buffer *RingBuffer
+	frames []ringFrame
+	groups []string
+}
+
+func (handler *ringHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (handler *ringHandler) Handle(_ context.Context, record slog.Record) error {
+	attrs := make(map[string]any)
+	for _, frame := range handler.frames {
+		target := groupMap(attrs, frame.groups)
+		for _, attr := range frame.attrs {
+			addEntryAttr(target, attr)
+		}
+	}
+	target := groupMap(attrs, handler.groups)
+	record.Attrs(func(attr slog.Attr) bool {
+		addEntryAttr(target, attr)
+		return true
+	})
+
+	entry := Entry{
+		Time:    record.Time,
+		Level:   LevelName(record.Level),
+		Message: record.Message,
+		level:   record.Level,
+	}
+	if component, ok := attrs[FieldComponent].(string); ok {
+		entry.Component = component
+	}
+	if len(attrs) > 0 {
+		entry.Attrs = attrs
+	}
+	handler.buffer.add(entry)
+	return nil
+}
+
+func (handler *ringHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *handler
+	clone.frames = append(append([]ringFrame(nil), handler.frames...), ringFrame{groups: handler.groups, attrs: attrs})
+	return &clone
+}
+
+func (handler *ringHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	clone := *handler
+	clone.groups = append(append([]string(nil), handler.groups...), name)
+	return &clone
+}
+
+func groupMap(root map[string]any, groups []string) map[string]any {
+	target := root
+	for _, group := range groups {
+		nested, ok := target[group].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			target[group] = nested
+		}
+		target = nested
+	}
+	return target
+}
+
+func addEntryAttr(target map[string]any, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		group := value.Group()
+		if len(group) == 0 {
+			return
+		}
+		nested := target
+		if attr.Key != "" {
+			nested = groupMap(target, []string{attr.Key})
+		}
+		for _, member := range group {
+			addEntryAttr(nested, member)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+	target[attr.Key] = entryValue(value)
+}
+
+// entryValue converts a value into a form that survives JSON encoding.
+func entryValue(value slog.Value) any {
+	switch value.Kind() {
+	case slog.KindDuration:
+		return value.Duration().String()
+	case slog.KindAny:
+		switch typed := value.Any().(type) {
+		case error:
+			return typed.Error()
+		case fmt.Stringer:
+			return typed.String()
+		default:
+			return typed
+		}
+	default:
+		return value.Any()
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Recent-log ring buffer tests.
+// This file verifies that the ring buffer keeps only the newest entries,
+// filters by level, component, time, and limit, delivers new entries to
+// subscribers, and flattens logger attributes and groups.
+
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// Recent-log ring buffer tests. {{{
+
+func TestRingBufferWrapsAndFilters(t *testing.T) {
+	buffer := NewRingBuffer(3)
+	logger := slog.New(buffer.Handler())
+
+	for index := range 5 {
+		logger.Info(fmt.Sprintf("record %d", index), FieldComponent, "sync")
+	}
+	logger.Warn("database warning", FieldComponent, "database")
+
+	if buffer.Len() != 3 {
+		t.Fatalf("expected 3 buffered entries, got %d", buffer.Len())
+	}
+	entries := buffer.Entries(LogFilter{})
+	if entries[0].Message != "record 3" || entries[2].Message != "database warning" {
+		t.Fatalf("unexpected buffered entries: %+v", entries)
+	}
+	if entries[0].Seq >= entries[1].Seq {
+		t.Fatalf("expected increasing sequence numbers, got %d and %d", entries[0].Seq, entries[1].Seq)
+	}
+
+	warnings := buffer.Entries(LogFilter{Level: slog.LevelWarn})
+	if len(warnings) != 1 || warnings[0].Component != "database" {
+		t.Fatalf("unexpected level filter result: %+v", warnings)
+	}
+	syncEntries := buffer.Entries(LogFilter{Component: "sync", Limit: 1})
+	if len(syncEntries) != 1 || syncEntries[0].Message != "record 4" {
+		t.Fatalf("unexpected component filter result: %+v", syncEntries)
+	}
+	if future := buffer.Entries(LogFilter{Since: time.Now().Add(time.Hour)}); len(future) != 0 {
+		t.Fatalf("expected no entries in the future, got %d", len(future))
+	}
+}
+
+func TestRingBufferSubscribe(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	logger := slog.New(buffer.Handler())
+
+	updates, cancel := buffer.Subscribe()
+	logger.Info("streamed")
+	select {
+	case entry := <-updates:
+		if entry.Message != "streamed" {
+			t.Fatalf("unexpected streamed entry: %+v", entry)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected streamed entry")
+	}
+
+	cancel()
+	cancel()
+	logger.Info("after cancel")
+	if _, ok := <-updates; ok {
+		t.Fatal("expected subscription channel to be closed")
+	}
+}
+
+func TestRingHandlerAttrsAndGroups(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	logger := slog.New(buffer.Handler()).With(FieldComponent, "server").WithGroup("request")
+
+	logger.Info("handled", "path", "/readyz", slog.Group("timing", "elapsed", 5*time.Millisecond))
+
+	entries := buffer.Entries(LogFilter{})
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Component != "server" {
+		t.Fatalf("expected server component, got %q", entry.Component)
+	}
+	request, ok := entry.Attrs["request"].(map[string]any)
+	if !ok || request["path"] != "/readyz" {
+		t.Fatalf("unexpected request group: %+v", entry.Attrs)
+	}
+	timing, ok := request["timing"].(map[string]any)
+	if !ok || timing["elapsed"] != "5ms" {
+		t.Fatalf("unexpected timing group: %+v", request)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
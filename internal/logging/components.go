@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Log component registry.
+// This file defines the registry of valid `component` field values. Core
+// components are registered at init; plugins and new subsystems register
+// namespaced components such as `plugin:contest-scorer` before they log.
+// Registration fails on conflicts, and the registry can be listed for
+// per-component levels and log filtering. Config may name a namespaced
+// component that registers later, since plugins load after the logger.
+
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Component registry. {{{
+
+const (
+	ComponentNamespacePlugin = "plugin"
+	componentSeparator       = ":"
+)
+
+var componentNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ComponentInfo describes a registered component.
+type ComponentInfo struct {
+	Name        Component `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Core        bool      `json:"core,omitempty"`
+}
+
+var (
+	componentsMu sync.RWMutex
+	components   = map[Component]ComponentInfo{}
+)
+
+func init() {
+	for component, description := range map[Component]string{
+		ComponentAuth:         "authentication and device pairing",
+		ComponentCLI:          "bms command line client",
+		ComponentConfig:       "configuration loading and validation",
+		ComponentDatabase:     "database access and migrations",
+		ComponentGRPC:         "gRPC listener",
+		ComponentIntegrations: "external integrations (Clublog, LoTW, QRZ)",
+		ComponentPlugins:      "plugin host",
+		ComponentREST:         "REST listener",
+		ComponentServer:       "bmsd process lifecycle",
+		ComponentSync:         "replication and sync",
+		ComponentTelemetry:    "telemetry reporting",
+		ComponentWebsocket:    "WebSocket listener",
+	} {
+		components[component] = ComponentInfo{Name: component, Description: description, Core: true}
+	}
+}
+
+// RegisterComponent registers a namespaced component (`namespace:name`). It
+// fails when the name is malformed or already registered.
+func RegisterComponent(component Component, description string) error {
+	if err := checkNamespacedComponent(component); err != nil {
+		return err
+	}
+
+	componentsMu.Lock()
+	defer componentsMu.Unlock()
+	if _, exists := components[component]; exists {
+		return fmt.Errorf("%s: %q", errtext.ErrLogComponentConflict, component)
+	}
+	components[component] = ComponentInfo{Name: component, Description: description}
+	return nil
+}
+
+// PluginComponent returns the namespaced component for a plugin name.
+func PluginComponent(name string) Component {
+	return Component(ComponentNamespacePlugin + componentSeparator + name)
+}
+
+// ValidComponent reports whether the component is registered.
+func ValidComponent(component Component) bool {
+	componentsMu.RLock()
+	defer componentsMu.RUnlock()
+	_, ok := components[component]
+	return ok
+}
+
+// RegisteredComponents returns every registered component sorted by name.
+func RegisteredComponents() []ComponentInfo {
+	componentsMu.RLock()
+	defer componentsMu.RUnlock()
+
+	list := make([]ComponentInfo, 0, len(components))
+	for _, info := range components {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// }}}
+// Component helpers. {{{
+
+// configurableComponent reports whether config may refer to the component:
+// it is registered, or it is a well-formed namespaced name that may register
+// once its plugin loads.
+func configurableComponent(component Component) bool {
+	return ValidComponent(component) || checkNamespacedComponent(component) == nil
+}
+
+func checkNamespacedComponent(component Component) error {
+	namespace, name, ok := strings.Cut(string(component), componentSeparator)
+	if !ok {
+		if componentNamePattern.MatchString(string(component)) {
+			return fmt.Errorf("%s: %q", errtext.ErrLogComponentNamespace, component)
+		}
+		return fmt.Errorf("%s: %q", errtext.ErrInvalidLogComponent, component)
+	}
+	if !componentNamePattern.MatchString(namespace) || !componentNamePattern.MatchString(name) {
+		return fmt.Errorf("%s: %q", errtext.ErrInvalidLogComponent, component)
+	}
+	return nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
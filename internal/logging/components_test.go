@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Log component registry tests.
+// This file verifies that core components are pre-registered, that plugins
+// register namespaced components, that conflicts and malformed names are
+// rejected, and that config may name plugin components before they register.
+
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Log component registry tests. {{{
+
+func TestRegisterComponent(t *testing.T) {
+	if !ValidComponent(ComponentDatabase) {
+		t.Fatalf("expected core component %q to be registered", ComponentDatabase)
+	}
+
+	scorer := PluginComponent("contest-scorer")
+	if ValidComponent(scorer) {
+		t.Fatalf("expected %q to be unregistered", scorer)
+	}
+	if err := RegisterComponent(scorer, "contest scoring plugin"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !ValidComponent(scorer) {
+		t.Fatalf("expected %q to be registered", scorer)
+	}
+
+	cases := map[Component]string{
+		scorer:              errtext.ErrLogComponentConflict,
+		ComponentDatabase:   errtext.ErrLogComponentNamespace,
+		"cluster":           errtext.ErrLogComponentNamespace,
+		"plugin:":           errtext.ErrInvalidLogComponent,
+		"Plugin:Scorer":     errtext.ErrInvalidLogComponent,
+		"plugin:a:b":        errtext.ErrInvalidLogComponent,
+		"plugin:with space": errtext.ErrInvalidLogComponent,
+	}
+	for component, want := range cases {
+		err := RegisterComponent(component, "")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q for %q, got: %v", want, component, err)
+		}
+	}
+
+	var found bool
+	list := RegisteredComponents()
+	for index, info := range list {
+		if index > 0 && list[index-1].Name >= info.Name {
+			t.Fatalf("expected components sorted by name, got %q before %q", list[index-1].Name, info.Name)
+		}
+		if info.Name == scorer {
+			found = !info.Core && info.Description == "contest scoring plugin"
+		}
+	}
+	if !found {
+		t.Fatalf("expected %q in the registry listing, got: %+v", scorer, list)
+	}
+}
+
+func TestNewRuntimeAcceptsPendingPluginComponents(t *testing.T) {
+	cfg := config.LoggingConfig{
+		Components: map[string]config.LogLevel{"plugin:late-loader": config.LogLevelDebug},
+		Level:      config.LogLevelInfo,
+		Format:     config.LogFormatJSON,
+	}
+	if _, err := NewRuntime(cfg, LoggerDefaults{}); err != nil {
+		t.Fatalf("expected namespaced component to be accepted before registration, got: %v", err)
+	}
+
+	cfg.Components = map[string]config.LogLevel{"late-loader": config.LogLevelDebug}
+	if _, err := NewRuntime(cfg, LoggerDefaults{}); err == nil {
+		t.Fatalf("expected unnamespaced unknown component to be rejected")
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
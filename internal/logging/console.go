@@ -0,0 +1,368 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Console log format.
+// This file defines the `console` format, a slog.Handler meant for people
+// reading a terminal: aligned timestamps, colored levels, a component prefix,
+// and struct, map, and slice attributes pretty-printed over several lines.
+// Colors follow the NO_COLOR convention, and when the writer is not a
+// terminal (piped or redirected) the format falls back to `text`.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Console handler. {{{
+
+const (
+	consoleTimeFormat = "15:04:05.000"
+	consoleIndent     = "    "
+
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiYellow  = "\x1b[33m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+	ansiGray    = "\x1b[90m"
+)
+
+// consoleHandler renders records for interactive terminals.
+type consoleHandler struct {
+	mu        *sync.Mutex
+	writer    io.Writer
+	level     slog.Leveler
+	color     bool
+	component string
+	attrs     []slog.Attr
+	groups    []string
+}
+
+// newConsoleHandler returns a console handler for terminals and a text
+// handler otherwise. NO_COLOR disables colors but keeps the layout.
+func newConsoleHandler(writer io.Writer, level slog.Leveler) slog.Handler {
+	if !isTerminal(writer) {
+		return slog.NewTextHandler(writer, &slog.HandlerOptions{Level: level})
+	}
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return &consoleHandler{mu: &sync.Mutex{}, writer: writer, level: level, color: !noColor}
+}
+
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (handler *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.level.Level()
+}
+
+func (handler *consoleHandler) Handle(_ context.Context, record slog.Record) error {
+	component := handler.component
+	var inline, blocks []slog.Attr
+	collect := func(attr slog.Attr) {
+		if len(handler.groups) == 0 && attr.Key == FieldComponent {
+			component = attr.Value.Resolve().String()
+			return
+		}
+		for _, flat := range flattenAttr(handler.groups, attr) {
+			if isBlockValue(flat.Value) {
+				blocks = append(blocks, flat)
+			} else {
+				inline = append(inline, flat)
+			}
+		}
+	}
+	for _, attr := range handler.attrs {
+		if isBlockValue(attr.Value) {
+			blocks = append(blocks, attr)
+		} else {
+			inline = append(inline, attr)
+		}
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		collect(attr)
+		return true
+	})
+
+	var builder strings.Builder
+	if !record.Time.IsZero() {
+		builder.WriteString(handler.paint(ansiDim, record.Time.Format(consoleTimeFormat)))
+		builder.WriteByte(' ')
+	}
+	builder.WriteString(handler.paint(levelColor(record.Level), fmt.Sprintf("%-5s", record.Level.String())))
+	builder.WriteByte(' ')
+	if component != "" {
+		builder.WriteString(handler.paint(ansiMagenta, "["+component+"]"))
+		builder.WriteByte(' ')
+	}
+	builder.WriteString(handler.paint(ansiBold, record.Message))
+	for _, attr := range inline {
+		builder.WriteByte(' ')
+		builder.WriteString(handler.paint(ansiDim, attr.Key+"="))
+		builder.WriteString(consoleScalar(attr.Value))
+	}
+	builder.WriteByte('\n')
+	for _, attr := range blocks {
+		builder.WriteString(consoleIndent)
+		builder.WriteString(handler.paint(ansiDim, attr.Key+":"))
+		builder.WriteByte('\n')
+		writePretty(&builder, reflect.ValueOf(attr.Value.Any()), consoleIndent+consoleIndent, 0)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	_, err := io.WriteString(handler.writer, builder.String())
+	return err
+}
+
+func (handler *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *handler
+	clone.attrs = append([]slog.Attr(nil), handler.attrs...)
+	for _, attr := range attrs {
+		if len(handler.groups) == 0 && attr.Key == FieldComponent {
+			clone.component = attr.Value.Resolve().String()
+			continue
+		}
+		clone.attrs = append(clone.attrs, flattenAttr(handler.groups, attr)...)
+	}
+	return &clone
+}
+
+func (handler *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	clone := *handler
+	clone.groups = append(append([]string(nil), handler.groups...), name)
+	return &clone
+}
+
+func (handler *consoleHandler) paint(color string, text string) string {
+	if !handler.color || text == "" {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return ansiRed
+	case level >= slog.LevelWarn:
+		return ansiYellow
+	case level >= slog.LevelInfo:
+		return ansiCyan
+	default:
+		return ansiGray
+	}
+}
+
+// }}}
+// Console value formatting. {{{
+
+// flattenAttr resolves an attribute and expands groups into dotted keys.
+func flattenAttr(groups []string, attr slog.Attr) []slog.Attr {
+	value := attr.Value.Resolve()
+	key := attr.Key
+	if len(groups) > 0 && key != "" {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	if value.Kind() != slog.KindGroup {
+		if attr.Key == "" {
+			return nil
+		}
+		return []slog.Attr{{Key: key, Value: value}}
+	}
+
+	nested := groups
+	if attr.Key != "" {
+		nested = append(append([]string(nil), groups...), attr.Key)
+	}
+	var flat []slog.Attr
+	for _, member := range value.Group() {
+		flat = append(flat, flattenAttr(nested, member)...)
+	}
+	return flat
+}
+
+// isBlockValue reports whether a value is printed on its own lines.
+func isBlockValue(value slog.Value) bool {
+	if value.Kind() != slog.KindAny {
+		return false
+	}
+	switch value.Any().(type) {
+	case nil, error, fmt.Stringer, []byte:
+		return false
+	}
+	reflected := reflect.ValueOf(value.Any())
+	for reflected.Kind() == reflect.Pointer && !reflected.IsNil() {
+		reflected = reflected.Elem()
+	}
+	switch reflected.Kind() {
+	case reflect.Struct, reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		return reflected.Len() > 0
+	}
+	return false
+}
+
+func consoleScalar(value slog.Value) string {
+	switch value.Kind() {
+	case slog.KindString:
+		return quoteIfNeeded(value.String())
+	case slog.KindTime:
+		return value.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return quoteIfNeeded(err.Error())
+		}
+	}
+	return quoteIfNeeded(value.String())
+}
+
+func quoteIfNeeded(text string) string {
+	if text == "" {
+		return `""`
+	}
+	for _, char := range text {
+		if unicode.IsSpace(char) || char == '"' || char == '=' || !unicode.IsPrint(char) {
+			return strconv.Quote(text)
+		}
+	}
+	return text
+}
+
+// writePretty writes value as an indented key: value tree. Struct fields use
+// their toml or json tag names so output matches the config file layout.
+func writePretty(builder *strings.Builder, value reflect.Value, indent string, depth int) {
+	value = prettyElem(value)
+	if depth > maxRedactDepth || !isPrettyContainer(value) {
+		builder.WriteString(indent + prettyScalar(value) + "\n")
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		valueType := value.Type()
+		for index := range value.NumField() {
+			field := valueType.Field(index)
+			if field.IsExported() {
+				writePrettyEntry(builder, structFieldKey(field)+":", value.Field(index), indent, depth)
+			}
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, key := range keys {
+			writePrettyEntry(builder, fmt.Sprint(key)+":", value.MapIndex(key), indent, depth)
+		}
+	case reflect.Slice, reflect.Array:
+		for index := range value.Len() {
+			writePrettyEntry(builder, "-", value.Index(index), indent, depth)
+		}
+	}
+}
+
+func writePrettyEntry(builder *strings.Builder, label string, value reflect.Value, indent string, depth int) {
+	value = prettyElem(value)
+	if isPrettyContainer(value) && !prettyEmpty(value) {
+		builder.WriteString(indent + label + "\n")
+		writePretty(builder, value, indent+"  ", depth+1)
+		return
+	}
+	builder.WriteString(indent + label + " " + prettyScalar(value) + "\n")
+}
+
+// prettyElem dereferences pointers and interfaces.
+func prettyElem(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value
+}
+
+func isPrettyContainer(value reflect.Value) bool {
+	if !value.IsValid() {
+		return false
+	}
+	if value.CanInterface() {
+		switch value.Interface().(type) {
+		case error, fmt.Stringer, time.Time:
+			return false
+		}
+	}
+	switch value.Kind() {
+	case reflect.Struct, reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		return value.Type().Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+func prettyEmpty(value reflect.Value) bool {
+	if value.Kind() == reflect.Struct {
+		return value.NumField() == 0
+	}
+	return value.Len() == 0
+}
+
+func prettyScalar(value reflect.Value) string {
+	if !value.IsValid() {
+		return "null"
+	}
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return "null"
+		}
+	case reflect.Struct:
+		if isPrettyContainer(value) && prettyEmpty(value) {
+			return "{}"
+		}
+	case reflect.Map:
+		if value.Len() == 0 {
+			return "{}"
+		}
+	case reflect.Slice, reflect.Array:
+		if isPrettyContainer(value) && value.Len() == 0 {
+			return "[]"
+		}
+	}
+	if !value.CanInterface() {
+		return quoteIfNeeded(fmt.Sprint(value))
+	}
+	switch typed := value.Interface().(type) {
+	case error:
+		return quoteIfNeeded(typed.Error())
+	case fmt.Stringer:
+		return quoteIfNeeded(typed.String())
+	case string:
+		return quoteIfNeeded(typed)
+	}
+	return quoteIfNeeded(fmt.Sprint(value.Interface()))
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Console log format tests.
+// This file verifies the console layout (level, component prefix, inline
+// attributes, pretty-printed structs), color handling, and the fallback to
+// text output when the writer is not a terminal.
+
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Console log format tests. {{{
+
+type consoleSample struct {
+	Name    string            `toml:"name"`
+	Ports   []int             `toml:"ports"`
+	Labels  map[string]string `toml:"labels"`
+	Enabled bool
+}
+
+func newTestConsoleHandler(output *bytes.Buffer, color bool) slog.Handler {
+	return &consoleHandler{mu: &sync.Mutex{}, writer: output, level: slog.LevelInfo, color: color}
+}
+
+func TestConsoleHandlerLayout(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(newTestConsoleHandler(&output, false)).With(FieldComponent, "config")
+
+	logger.Debug("hidden")
+	logger.WithGroup("request").Info("config loaded", "path", "/etc/bms/config.toml", "note", "two words", "config", consoleSample{
+		Name:   "bms",
+		Ports:  []int{8080, 9090},
+		Labels: map[string]string{"b": "2", "a": "1"},
+	})
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	header := lines[0]
+	for _, expected := range []string{"INFO  [config] config loaded", "request.path=/etc/bms/config.toml", `request.note="two words"`} {
+		if !strings.Contains(header, expected) {
+			t.Fatalf("expected %q in header line, got: %q", expected, header)
+		}
+	}
+	if strings.Contains(header, "hidden") || strings.Contains(header, "\x1b[") {
+		t.Fatalf("unexpected header line: %q", header)
+	}
+
+	block := strings.Join(lines[1:], "\n")
+	expected := strings.Join([]string{
+		"    request.config:",
+		"        name: bms",
+		"        ports:",
+		"          - 8080",
+		"          - 9090",
+		"        labels:",
+		"          a: 1",
+		"          b: 2",
+		"        Enabled: false",
+	}, "\n")
+	if block != expected {
+		t.Fatalf("unexpected struct block:\n%s\nexpected:\n%s", block, expected)
+	}
+}
+
+func TestConsoleHandlerColor(t *testing.T) {
+	var output bytes.Buffer
+	slog.New(newTestConsoleHandler(&output, true)).Error("failed")
+	if !strings.Contains(output.String(), ansiRed+"ERROR"+ansiReset) {
+		t.Fatalf("expected colored level, got: %q", output.String())
+	}
+}
+
+func TestConsoleHandlerFallsBackToText(t *testing.T) {
+	var output bytes.Buffer
+	handler := newConsoleHandler(&output, slog.LevelInfo)
+	if _, ok := handler.(*slog.TextHandler); !ok {
+		t.Fatalf("expected text handler for a non-terminal writer, got %T", handler)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
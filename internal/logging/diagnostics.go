@@ -10,6 +10,7 @@
 package logging
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/SandorMiskey/bms-core/internal/config"
@@ -19,19 +20,12 @@ import (
 // This block defines LogConfigDiagnostics, which emits config_loaded and
 // config_warnings events for startup diagnostics.
 
-const (
-	eventConfigLoaded   = "config_loaded"
-	eventConfigWarnings = "config_warnings"
-)
-
 // LogConfigDiagnostics emits startup diagnostics for a resolved config.
 func LogConfigDiagnostics(logger *slog.Logger, format config.LogFormat, cfg config.Config, path string, warnings config.WarningList) {
 	redacted := config.RedactConfig(cfg)
 
-	logger.Info(
-		"config loaded",
-		FieldComponent, string(ComponentConfig),
-		FieldEvent, eventConfigLoaded,
+	EventConfigLoaded.Log(
+		context.Background(), logger,
 		FieldConfigPath, path,
 		FieldRedacted, true,
 		FieldWarningsCount, len(warnings),
@@ -42,10 +36,8 @@ func LogConfigDiagnostics(logger *slog.Logger, format config.LogFormat, cfg conf
 		return
 	}
 
-	logger.Warn(
-		"config warnings",
-		FieldComponent, string(ComponentConfig),
-		FieldEvent, eventConfigWarnings,
+	EventConfigWarnings.Log(
+		context.Background(), logger,
 		FieldWarningsCount, len(warnings),
 		"warnings", formatWarnings(format, warnings),
 	)
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Structured log event catalog.
+// This file defines Event, a typed description of a structured log event
+// (name, component, level, message, required attributes), and the registry
+// that lists every event the binaries emit. Event names are a stable contract
+// for alerting, so they are declared here once and emitted through Event.Log;
+// tests use Event.Check to assert that a call carries the required attributes.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Event catalog. {{{
+
+// Event describes a structured log event.
+type Event struct {
+	Name        string     // Stable value of the `event` field.
+	Component   Component  // Component of the emitting logger (empty when it varies).
+	Level       slog.Level // Level the event is logged at.
+	Message     string     // Log message.
+	Attrs       []string   // Attribute keys every record must carry.
+	Description string     // One-line explanation for operators.
+}
+
+var (
+	eventsMu sync.RWMutex
+	events   = make(map[string]Event)
+)
+
+var (
+	EventConfigLoaded = RegisterEvent(Event{
+		Name:        "config_loaded",
+		Level:       slog.LevelInfo,
+		Message:     "config loaded",
+		Attrs:       []string{FieldConfigPath, FieldRedacted, FieldWarningsCount, "config"},
+		Description: "Configuration was resolved; carries the redacted config.",
+	})
+	EventConfigWarnings = RegisterEvent(Event{
+		Name:        "config_warnings",
+		Level:       slog.LevelWarn,
+		Message:     "config warnings",
+		Attrs:       []string{FieldWarningsCount, "warnings"},
+		Description: "Configuration resolved with non-fatal warnings.",
+	})
+	EventHealthStateChanged = RegisterEvent(Event{
+		Name:        "health_state_changed",
+		Component:   ComponentServer,
+		Level:       slog.LevelInfo,
+		Message:     "health state changed",
+		Attrs:       []string{FieldHealthState, FieldHealthPrevious, FieldReason},
+		Description: "The lifecycle state moved (starting, ready, degraded, draining, stopped); carries the reason.",
+	})
+	EventLogSuppressed = RegisterEvent(Event{
+		Name:        "log_suppressed",
+		Level:       slog.LevelWarn,
+		Message:     "suppressed %d similar records",
+		Attrs:       []string{FieldSuppressedCount, FieldSuppressedReason},
+		Description: "Records were dropped by deduplication or rate limiting; %d is the count, and duplicates are reported at their original level.",
+	})
+)
+
+// RegisterEvent adds an event to the catalog and returns it. It panics on an
+// empty or duplicate name, so registration belongs in package variables.
+func RegisterEvent(event Event) Event {
+	if event.Name == "" {
+		panic("logging: event name is required")
+	}
+
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	if _, exists := events[event.Name]; exists {
+		panic(fmt.Sprintf("logging: event %q registered twice", event.Name))
+	}
+	events[event.Name] = event
+	return event
+}
+
+// LookupEvent returns the registered event with the name.
+func LookupEvent(name string) (Event, bool) {
+	eventsMu.RLock()
+	defer eventsMu.RUnlock()
+	event, ok := events[name]
+	return event, ok
+}
+
+// Events returns the catalog sorted by event name.
+func Events() []Event {
+	eventsMu.RLock()
+	defer eventsMu.RUnlock()
+
+	list := make([]Event, 0, len(events))
+	for _, event := range events {
+		list = append(list, event)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// }}}
+// Event emission. {{{
+
+// Log emits the event with its name, level, and message followed by args
+// (key-value pairs or slog.Attr values, as for slog.Logger.Log). The
+// component field comes from the logger, so records carry it only once.
+func (event Event) Log(ctx context.Context, logger *slog.Logger, args ...any) {
+	if !logger.Enabled(ctx, event.Level) {
+		return
+	}
+
+	logger.Log(ctx, event.Level, event.Message, append([]any{FieldEvent, event.Name}, args...)...)
+}
+
+// Check reports the required attribute keys absent from args, which are
+// given as for Log.
+func (event Event) Check(args ...any) error {
+	record := slog.NewRecord(time.Time{}, event.Level, event.Message, 0)
+	record.Add(args...)
+
+	present := make(map[string]bool, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		present[attr.Key] = true
+		return true
+	})
+
+	var missing []string
+	for _, key := range event.Attrs {
+		if !present[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s: %s: %s", errtext.ErrLogEventAttrs, event.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Structured log event tests.
+// This file verifies that the event catalog is sorted and rejects duplicate
+// names, that Event.Log attaches the event fields, and that Event.Check
+// reports missing required attributes.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Structured log event tests. {{{
+
+func TestEventsCatalog(t *testing.T) {
+	list := Events()
+	for index := 1; index < len(list); index++ {
+		if list[index-1].Name >= list[index].Name {
+			t.Fatalf("expected events sorted by name, got %q before %q", list[index-1].Name, list[index].Name)
+		}
+	}
+	if _, ok := LookupEvent(EventConfigLoaded.Name); !ok {
+		t.Fatalf("expected %q in the catalog", EventConfigLoaded.Name)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected duplicate registration to panic")
+		}
+	}()
+	RegisterEvent(Event{Name: EventConfigLoaded.Name})
+}
+
+func TestEventLog(t *testing.T) {
+	buffer := NewRingBuffer(10)
+	var output bytes.Buffer
+	handler := combineHandlers(buffer.Handler(), slog.NewJSONHandler(&output, nil))
+	logger := slog.New(handler).With(FieldComponent, string(ComponentServer))
+
+	EventConfigWarnings.Log(context.Background(), logger, FieldWarningsCount, 2, slog.String("warnings", "a; b"))
+	entries := buffer.Entries(LogFilter{})
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Message != EventConfigWarnings.Message || entry.Level != "warn" || entry.Component != string(ComponentServer) {
+		t.Fatalf("unexpected event entry: %+v", entry)
+	}
+	if entry.Attrs[FieldEvent] != EventConfigWarnings.Name {
+		t.Fatalf("expected event field %q, got: %+v", EventConfigWarnings.Name, entry.Attrs)
+	}
+	if count := strings.Count(output.String(), `"`+FieldComponent+`":`); count != 1 {
+		t.Fatalf("expected one component field, got %d: %s", count, output.String())
+	}
+
+	if err := EventConfigWarnings.Check(FieldWarningsCount, 2, slog.String("warnings", "a; b")); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	err := EventConfigWarnings.Check(FieldWarningsCount, 2)
+	if err == nil || !strings.Contains(err.Error(), errtext.ErrLogEventAttrs) || !strings.HasSuffix(err.Error(), ": warnings") {
+		t.Fatalf("expected missing warnings attribute, got: %v", err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -4,7 +4,8 @@
 // Logging field definitions.
 // This file defines canonical field keys and component identifiers used in
 // structured logging and diagnostics so log output is consistent across
-// packages and environments.
+// packages and environments. Components are validated through the registry
+// in components.go, which pre-registers the core identifiers below.
 
 package logging
 
@@ -17,9 +18,18 @@ const (
 	FieldTraceID       = "trace_id"
 	FieldServerID      = "server_id"
 	FieldEnvironment   = "environment"
+	FieldVersion       = "version"
 	FieldConfigPath    = "config_path"
 	FieldWarningsCount = "warnings_count"
 	FieldRedacted      = "redacted"
+	FieldReason        = "reason"
+
+	FieldHealthState    = "state"
+	FieldHealthPrevious = "previous_state"
+
+	FieldSuppressedCount   = "suppressed_count"
+	FieldSuppressedMessage = "suppressed_msg"
+	FieldSuppressedReason  = "suppressed_reason"
 )
 
 type Component string
@@ -39,26 +49,6 @@ const (
 	ComponentWebsocket    Component = "websocket"
 )
 
-func ValidComponent(component Component) bool {
-	switch component {
-	case ComponentAuth,
-		ComponentCLI,
-		ComponentConfig,
-		ComponentDatabase,
-		ComponentGRPC,
-		ComponentIntegrations,
-		ComponentPlugins,
-		ComponentREST,
-		ComponentServer,
-		ComponentSync,
-		ComponentTelemetry,
-		ComponentWebsocket:
-		return true
-	default:
-		return false
-	}
-}
-
 // }}}
 
 // vim: set ts=4 sw=4 noet:
@@ -0,0 +1,346 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Runtime log levels.
+// This file defines Levels, which backs the base log level and optional
+// per-component levels with slog.LevelVar so verbosity can change while the
+// process runs. A change may carry a TTL, after which the level reverts to
+// its configured value. A level handler applies the effective level for the
+// component attached to each logger or record.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Levels. {{{
+
+// Levels tracks the base level and per-component overrides.
+type Levels struct {
+	mu         sync.RWMutex
+	base       *levelEntry
+	components map[Component]*levelEntry
+	minimum    atomic.Int64
+	now        func() time.Time
+}
+
+type levelEntry struct {
+	level      slog.LevelVar
+	configured *slog.Level
+	expires    time.Time
+	timer      *time.Timer
+	generation uint64
+}
+
+// LevelSnapshot reports the current levels for display and the admin API.
+type LevelSnapshot struct {
+	Level      string                    `json:"level"`
+	Expires    *time.Time                `json:"expires,omitempty"`
+	Components map[string]ComponentLevel `json:"components,omitempty"`
+}
+
+// ComponentLevel reports a single component override.
+type ComponentLevel struct {
+	Level   string     `json:"level"`
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// EffectiveLevel reports the level that applies to one component.
+type EffectiveLevel struct {
+	Component string     `json:"component"`
+	Level     string     `json:"level"`             // Level records of the component are filtered at.
+	Override  bool       `json:"override"`          // The component has its own level; otherwise the base applies.
+	Expires   *time.Time `json:"expires,omitempty"` // When that level reverts.
+}
+
+// NewLevels returns levels seeded with the configured base and component levels.
+func NewLevels(base slog.Level, components map[Component]slog.Level) *Levels {
+	levels := &Levels{
+		base:       newLevelEntry(base),
+		components: make(map[Component]*levelEntry, len(components)),
+		now:        time.Now,
+	}
+	for component, level := range components {
+		levels.components[component] = newLevelEntry(level)
+	}
+	levels.updateMinimum()
+	return levels
+}
+
+func newLevelEntry(level slog.Level) *levelEntry {
+	entry := &levelEntry{configured: &level}
+	entry.level.Set(level)
+	return entry
+}
+
+// Level returns the base level.
+func (levels *Levels) Level() slog.Level {
+	return levels.base.level.Level()
+}
+
+// Effective returns the level that applies to records of the component.
+func (levels *Levels) Effective(component Component) slog.Level {
+	if component != "" {
+		levels.mu.RLock()
+		entry, ok := levels.components[component]
+		levels.mu.RUnlock()
+		if ok {
+			return entry.level.Level()
+		}
+	}
+	return levels.base.level.Level()
+}
+
+// Minimum returns the lowest level any component currently accepts.
+func (levels *Levels) Minimum() slog.Level {
+	return slog.Level(levels.minimum.Load())
+}
+
+// Set changes the level for a component, or the base level when component is
+// empty. A positive ttl reverts the change to the configured level after the
+// duration elapses; a zero ttl makes the change stick until the next restart.
+func (levels *Levels) Set(component Component, level slog.Level, ttl time.Duration) error {
+	if component != "" && !ValidComponent(component) {
+		return fmt.Errorf("%s: %q", errtext.ErrInvalidLogComponent, component)
+	}
+	if ttl < 0 {
+		return fmt.Errorf("%s: %s", errtext.ErrInvalidLevelTTL, ttl)
+	}
+
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	entry := levels.base
+	if component != "" {
+		var ok bool
+		entry, ok = levels.components[component]
+		if !ok {
+			entry = &levelEntry{}
+			levels.components[component] = entry
+		}
+	}
+
+	entry.stopTimer()
+	entry.level.Set(level)
+	if ttl > 0 {
+		entry.expires = levels.now().Add(ttl)
+		entry.generation++
+		generation := entry.generation
+		entry.timer = time.AfterFunc(ttl, func() {
+			levels.revert(component, entry, generation)
+		})
+	}
+	levels.updateMinimum()
+	return nil
+}
+
+// Snapshot returns the current levels and pending expirations.
+func (levels *Levels) Snapshot() LevelSnapshot {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	snapshot := LevelSnapshot{
+		Level:   LevelName(levels.base.level.Level()),
+		Expires: levels.base.expiry(),
+	}
+	if len(levels.components) > 0 {
+		snapshot.Components = make(map[string]ComponentLevel, len(levels.components))
+		for component, entry := range levels.components {
+			snapshot.Components[string(component)] = ComponentLevel{
+				Level:   LevelName(entry.level.Level()),
+				Expires: entry.expiry(),
+			}
+		}
+	}
+	return snapshot
+}
+
+// Lookup returns the level in effect for the component, which is its
+// override when one is set and the base level otherwise.
+func (levels *Levels) Lookup(component Component) EffectiveLevel {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	entry, ok := levels.components[component]
+	if !ok {
+		entry = levels.base
+	}
+	return EffectiveLevel{
+		Component: string(component),
+		Level:     LevelName(entry.level.Level()),
+		Override:  ok,
+		Expires:   entry.expiry(),
+	}
+}
+
+// Components returns the components with a level override, sorted by name.
+func (levels *Levels) Components() []Component {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	components := make([]Component, 0, len(levels.components))
+	for component := range levels.components {
+		components = append(components, component)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i] < components[j] })
+	return components
+}
+
+// }}}
+// Level helpers. {{{
+
+// revert restores the configured level once a temporary change expires. A
+// component override added at runtime is removed so the base level applies.
+// A callback from a timer that a later Set replaced does nothing, since
+// Timer.Stop cannot recall a callback that already fired.
+func (levels *Levels) revert(component Component, entry *levelEntry, generation uint64) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	current := levels.base
+	if component != "" {
+		current = levels.components[component]
+	}
+	if current != entry || entry.timer == nil || entry.generation != generation {
+		return
+	}
+
+	entry.timer = nil
+	entry.expires = time.Time{}
+	if entry.configured != nil {
+		entry.level.Set(*entry.configured)
+	} else {
+		delete(levels.components, component)
+	}
+	levels.updateMinimum()
+}
+
+func (levels *Levels) updateMinimum() {
+	minimum := levels.base.level.Level()
+	for _, entry := range levels.components {
+		if level := entry.level.Level(); level < minimum {
+			minimum = level
+		}
+	}
+	levels.minimum.Store(int64(minimum))
+}
+
+func (entry *levelEntry) stopTimer() {
+	if entry.timer != nil {
+		entry.timer.Stop()
+		entry.timer = nil
+	}
+	entry.expires = time.Time{}
+}
+
+func (entry *levelEntry) expiry() *time.Time {
+	if entry.expires.IsZero() {
+		return nil
+	}
+	expires := entry.expires
+	return &expires
+}
+
+// ParseLevel converts a level name (`debug`, `info`, `warn`, `error`).
+func ParseLevel(name string) (slog.Level, error) {
+	return resolveLogLevel(config.LogLevel(strings.ToLower(name)), "")
+}
+
+// LevelName returns the config name for a level.
+func LevelName(level slog.Level) string {
+	switch level {
+	case slog.LevelDebug:
+		return string(config.LogLevelDebug)
+	case slog.LevelInfo:
+		return string(config.LogLevelInfo)
+	case slog.LevelWarn:
+		return string(config.LogLevelWarn)
+	case slog.LevelError:
+		return string(config.LogLevelError)
+	default:
+		return strings.ToLower(level.String())
+	}
+}
+
+// }}}
+// Level handler. {{{
+
+// levelHandler filters records by the effective level of their component.
+// The component is taken from logger attributes (logger.With) when present,
+// otherwise from the record's own attributes.
+type levelHandler struct {
+	next      slog.Handler
+	levels    *Levels
+	component Component
+	grouped   bool
+}
+
+func newLevelHandler(next slog.Handler, levels *Levels) slog.Handler {
+	return &levelHandler{next: next, levels: levels}
+}
+
+func (handler *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	threshold := handler.levels.Minimum()
+	if handler.component != "" {
+		threshold = handler.levels.Effective(handler.component)
+	}
+	return level >= threshold && handler.next.Enabled(ctx, level)
+}
+
+func (handler *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	component := handler.component
+	if component == "" {
+		component = recordComponent(record)
+	}
+	if record.Level < handler.levels.Effective(component) {
+		return nil
+	}
+	return handler.next.Handle(ctx, record)
+}
+
+func (handler *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *handler
+	clone.next = handler.next.WithAttrs(attrs)
+	if !handler.grouped {
+		for _, attr := range attrs {
+			if attr.Key == FieldComponent {
+				clone.component = Component(attr.Value.String())
+			}
+		}
+	}
+	return &clone
+}
+
+func (handler *levelHandler) WithGroup(name string) slog.Handler {
+	clone := *handler
+	clone.next = handler.next.WithGroup(name)
+	clone.grouped = clone.grouped || name != ""
+	return &clone
+}
+
+func recordComponent(record slog.Record) Component {
+	var component Component
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == FieldComponent {
+			component = Component(attr.Value.String())
+			return false
+		}
+		return true
+	})
+	return component
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Runtime log level tests.
+// This file verifies runtime level changes for the base level and individual
+// components, TTL-based reverts, and that the level handler filters records
+// by the component attached to the logger or the record.
+
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Runtime log level tests. {{{
+
+func TestLevelsComponentOverride(t *testing.T) {
+	var buffer bytes.Buffer
+	levels := NewLevels(slog.LevelInfo, map[Component]slog.Level{ComponentDatabase: slog.LevelDebug})
+	logger := slog.New(newLevelHandler(newHandler(&buffer, config.LogFormatText, slog.LevelDebug), levels))
+
+	logger.Debug("root debug")
+	logger.With(FieldComponent, string(ComponentDatabase)).Debug("database debug")
+	logger.Debug("record debug", FieldComponent, string(ComponentDatabase))
+	logger.With(FieldComponent, string(ComponentREST)).Debug("rest debug")
+
+	output := buffer.String()
+	if strings.Contains(output, "root debug") || strings.Contains(output, "rest debug") {
+		t.Fatalf("expected base debug records to be filtered, got: %s", output)
+	}
+	if !strings.Contains(output, "database debug") || !strings.Contains(output, "record debug") {
+		t.Fatalf("expected database debug records, got: %s", output)
+	}
+}
+
+func TestLevelsSetBaseLevel(t *testing.T) {
+	var buffer bytes.Buffer
+	levels := NewLevels(slog.LevelInfo, nil)
+	logger := slog.New(newLevelHandler(newHandler(&buffer, config.LogFormatText, slog.LevelDebug), levels))
+
+	logger.Debug("hidden")
+	if err := levels.Set("", slog.LevelDebug, 0); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	logger.Debug("visible")
+
+	output := buffer.String()
+	if strings.Contains(output, "hidden") || !strings.Contains(output, "visible") {
+		t.Fatalf("unexpected output after level change: %s", output)
+	}
+	if levels.Minimum() != slog.LevelDebug {
+		t.Fatalf("expected minimum level debug, got %s", levels.Minimum())
+	}
+}
+
+func TestLevelsTTLReverts(t *testing.T) {
+	levels := NewLevels(slog.LevelInfo, nil)
+
+	if err := levels.Set("", slog.LevelDebug, 20*time.Millisecond); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := levels.Set(ComponentSync, slog.LevelDebug, 20*time.Millisecond); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	snapshot := levels.Snapshot()
+	if snapshot.Level != "debug" || snapshot.Expires == nil {
+		t.Fatalf("expected temporary debug level, got: %+v", snapshot)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if levels.Level() == slog.LevelInfo && len(levels.Components()) == 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if levels.Level() != slog.LevelInfo {
+		t.Fatalf("expected base level to revert to info, got %s", levels.Level())
+	}
+	if len(levels.Components()) != 0 {
+		t.Fatalf("expected runtime component override to be removed, got %v", levels.Components())
+	}
+	if levels.Minimum() != slog.LevelInfo {
+		t.Fatalf("expected minimum level info, got %s", levels.Minimum())
+	}
+}
+
+func TestLevelsStaleRevertIgnored(t *testing.T) {
+	levels := NewLevels(slog.LevelInfo, nil)
+
+	if err := levels.Set("", slog.LevelDebug, time.Hour); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	stale := levels.base.generation
+	if err := levels.Set("", slog.LevelWarn, time.Hour); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer levels.base.stopTimer()
+
+	// A callback from the replaced timer that fired before Stop.
+	levels.revert("", levels.base, stale)
+	if levels.Level() != slog.LevelWarn {
+		t.Fatalf("expected stale revert to be ignored, got %s", levels.Level())
+	}
+	if levels.Snapshot().Expires == nil {
+		t.Fatal("expected new expiry to be kept")
+	}
+}
+
+func TestLevelsRejectsInvalidComponent(t *testing.T) {
+	levels := NewLevels(slog.LevelInfo, nil)
+	if err := levels.Set(Component("invalid"), slog.LevelDebug, 0); err == nil {
+		t.Fatal("expected error for invalid component")
+	}
+}
+
+func TestNewRuntimeComponentLevels(t *testing.T) {
+	cfg := config.LoggingConfig{Components: map[string]config.LogLevel{"invalid": config.LogLevelDebug}}
+	if _, err := NewRuntime(cfg, LoggerDefaults{Format: config.LogFormatJSON, Level: config.LogLevelInfo}); err == nil {
+		t.Fatal("expected error for invalid component level")
+	}
+
+	cfg = config.LoggingConfig{Components: map[string]config.LogLevel{"database": config.LogLevelDebug}}
+	runtime, err := NewRuntime(cfg, LoggerDefaults{Format: config.LogFormatJSON, Level: config.LogLevelInfo})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if runtime.Levels.Effective(ComponentDatabase) != slog.LevelDebug {
+		t.Fatalf("expected database level debug, got %s", runtime.Levels.Effective(ComponentDatabase))
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
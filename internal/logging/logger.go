@@ -2,16 +2,18 @@
 // Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
 
 // Logging initialization.
-// This file defines NewLogger, which constructs a slog.Logger from logging
-// config values, applies defaults when the config is unset, and attaches
-// base fields (component, server_id, environment) for consistent output.
+// This file defines NewLogger, which builds a slog.Logger from logging config
+// with base fields (component, server_id, environment), and NewRuntime, which
+// also returns the handles used while the process runs. Redaction, rotation,
+// throttling, levels, and the recent-log buffer live in their own files.
 
 package logging
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
-	"os"
+	"time"
 
 	"github.com/SandorMiskey/bms-core/internal/config"
 	"github.com/SandorMiskey/bms-core/internal/errtext"
@@ -23,16 +25,47 @@ type DefaultFields struct {
 	Component   Component
 	Environment string
 	ServerID    string
+	Version     string
 }
 
 type LoggerDefaults struct {
-	Fields DefaultFields
-	Format config.LogFormat
-	Level  config.LogLevel
+	BufferSize int // Recent records kept in memory when logging.buffer_size is unset (0 disables).
+	Fields     DefaultFields
+	Format     config.LogFormat
+	Level      config.LogLevel
+}
+
+// Runtime holds a logger and the handles used to manage it at runtime.
+type Runtime struct {
+	Buffer   *RingBuffer // Recent records; nil when disabled.
+	Levels   *Levels
+	Logger   *slog.Logger
+	Sinks    *Sinks
+	Throttle *Throttle // Dedup and rate limiting; nil when disabled.
+}
+
+// Close flushes pending suppression summaries and closes file outputs.
+func (runtime *Runtime) Close() error {
+	if runtime == nil {
+		return nil
+	}
+	runtime.Throttle.Flush()
+	return runtime.Sinks.Close()
 }
 
 // NewLogger builds a slog.Logger from config with fallbacks and base fields.
+// File outputs stay open for the life of the process; use NewRuntime when
+// they need to be reopened or closed.
 func NewLogger(cfg config.LoggingConfig, defaults LoggerDefaults) (*slog.Logger, error) {
+	runtime, err := NewRuntime(cfg, defaults)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.Logger, nil
+}
+
+// NewRuntime builds a logger from config and returns it with its sinks.
+func NewRuntime(cfg config.LoggingConfig, defaults LoggerDefaults) (*Runtime, error) {
 	format, err := resolveLogFormat(cfg.Format, defaults.Format)
 	if err != nil {
 		return nil, err
@@ -44,11 +77,39 @@ func NewLogger(cfg config.LoggingConfig, defaults LoggerDefaults) (*slog.Logger,
 	if defaults.Fields.Component != "" && !ValidComponent(defaults.Fields.Component) {
 		return nil, fmt.Errorf("%s: %q", errtext.ErrInvalidLogComponent, defaults.Fields.Component)
 	}
+	components, err := resolveComponentLevels(cfg.Components)
+	if err != nil {
+		return nil, err
+	}
+	throttle, err := resolveThrottle(cfg.DedupWindow, cfg.RateLimits)
+	if err != nil {
+		return nil, err
+	}
 
-	logger := slog.New(newHandler(format, level))
+	levels := NewLevels(level, components)
+	handler, sinks, err := newOutputHandler(cfg.Output, format, levels)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer *RingBuffer
+	bufferSize := cfg.BufferSize
+	if bufferSize == 0 {
+		bufferSize = defaults.BufferSize
+	}
+	if bufferSize > 0 {
+		buffer = NewRingBuffer(bufferSize)
+		handler = combineHandlers(handler, newLevelHandler(buffer.Handler(), levels))
+	}
+
+	if throttle != nil {
+		handler = newThrottleHandler(handler, throttle)
+	}
+	redactor := NewRedactor(cfg.RedactKeys...)
+	logger := slog.New(newRedactHandler(handler, redactor))
 	logger = applyDefaultFields(logger, defaults.Fields)
 
-	return logger, nil
+	return &Runtime{Buffer: buffer, Levels: levels, Logger: logger, Sinks: sinks, Throttle: throttle}, nil
 }
 
 // }}}
@@ -63,7 +124,7 @@ func resolveLogFormat(format config.LogFormat, fallback config.LogFormat) (confi
 	}
 
 	switch format {
-	case config.LogFormatJSON, config.LogFormatText:
+	case config.LogFormatConsole, config.LogFormatJSON, config.LogFormatText:
 		return format, nil
 	default:
 		return "", fmt.Errorf("%s: %q", errtext.ErrInvalidLogFormat, format)
@@ -92,16 +153,66 @@ func resolveLogLevel(level config.LogLevel, fallback config.LogLevel) (slog.Leve
 	}
 }
 
-func newHandler(format config.LogFormat, level slog.Level) slog.Handler {
+func resolveComponentLevels(components map[string]config.LogLevel) (map[Component]slog.Level, error) {
+	levels := make(map[Component]slog.Level, len(components))
+	for name, value := range components {
+		component := Component(name)
+		if !configurableComponent(component) {
+			return nil, fmt.Errorf("%s: %q", errtext.ErrInvalidLogComponent, name)
+		}
+		level, err := resolveLogLevel(value, "")
+		if err != nil {
+			return nil, err
+		}
+		levels[component] = level
+	}
+	return levels, nil
+}
+
+// resolveThrottle returns nil when neither dedup nor rate limits are set.
+func resolveThrottle(window string, limits map[string]config.LogRateLimitConfig) (*Throttle, error) {
+	var dedup time.Duration
+	if window != "" {
+		parsed, err := time.ParseDuration(window)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("%s: %q", errtext.ErrInvalidLogDedupWindow, window)
+		}
+		dedup = parsed
+	}
+
+	rates := make(map[Component]RateLimit, len(limits))
+	for name, limit := range limits {
+		component := Component(name)
+		if !configurableComponent(component) {
+			return nil, fmt.Errorf("%s: %q", errtext.ErrInvalidLogComponent, name)
+		}
+		if limit.Rate <= 0 || limit.Burst < 0 {
+			return nil, fmt.Errorf("%s: %q", errtext.ErrInvalidLogRateLimit, name)
+		}
+		rates[component] = RateLimit{Burst: limit.Burst, Rate: limit.Rate}
+	}
+
+	throttle := NewThrottle(dedup, rates)
+	if !throttle.Enabled() {
+		return nil, nil
+	}
+	return throttle, nil
+}
+
+func newHandler(writer io.Writer, format config.LogFormat, level slog.Leveler) slog.Handler {
 	options := &slog.HandlerOptions{Level: level}
-	if format == config.LogFormatText {
-		return slog.NewTextHandler(os.Stdout, options)
+	switch format {
+	case config.LogFormatConsole:
+		return newConsoleHandler(writer, level)
+	case config.LogFormatText:
+		return slog.NewTextHandler(writer, options)
+	default:
+		return slog.NewJSONHandler(writer, options)
 	}
-	return slog.NewJSONHandler(os.Stdout, options)
 }
 
 func applyDefaultFields(logger *slog.Logger, fields DefaultFields) *slog.Logger {
-	attrs := make([]any, 0, 6)
+	attrs := make([]any, 0, 8)
 	if fields.Component != "" {
 		attrs = append(attrs, FieldComponent, string(fields.Component))
 	}
@@ -111,6 +222,9 @@ func applyDefaultFields(logger *slog.Logger, fields DefaultFields) *slog.Logger
 	if fields.Environment != "" {
 		attrs = append(attrs, FieldEnvironment, fields.Environment)
 	}
+	if fields.Version != "" {
+		attrs = append(attrs, FieldVersion, fields.Version)
+	}
 	if len(attrs) == 0 {
 		return logger
 	}
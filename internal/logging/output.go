@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Logging outputs.
+// This file turns [[logging.output]] entries into slog handlers. Each output
+// writes to stdout, stderr, or a rotating file with its own format and level,
+// and a fan-out handler dispatches every record to all enabled outputs. Sinks
+// tracks the opened files so callers can reopen them on SIGHUP and close them
+// on shutdown.
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Sinks. {{{
+
+const bytesPerMB = 1024 * 1024
+
+// Sinks holds the file outputs opened for a logger.
+type Sinks struct {
+	files []*RotatingFile
+}
+
+// Reopen reopens every file output, e.g. after logrotate moved the files.
+func (sinks *Sinks) Reopen() error {
+	if sinks == nil {
+		return nil
+	}
+	var errs []error
+	for _, file := range sinks.files {
+		if err := file.Reopen(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every file output.
+func (sinks *Sinks) Close() error {
+	if sinks == nil {
+		return nil
+	}
+	var errs []error
+	for _, file := range sinks.files {
+		if err := file.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// }}}
+// Output handlers. {{{
+
+// newOutputHandler builds one handler per configured output, falling back to
+// a single stdout output using the resolved format. Outputs with their own
+// level filter statically; the rest follow the runtime levels.
+func newOutputHandler(outputs []config.LogOutputConfig, format config.LogFormat, levels *Levels) (slog.Handler, *Sinks, error) {
+	if len(outputs) == 0 {
+		outputs = []config.LogOutputConfig{{Target: config.LogTargetStdout}}
+	}
+
+	sinks := &Sinks{}
+	handlers := make([]slog.Handler, 0, len(outputs))
+	for _, output := range outputs {
+		outputFormat, err := resolveLogFormat(output.Format, format)
+		if err != nil {
+			_ = sinks.Close()
+			return nil, nil, err
+		}
+		var outputLevel slog.Level
+		if output.Level != "" {
+			outputLevel, err = resolveLogLevel(output.Level, "")
+			if err != nil {
+				_ = sinks.Close()
+				return nil, nil, err
+			}
+		}
+
+		writer, err := openOutput(output, sinks)
+		if err != nil {
+			_ = sinks.Close()
+			return nil, nil, err
+		}
+		if output.Level != "" {
+			handlers = append(handlers, newHandler(writer, outputFormat, outputLevel))
+			continue
+		}
+		handler := newHandler(writer, outputFormat, slog.Level(math.MinInt))
+		handlers = append(handlers, newLevelHandler(handler, levels))
+	}
+
+	return combineHandlers(handlers...), sinks, nil
+}
+
+func openOutput(output config.LogOutputConfig, sinks *Sinks) (io.Writer, error) {
+	switch output.Target {
+	case "":
+		return nil, fmt.Errorf("%s", errtext.ErrLogTargetRequired)
+	case config.LogTargetStdout:
+		return os.Stdout, nil
+	case config.LogTargetStderr:
+		return os.Stderr, nil
+	}
+
+	options, err := rotationOptions(output)
+	if err != nil {
+		return nil, err
+	}
+	file, err := OpenRotatingFile(output.Target, options)
+	if err != nil {
+		return nil, err
+	}
+	sinks.files = append(sinks.files, file)
+	return file, nil
+}
+
+func rotationOptions(output config.LogOutputConfig) (RotationOptions, error) {
+	options := RotationOptions{
+		Compress:   output.Compress,
+		MaxBackups: output.MaxBackups,
+		MaxSize:    int64(output.MaxSizeMB) * bytesPerMB,
+	}
+	if output.MaxAge != "" {
+		age, err := time.ParseDuration(output.MaxAge)
+		if err != nil {
+			return RotationOptions{}, fmt.Errorf("%s: %q", errtext.ErrInvalidLogMaxAge, output.MaxAge)
+		}
+		options.MaxAge = age
+	}
+	return options, nil
+}
+
+// }}}
+// Fan-out handler. {{{
+
+// combineHandlers returns a single handler that dispatches to all handlers,
+// flattening nested fan-outs.
+func combineHandlers(handlers ...slog.Handler) slog.Handler {
+	flat := make([]slog.Handler, 0, len(handlers))
+	for _, handler := range handlers {
+		if fanout, ok := handler.(*fanoutHandler); ok {
+			flat = append(flat, fanout.handlers...)
+			continue
+		}
+		flat = append(flat, handler)
+	}
+	if len(flat) == 1 {
+		return flat[0]
+	}
+	return &fanoutHandler{handlers: flat}
+}
+
+// fanoutHandler dispatches records to every handler that accepts the level.
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+func (handler *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, next := range handler.handlers {
+		if next.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (handler *fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, next := range handler.handlers {
+		if !next.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := next.Handle(ctx, record.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (handler *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(handler.handlers))
+	for _, next := range handler.handlers {
+		handlers = append(handlers, next.WithAttrs(attrs))
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+func (handler *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(handler.handlers))
+	for _, next := range handler.handlers {
+		handlers = append(handlers, next.WithGroup(name))
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Logging output tests.
+// This file verifies that [[logging.output]] entries fan records out to each
+// output with its own format and level, and that sinks reopen and close the
+// underlying files.
+
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Logging output tests. {{{
+
+func TestNewRuntimeMultipleOutputs(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "debug.json")
+	textPath := filepath.Join(dir, "warn.log")
+
+	cfg := config.LoggingConfig{
+		Format: config.LogFormatJSON,
+		Level:  config.LogLevelInfo,
+		Output: []config.LogOutputConfig{
+			{Target: jsonPath, Level: config.LogLevelDebug},
+			{Target: textPath, Format: config.LogFormatText, Level: config.LogLevelWarn},
+		},
+	}
+	runtime, err := NewRuntime(cfg, LoggerDefaults{Format: config.LogFormatJSON, Level: config.LogLevelInfo})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	runtime.Logger.Debug("debug record")
+	runtime.Logger.Warn("warn record")
+	if err := runtime.Sinks.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	jsonContent, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read json output: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(jsonContent)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 json records, got %d", len(lines))
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("expected json record, got: %s", lines[0])
+	}
+	if record["msg"] != "debug record" {
+		t.Fatalf("unexpected json record: %v", record)
+	}
+
+	textContent, err := os.ReadFile(textPath)
+	if err != nil {
+		t.Fatalf("failed to read text output: %v", err)
+	}
+	if strings.Contains(string(textContent), "debug record") {
+		t.Fatal("expected debug record to be filtered from warn output")
+	}
+	if !strings.Contains(string(textContent), `msg="warn record"`) {
+		t.Fatalf("expected text warn record, got: %s", textContent)
+	}
+}
+
+func TestNewRuntimeInvalidOutputLevel(t *testing.T) {
+	cfg := config.LoggingConfig{
+		Output: []config.LogOutputConfig{
+			{Target: config.LogTargetStderr, Level: config.LogLevel("loud")},
+		},
+	}
+	_, err := NewRuntime(cfg, LoggerDefaults{Format: config.LogFormatJSON, Level: config.LogLevelInfo})
+	if err == nil {
+		t.Fatal("expected error for invalid output level")
+	}
+}
+
+func TestSinksReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bmsd.log")
+	cfg := config.LoggingConfig{Output: []config.LogOutputConfig{{Target: path}}}
+	runtime, err := NewRuntime(cfg, LoggerDefaults{Format: config.LogFormatJSON, Level: config.LogLevelInfo})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer runtime.Sinks.Close()
+
+	runtime.Logger.Info("before")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("rename failed: %v", err)
+	}
+	if err := runtime.Sinks.Reopen(); err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	runtime.Logger.Info("after")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read reopened output: %v", err)
+	}
+	if strings.Contains(string(content), "before") || !strings.Contains(string(content), "after") {
+		t.Fatalf("unexpected reopened content: %s", content)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
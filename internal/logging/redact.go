@@ -0,0 +1,386 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Secret scrubbing for log output.
+// This file defines Redactor and a redacting slog.Handler middleware that
+// masks sensitive attributes before they reach any output. Attributes are
+// masked when their key contains a sensitive name (token, password, dsn,
+// secret, authorization, API or private key, plus any configured extras),
+// and string values are scrubbed for bearer tokens, URL userinfo, and
+// password= pairs. Groups, structs, maps, slices, and pointers are walked
+// recursively; structs are rebuilt with their original types so JSON and
+// text output keep their shape.
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Redactor. {{{
+
+const (
+	RedactedValue  = "[redacted]"
+	maxRedactDepth = 16
+)
+
+// DefaultRedactKeys lists key names whose string values are always masked.
+// Keys match case-insensitively anywhere in the attribute key, ignoring `_`
+// and `-`, so `auth_token`, `secret_key`, `API-Key`, and `DSN` match.
+var DefaultRedactKeys = []string{"apikey", "authorization", "dsn", "password", "privatekey", "secret", "token"}
+
+// redactMetadataSuffixes end keys that describe a secret rather than hold
+// it, so `token_ttl` and `password_expires` stay visible.
+var redactMetadataSuffixes = []string{"count", "expires", "expiry", "ttl"}
+
+var defaultRedactPatterns = []redactPattern{
+	{pattern: regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`), replacement: "$1 " + RedactedValue},
+	{pattern: regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.\-]*://)[^/\s@]+@`), replacement: "${1}" + RedactedValue + "@"},
+	{pattern: regexp.MustCompile(`(?i)\b(password|passwd|pwd)=('[^']*'|"[^"]*"|[^\s&;]+)`), replacement: "$1=" + RedactedValue},
+}
+
+type redactPattern struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// Redactor masks sensitive attributes and values.
+type Redactor struct {
+	keys     []string
+	patterns []redactPattern
+}
+
+// NewRedactor returns a redactor for the default keys plus extraKeys.
+func NewRedactor(extraKeys ...string) *Redactor {
+	keys := make([]string, 0, len(DefaultRedactKeys)+len(extraKeys))
+	for _, key := range append(append([]string(nil), DefaultRedactKeys...), extraKeys...) {
+		if normalized := normalizeRedactKey(key); normalized != "" {
+			keys = append(keys, normalized)
+		}
+	}
+	return &Redactor{keys: keys, patterns: defaultRedactPatterns}
+}
+
+// Attr returns a with sensitive content masked.
+func (redactor *Redactor) Attr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	sensitive := redactor.SensitiveKey(attr.Key)
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		if sensitive {
+			return maskAttr(attr)
+		}
+		if scrubbed := redactor.Scrub(attr.Value.String()); scrubbed != attr.Value.String() {
+			attr.Value = slog.StringValue(scrubbed)
+		}
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		redacted := make([]slog.Attr, 0, len(group))
+		for _, member := range group {
+			redacted = append(redacted, redactor.Attr(member))
+		}
+		attr.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		attr.Value = redactor.anyValue(attr.Value.Any(), sensitive)
+	}
+	return attr
+}
+
+// Scrub masks secret patterns (bearer tokens, URL userinfo, password pairs)
+// inside a free-form string.
+func (redactor *Redactor) Scrub(value string) string {
+	for _, rule := range redactor.patterns {
+		if rule.pattern.MatchString(value) {
+			value = rule.pattern.ReplaceAllString(value, rule.replacement)
+		}
+	}
+	return value
+}
+
+// SensitiveKey reports whether values under key must be masked.
+func (redactor *Redactor) SensitiveKey(key string) bool {
+	normalized := normalizeRedactKey(key)
+	if normalized == "" {
+		return false
+	}
+	for _, suffix := range redactMetadataSuffixes {
+		if strings.HasSuffix(normalized, suffix) {
+			return false
+		}
+	}
+	for _, candidate := range redactor.keys {
+		if strings.Contains(normalized, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// }}}
+// Value helpers. {{{
+
+func (redactor *Redactor) anyValue(value any, sensitive bool) slog.Value {
+	switch typed := value.(type) {
+	case nil:
+		return slog.AnyValue(nil)
+	case error:
+		message := typed.Error()
+		if scrubbed := redactor.Scrub(message); scrubbed != message {
+			return slog.StringValue(scrubbed)
+		}
+		return slog.AnyValue(value)
+	case []byte:
+		if sensitive && len(typed) > 0 {
+			return slog.StringValue(RedactedValue)
+		}
+		return slog.AnyValue(value)
+	}
+
+	reflected := reflect.ValueOf(value)
+	if sensitive && reflected.Kind() == reflect.String {
+		if reflected.Len() == 0 {
+			return slog.AnyValue(value)
+		}
+		return slog.StringValue(RedactedValue)
+	}
+	redacted, changed := redactor.reflectValue(reflected, 0)
+	if !changed {
+		return slog.AnyValue(value)
+	}
+	return slog.AnyValue(redacted.Interface())
+}
+
+// reflectValue returns a copy of value with sensitive fields masked. The bool
+// reports whether anything changed so callers can keep the original.
+func (redactor *Redactor) reflectValue(value reflect.Value, depth int) (reflect.Value, bool) {
+	if depth > maxRedactDepth || !value.IsValid() {
+		return value, false
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		scrubbed := redactor.Scrub(value.String())
+		if scrubbed == value.String() {
+			return value, false
+		}
+		out := reflect.New(value.Type()).Elem()
+		out.SetString(scrubbed)
+		return out, true
+
+	case reflect.Struct:
+		return redactor.reflectStruct(value, depth)
+
+	case reflect.Pointer:
+		if value.IsNil() {
+			return value, false
+		}
+		elem, changed := redactor.reflectValue(value.Elem(), depth+1)
+		if !changed {
+			return value, false
+		}
+		out := reflect.New(value.Type().Elem())
+		out.Elem().Set(elem)
+		return out, true
+
+	case reflect.Interface:
+		if value.IsNil() {
+			return value, false
+		}
+		elem, changed := redactor.reflectValue(value.Elem(), depth+1)
+		if !changed {
+			return value, false
+		}
+		out := reflect.New(value.Type()).Elem()
+		out.Set(elem)
+		return out, true
+
+	case reflect.Slice, reflect.Array:
+		return redactor.reflectList(value, depth)
+
+	case reflect.Map:
+		return redactor.reflectMap(value, depth)
+	}
+	return value, false
+}
+
+func (redactor *Redactor) reflectStruct(value reflect.Value, depth int) (reflect.Value, bool) {
+	valueType := value.Type()
+	var out reflect.Value
+	changed := false
+
+	for index := range value.NumField() {
+		field := valueType.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+
+		current := value.Field(index)
+		var replacement reflect.Value
+		fieldChanged := false
+		if field.Type.Kind() == reflect.String && redactor.SensitiveKey(structFieldKey(field)) {
+			if current.Len() > 0 && current.String() != RedactedValue {
+				replacement = reflect.New(field.Type).Elem()
+				replacement.SetString(RedactedValue)
+				fieldChanged = true
+			}
+		} else {
+			replacement, fieldChanged = redactor.reflectValue(current, depth+1)
+		}
+		if !fieldChanged {
+			continue
+		}
+
+		if !changed {
+			out = reflect.New(valueType).Elem()
+			out.Set(value)
+			changed = true
+		}
+		out.Field(index).Set(replacement)
+	}
+
+	if !changed {
+		return value, false
+	}
+	return out, true
+}
+
+func (redactor *Redactor) reflectList(value reflect.Value, depth int) (reflect.Value, bool) {
+	if value.Kind() == reflect.Slice && value.IsNil() {
+		return value, false
+	}
+	if value.Type().Elem().Kind() == reflect.Uint8 {
+		return value, false
+	}
+
+	var out reflect.Value
+	changed := false
+	for index := range value.Len() {
+		elem, elemChanged := redactor.reflectValue(value.Index(index), depth+1)
+		if !elemChanged {
+			continue
+		}
+		if !changed {
+			if value.Kind() == reflect.Slice {
+				out = reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+			} else {
+				out = reflect.New(value.Type()).Elem()
+			}
+			reflect.Copy(out, value)
+			changed = true
+		}
+		out.Index(index).Set(elem)
+	}
+	if !changed {
+		return value, false
+	}
+	return out, true
+}
+
+func (redactor *Redactor) reflectMap(value reflect.Value, depth int) (reflect.Value, bool) {
+	if value.IsNil() {
+		return value, false
+	}
+
+	replacements := make(map[int]reflect.Value)
+	keys := value.MapKeys()
+	for index, key := range keys {
+		elem := value.MapIndex(key)
+		if key.Kind() == reflect.String && redactor.SensitiveKey(key.String()) && isNonEmptyString(elem) {
+			replacements[index] = reflect.ValueOf(RedactedValue).Convert(value.Type().Elem())
+			continue
+		}
+		if redacted, changed := redactor.reflectValue(elem, depth+1); changed {
+			replacements[index] = redacted
+		}
+	}
+	if len(replacements) == 0 {
+		return value, false
+	}
+
+	out := reflect.MakeMapWithSize(value.Type(), value.Len())
+	for index, key := range keys {
+		if replacement, ok := replacements[index]; ok {
+			out.SetMapIndex(key, replacement)
+			continue
+		}
+		out.SetMapIndex(key, value.MapIndex(key))
+	}
+	return out, true
+}
+
+func isNonEmptyString(value reflect.Value) bool {
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value.Kind() == reflect.String && value.Len() > 0
+}
+
+func maskAttr(attr slog.Attr) slog.Attr {
+	if attr.Value.String() == "" {
+		return attr
+	}
+	attr.Value = slog.StringValue(RedactedValue)
+	return attr
+}
+
+func structFieldKey(field reflect.StructField) string {
+	for _, tag := range []string{"json", "toml"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func normalizeRedactKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.ReplaceAll(key, "_", "")
+	return strings.ReplaceAll(key, "-", "")
+}
+
+// }}}
+// Redacting handler. {{{
+
+// redactHandler masks attributes and scrubs messages before delegating.
+type redactHandler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+func newRedactHandler(next slog.Handler, redactor *Redactor) slog.Handler {
+	return &redactHandler{next: next, redactor: redactor}
+}
+
+func (handler *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.next.Enabled(ctx, level)
+}
+
+func (handler *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, handler.redactor.Scrub(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(handler.redactor.Attr(attr))
+		return true
+	})
+	return handler.next.Handle(ctx, redacted)
+}
+
+func (handler *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		redacted = append(redacted, handler.redactor.Attr(attr))
+	}
+	return &redactHandler{next: handler.next.WithAttrs(redacted), redactor: handler.redactor}
+}
+
+func (handler *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: handler.next.WithGroup(name), redactor: handler.redactor}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Rotating log files.
+// This file defines RotatingFile, an io.WriteCloser that appends to a log file
+// and rotates it by size and age. Rotated files are renamed with a timestamp
+// suffix, optionally gzip-compressed, and pruned to a retention count. Writes,
+// rotation, and reopen (for external logrotate) are serialized by a mutex so
+// concurrent writers never interleave partial records across files.
+
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Rotation options. {{{
+
+const (
+	rotatedTimeFormat = "20060102T150405.000"
+	compressedSuffix  = ".gz"
+	logFileMode       = 0o640
+	logDirMode        = 0o750
+)
+
+type RotationOptions struct {
+	Compress   bool          // Gzip rotated files.
+	MaxAge     time.Duration // Rotate when the file is older than this (0 disables).
+	MaxBackups int           // Rotated files to retain (0 keeps all).
+	MaxSize    int64         // Rotate when the file exceeds this many bytes (0 disables).
+}
+
+// }}}
+// RotatingFile. {{{
+
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	options  RotationOptions
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+
+	millMu sync.Mutex
+	mills  sync.WaitGroup
+}
+
+// OpenRotatingFile opens (or creates) the log file at path for appending.
+func OpenRotatingFile(path string, options RotationOptions) (*RotatingFile, error) {
+	file := &RotatingFile{
+		path:    path,
+		options: options,
+		now:     time.Now,
+	}
+	if err := file.open(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Write appends p to the current file, rotating first when a limit is reached.
+func (file *RotatingFile) Write(p []byte) (int, error) {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+
+	if file.file == nil {
+		return 0, fmt.Errorf("%s: %s", errtext.ErrLogFileClosed, file.path)
+	}
+	if file.shouldRotate(int64(len(p))) {
+		if err := file.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	written, err := file.file.Write(p)
+	file.size += int64(written)
+	return written, err
+}
+
+// Rotate forces a rotation of the current file.
+func (file *RotatingFile) Rotate() error {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+	return file.rotate()
+}
+
+// Reopen closes and reopens the file at the same path. It is meant for
+// external rotation (logrotate) that renames the file and sends SIGHUP.
+func (file *RotatingFile) Reopen() error {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+
+	if file.file != nil {
+		if err := file.file.Close(); err != nil {
+			return fmt.Errorf("%s %q: %w", errtext.ErrCloseLogFile, file.path, err)
+		}
+		file.file = nil
+	}
+	return file.open()
+}
+
+// Close closes the file and waits for pending compression and pruning.
+func (file *RotatingFile) Close() error {
+	file.mu.Lock()
+	var err error
+	if file.file != nil {
+		err = file.file.Close()
+		file.file = nil
+	}
+	file.mu.Unlock()
+
+	file.mills.Wait()
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", errtext.ErrCloseLogFile, file.path, err)
+	}
+	return nil
+}
+
+// }}}
+// Rotation helpers. {{{
+
+func (file *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(file.path), logDirMode); err != nil {
+		return fmt.Errorf("%s %q: %w", errtext.ErrOpenLogFile, file.path, err)
+	}
+	handle, err := os.OpenFile(file.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, logFileMode)
+	if err != nil {
+		return fmt.Errorf("%s %q: %w", errtext.ErrOpenLogFile, file.path, err)
+	}
+	info, err := handle.Stat()
+	if err != nil {
+		_ = handle.Close()
+		return fmt.Errorf("%s %q: %w", errtext.ErrOpenLogFile, file.path, err)
+	}
+
+	file.file = handle
+	file.size = info.Size()
+	file.openedAt = info.ModTime()
+	if file.size == 0 {
+		file.openedAt = file.now()
+	}
+	return nil
+}
+
+func (file *RotatingFile) shouldRotate(incoming int64) bool {
+	if file.size == 0 {
+		return false
+	}
+	if file.options.MaxSize > 0 && file.size+incoming > file.options.MaxSize {
+		return true
+	}
+	if file.options.MaxAge > 0 && file.now().Sub(file.openedAt) >= file.options.MaxAge {
+		return true
+	}
+	return false
+}
+
+func (file *RotatingFile) rotate() error {
+	if file.file != nil {
+		if err := file.file.Close(); err != nil {
+			return fmt.Errorf("%s %q: %w", errtext.ErrCloseLogFile, file.path, err)
+		}
+		file.file = nil
+	}
+
+	rotated := file.rotatedName(file.now())
+	if err := os.Rename(file.path, rotated); err != nil && !os.IsNotExist(err) {
+		_ = file.open()
+		return fmt.Errorf("%s %q: %w", errtext.ErrRotateLogFile, file.path, err)
+	}
+	if err := file.open(); err != nil {
+		return err
+	}
+
+	file.mills.Add(1)
+	go func() {
+		defer file.mills.Done()
+		file.mill()
+	}()
+	return nil
+}
+
+// rotatedName returns a timestamped backup name that does not exist yet,
+// stepping the timestamp forward when rotations land in the same millisecond.
+func (file *RotatingFile) rotatedName(at time.Time) string {
+	dir := filepath.Dir(file.path)
+	ext := filepath.Ext(file.path)
+	prefix := strings.TrimSuffix(filepath.Base(file.path), ext)
+	for {
+		name := filepath.Join(dir, prefix+"-"+at.UTC().Format(rotatedTimeFormat)+ext)
+		if !fileExists(name) && !fileExists(name+compressedSuffix) {
+			return name
+		}
+		at = at.Add(time.Millisecond)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// mill compresses rotated files and prunes old backups. It runs outside the
+// write lock so logging never waits on gzip or directory scans, and it scans
+// the directory instead of tracking one file so passes can run in any order.
+func (file *RotatingFile) mill() {
+	file.millMu.Lock()
+	defer file.millMu.Unlock()
+
+	if file.options.Compress {
+		backups, err := file.backups()
+		if err != nil {
+			return
+		}
+		for _, backup := range backups {
+			if strings.HasSuffix(backup, compressedSuffix) {
+				continue
+			}
+			if err := compressFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "%s %q: %v\n", errtext.ErrCompressLogFile, backup, err)
+			}
+		}
+	}
+	if file.options.MaxBackups > 0 {
+		file.prune()
+	}
+}
+
+func (file *RotatingFile) prune() {
+	backups, err := file.backups()
+	if err != nil || len(backups) <= file.options.MaxBackups {
+		return
+	}
+	for _, backup := range backups[:len(backups)-file.options.MaxBackups] {
+		_ = os.Remove(backup)
+	}
+}
+
+// backups returns rotated files oldest first; the timestamp suffix sorts
+// lexically in chronological order.
+func (file *RotatingFile) backups() ([]string, error) {
+	dir := filepath.Dir(file.path)
+	ext := filepath.Ext(file.path)
+	prefix := strings.TrimSuffix(filepath.Base(file.path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, compressedSuffix), ext)
+		stamp = strings.TrimPrefix(stamp, prefix)
+		if _, err := time.Parse(rotatedTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(path+compressedSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, logFileMode)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(target)
+	if _, err := io.Copy(writer, source); err != nil {
+		_ = writer.Close()
+		_ = target.Close()
+		_ = os.Remove(path + compressedSuffix)
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		_ = target.Close()
+		_ = os.Remove(path + compressedSuffix)
+		return err
+	}
+	if err := target.Close(); err != nil {
+		_ = os.Remove(path + compressedSuffix)
+		return err
+	}
+	return os.Remove(path)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Rotating log file tests.
+// This file verifies size- and age-based rotation, gzip compression and
+// retention of rotated files, reopen after external moves, and that
+// concurrent writers never split records across files.
+
+package logging
+
+import (
+	"bufio"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Rotating log file tests. {{{
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bmsd.log")
+	file, err := OpenRotatingFile(path, RotationOptions{MaxSize: 16})
+	if err != nil {
+		t.Fatalf("failed to open rotating file: %v", err)
+	}
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	file.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	for range 3 {
+		if _, err := file.Write([]byte("0123456789\n")); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	backups, err := file.backups()
+	if err != nil {
+		t.Fatalf("failed to list backups: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 rotated files, got %d", len(backups))
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if string(content) != "0123456789\n" {
+		t.Fatalf("unexpected current file content: %q", content)
+	}
+}
+
+func TestRotatingFileRotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bmsd.log")
+	file, err := OpenRotatingFile(path, RotationOptions{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("failed to open rotating file: %v", err)
+	}
+	clock := time.Now()
+	file.now = func() time.Time { return clock }
+
+	if _, err := file.Write([]byte("first\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	clock = clock.Add(2 * time.Hour)
+	if _, err := file.Write([]byte("second\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	backups, err := file.backups()
+	if err != nil {
+		t.Fatalf("failed to list backups: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 rotated file, got %d", len(backups))
+	}
+}
+
+func TestRotatingFileCompressesAndPrunes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bmsd.log")
+	file, err := OpenRotatingFile(path, RotationOptions{Compress: true, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("failed to open rotating file: %v", err)
+	}
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	file.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	for index := range 4 {
+		if _, err := file.Write([]byte("record\n")); err != nil {
+			t.Fatalf("write %d failed: %v", index, err)
+		}
+		if err := file.Rotate(); err != nil {
+			t.Fatalf("rotate %d failed: %v", index, err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	backups, err := file.backups()
+	if err != nil {
+		t.Fatalf("failed to list backups: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 retained backups, got %d: %v", len(backups), backups)
+	}
+	for _, backup := range backups {
+		if !strings.HasSuffix(backup, compressedSuffix) {
+			t.Fatalf("expected compressed backup, got %s", backup)
+		}
+	}
+
+	handle, err := os.Open(backups[1])
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer handle.Close()
+	reader, err := gzip.NewReader(handle)
+	if err != nil {
+		t.Fatalf("failed to read gzip backup: %v", err)
+	}
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil || line != "record\n" {
+		t.Fatalf("unexpected backup content: %q (%v)", line, err)
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bmsd.log")
+	file, err := OpenRotatingFile(path, RotationOptions{})
+	if err != nil {
+		t.Fatalf("failed to open rotating file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write([]byte("before\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	moved := filepath.Join(dir, "bmsd.log.1")
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatalf("rename failed: %v", err)
+	}
+	if err := file.Reopen(); err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if _, err := file.Write([]byte("after\n")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read reopened file: %v", err)
+	}
+	if string(content) != "after\n" {
+		t.Fatalf("unexpected reopened content: %q", content)
+	}
+}
+
+func TestRotatingFileConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bmsd.log")
+	file, err := OpenRotatingFile(path, RotationOptions{MaxSize: 256})
+	if err != nil {
+		t.Fatalf("failed to open rotating file: %v", err)
+	}
+
+	record := strings.Repeat("x", 31) + "\n"
+	var group sync.WaitGroup
+	for range 8 {
+		group.Go(func() {
+			for range 50 {
+				_, _ = file.Write([]byte(record))
+			}
+		})
+	}
+	group.Wait()
+	if err := file.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to list log dir: %v", err)
+	}
+	total := 0
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(filepath.Dir(path), entry.Name()))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", entry.Name(), err)
+		}
+		if len(content)%len(record) != 0 {
+			t.Fatalf("file %s contains a partial record", entry.Name())
+		}
+		total += len(content) / len(record)
+	}
+	if total != 400 {
+		t.Fatalf("expected 400 records across files, got %d", total)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
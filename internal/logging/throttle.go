@@ -0,0 +1,340 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Log deduplication and rate limiting.
+// This file defines a throttling slog.Handler middleware for noisy events.
+// Records with an identical level, message, and attribute set are suppressed
+// within a dedup window, and per-component token buckets cap sustained rates.
+// Suppressed records are summarized ("suppressed 312 similar records") once
+// the window closes or traffic resumes, and on Flush at shutdown. Errors
+// always get through at least once: the first error of each kind passes
+// dedup, and the limiter admits one error per component per second even when
+// its bucket is empty.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Throttle configuration. {{{
+
+const (
+	suppressedDuplicate = "duplicate"
+	suppressedRateLimit = "rate_limit"
+	errorGraceInterval  = time.Second
+)
+
+// RateLimit caps records per second for a component.
+type RateLimit struct {
+	Burst int     // Records allowed in a burst (0 uses the rate, at least 1).
+	Rate  float64 // Sustained records per second.
+}
+
+// Throttle holds shared dedup and rate limiting state.
+type Throttle struct {
+	mu        sync.Mutex
+	window    time.Duration
+	seen      map[uint64]*dedupEntry
+	limits    map[Component]RateLimit
+	buckets   map[Component]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type dedupEntry struct {
+	first   time.Time
+	count   int
+	level   slog.Level
+	message string
+	handler slog.Handler
+}
+
+type tokenBucket struct {
+	tokens    float64
+	last      time.Time
+	lastError time.Time
+	dropped   int
+	handler   slog.Handler
+	tagged    bool // handler already carries the component attribute
+}
+
+type suppressedSummary struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+// NewThrottle returns throttle state for a dedup window (0 disables dedup)
+// and per-component rate limits.
+func NewThrottle(window time.Duration, limits map[Component]RateLimit) *Throttle {
+	return &Throttle{
+		window:  window,
+		seen:    make(map[uint64]*dedupEntry),
+		limits:  limits,
+		buckets: make(map[Component]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Enabled reports whether the throttle does anything.
+func (throttle *Throttle) Enabled() bool {
+	return throttle != nil && (throttle.window > 0 || len(throttle.limits) > 0)
+}
+
+// Flush emits summaries for every pending suppression.
+func (throttle *Throttle) Flush() {
+	if throttle == nil {
+		return
+	}
+	throttle.mu.Lock()
+	summaries := throttle.sweep(throttle.now(), true)
+	throttle.mu.Unlock()
+	emitSummaries(summaries)
+}
+
+// }}}
+// Throttle decisions. {{{
+
+// admit decides whether a record passes and collects summaries to emit.
+func (throttle *Throttle) admit(handler slog.Handler, component Component, tagged bool, key uint64, record slog.Record) (bool, []suppressedSummary) {
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+
+	now := throttle.now()
+	var summaries []suppressedSummary
+	if throttle.window > 0 && now.Sub(throttle.lastSweep) >= throttle.window {
+		summaries = throttle.sweep(now, false)
+		throttle.lastSweep = now
+	}
+
+	if throttle.window > 0 {
+		entry, ok := throttle.seen[key]
+		if ok && now.Sub(entry.first) < throttle.window {
+			entry.count++
+			return false, summaries
+		}
+		if ok && entry.count > 0 {
+			summaries = append(summaries, entry.summary(now))
+		}
+		throttle.seen[key] = &dedupEntry{first: now, level: record.Level, message: record.Message, handler: handler}
+	}
+
+	limit, limited := throttle.limits[component]
+	if !limited {
+		return true, summaries
+	}
+	bucket := throttle.bucket(component, limit, now)
+	bucket.refill(limit, now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+	} else if record.Level >= slog.LevelError && now.Sub(bucket.lastError) >= errorGraceInterval {
+		bucket.lastError = now
+	} else {
+		bucket.dropped++
+		bucket.handler = handler
+		bucket.tagged = tagged
+		return false, summaries
+	}
+	if record.Level >= slog.LevelError {
+		bucket.lastError = now
+	}
+	if bucket.dropped > 0 {
+		summaries = append(summaries, bucket.summary(component, now))
+	}
+	return true, summaries
+}
+
+// sweep drops expired dedup entries and returns summaries for those that
+// suppressed records. With all set it also drains rate limit counters.
+func (throttle *Throttle) sweep(now time.Time, all bool) []suppressedSummary {
+	var summaries []suppressedSummary
+	for key, entry := range throttle.seen {
+		if !all && now.Sub(entry.first) < throttle.window {
+			continue
+		}
+		if entry.count > 0 {
+			summaries = append(summaries, entry.summary(now))
+		}
+		delete(throttle.seen, key)
+	}
+	if all {
+		for component, bucket := range throttle.buckets {
+			if bucket.dropped > 0 {
+				summaries = append(summaries, bucket.summary(component, now))
+			}
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].record.Message < summaries[j].record.Message
+	})
+	return summaries
+}
+
+func (throttle *Throttle) bucket(component Component, limit RateLimit, now time.Time) *tokenBucket {
+	bucket, ok := throttle.buckets[component]
+	if !ok {
+		bucket = &tokenBucket{tokens: limit.burst(), last: now}
+		throttle.buckets[component] = bucket
+	}
+	return bucket
+}
+
+func (limit RateLimit) burst() float64 {
+	if limit.Burst > 0 {
+		return float64(limit.Burst)
+	}
+	if limit.Rate >= 1 {
+		return limit.Rate
+	}
+	return 1
+}
+
+func (bucket *tokenBucket) refill(limit RateLimit, now time.Time) {
+	elapsed := now.Sub(bucket.last).Seconds()
+	bucket.last = now
+	if elapsed <= 0 {
+		return
+	}
+	bucket.tokens = min(limit.burst(), bucket.tokens+elapsed*limit.Rate)
+}
+
+func (entry *dedupEntry) summary(now time.Time) suppressedSummary {
+	record := slog.NewRecord(now, entry.level, suppressedMessage(entry.count), 0)
+	record.AddAttrs(
+		slog.String(FieldEvent, EventLogSuppressed.Name),
+		slog.Int(FieldSuppressedCount, entry.count),
+		slog.String(FieldSuppressedMessage, entry.message),
+		slog.String(FieldSuppressedReason, suppressedDuplicate),
+	)
+	entry.count = 0
+	return suppressedSummary{handler: entry.handler, record: record}
+}
+
+func (bucket *tokenBucket) summary(component Component, now time.Time) suppressedSummary {
+	record := slog.NewRecord(now, slog.LevelWarn, suppressedMessage(bucket.dropped), 0)
+	if !bucket.tagged {
+		record.AddAttrs(slog.String(FieldComponent, string(component)))
+	}
+	record.AddAttrs(
+		slog.String(FieldEvent, EventLogSuppressed.Name),
+		slog.Int(FieldSuppressedCount, bucket.dropped),
+		slog.String(FieldSuppressedReason, suppressedRateLimit),
+	)
+	bucket.dropped = 0
+	return suppressedSummary{handler: bucket.handler, record: record}
+}
+
+// suppressedMessage formats the catalog message of the summary event.
+func suppressedMessage(count int) string {
+	return fmt.Sprintf(EventLogSuppressed.Message, count)
+}
+
+func emitSummaries(summaries []suppressedSummary) {
+	for _, summary := range summaries {
+		if summary.handler == nil {
+			continue
+		}
+		_ = summary.handler.Handle(context.Background(), summary.record)
+	}
+}
+
+// }}}
+// Throttle handler. {{{
+
+// throttleHandler applies a Throttle in front of the next handler. It keeps a
+// fingerprint of logger attributes so records from different loggers with
+// the same message are counted separately.
+type throttleHandler struct {
+	next      slog.Handler
+	throttle  *Throttle
+	component Component
+	grouped   bool
+	prefix    uint64
+}
+
+func newThrottleHandler(next slog.Handler, throttle *Throttle) slog.Handler {
+	return &throttleHandler{next: next, throttle: throttle}
+}
+
+func (handler *throttleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.next.Enabled(ctx, level)
+}
+
+func (handler *throttleHandler) Handle(ctx context.Context, record slog.Record) error {
+	component := handler.component
+	if component == "" {
+		component = recordComponent(record)
+	}
+
+	admitted, summaries := handler.throttle.admit(handler.next, component, handler.component != "", handler.fingerprint(record), record)
+	emitSummaries(summaries)
+	if !admitted {
+		return nil
+	}
+	return handler.next.Handle(ctx, record)
+}
+
+func (handler *throttleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *handler
+	clone.next = handler.next.WithAttrs(attrs)
+	hash := fnv.New64a()
+	writeUint64(hash, handler.prefix)
+	for _, attr := range attrs {
+		writeAttr(hash, attr)
+		if !handler.grouped && attr.Key == FieldComponent {
+			clone.component = Component(attr.Value.String())
+		}
+	}
+	clone.prefix = hash.Sum64()
+	return &clone
+}
+
+func (handler *throttleHandler) WithGroup(name string) slog.Handler {
+	clone := *handler
+	clone.next = handler.next.WithGroup(name)
+	clone.grouped = clone.grouped || name != ""
+	hash := fnv.New64a()
+	writeUint64(hash, handler.prefix)
+	_, _ = io.WriteString(hash, "group:"+name)
+	clone.prefix = hash.Sum64()
+	return &clone
+}
+
+func (handler *throttleHandler) fingerprint(record slog.Record) uint64 {
+	hash := fnv.New64a()
+	writeUint64(hash, handler.prefix)
+	_, _ = fmt.Fprintf(hash, "%d|%s", record.Level, record.Message)
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(hash, attr)
+		return true
+	})
+	return hash.Sum64()
+}
+
+func writeAttr(writer io.Writer, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		_, _ = io.WriteString(writer, "|"+attr.Key+"{")
+		for _, member := range value.Group() {
+			writeAttr(writer, member)
+		}
+		_, _ = io.WriteString(writer, "}")
+		return
+	}
+	_, _ = fmt.Fprintf(writer, "|%s=%v", attr.Key, value.Any())
+}
+
+func writeUint64(writer io.Writer, value uint64) {
+	_, _ = fmt.Fprintf(writer, "%x|", value)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
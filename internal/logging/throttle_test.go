@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Log throttling tests.
+// This file verifies that identical records are suppressed within the dedup
+// window and summarized afterwards, that per-component token buckets cap
+// rates, and that errors still get through when a bucket is empty.
+
+package logging
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// Log throttling tests. {{{
+
+type fakeClock struct {
+	current time.Time
+}
+
+func (clock *fakeClock) now() time.Time {
+	return clock.current
+}
+
+func (clock *fakeClock) advance(duration time.Duration) {
+	clock.current = clock.current.Add(duration)
+}
+
+func newThrottledLogger(window time.Duration, limits map[Component]RateLimit) (*slog.Logger, *RingBuffer, *Throttle, *fakeClock) {
+	clock := &fakeClock{current: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	throttle := NewThrottle(window, limits)
+	throttle.now = clock.now
+	buffer := NewRingBuffer(100)
+	return slog.New(newThrottleHandler(buffer.Handler(), throttle)), buffer, throttle, clock
+}
+
+func TestThrottleDeduplicates(t *testing.T) {
+	logger, buffer, _, clock := newThrottledLogger(10*time.Second, nil)
+
+	for range 5 {
+		logger.Warn("peer unreachable", "peer", "a")
+	}
+	logger.Warn("peer unreachable", "peer", "b")
+	if buffer.Len() != 2 {
+		t.Fatalf("expected 2 records within the window, got %d", buffer.Len())
+	}
+
+	clock.advance(11 * time.Second)
+	logger.Warn("peer unreachable", "peer", "a")
+
+	entries := buffer.Entries(LogFilter{})
+	if len(entries) != 4 {
+		t.Fatalf("expected summary and repeated record, got: %+v", entries)
+	}
+	summary := entries[2]
+	if summary.Message != "suppressed 4 similar records" || summary.Level != "warn" {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if summary.Attrs[FieldSuppressedMessage] != "peer unreachable" || summary.Attrs[FieldSuppressedReason] != suppressedDuplicate {
+		t.Fatalf("unexpected summary attrs: %+v", summary.Attrs)
+	}
+	if entries[3].Message != "peer unreachable" {
+		t.Fatalf("expected repeated record after the window, got: %+v", entries[3])
+	}
+}
+
+func TestThrottleFlushSummarizes(t *testing.T) {
+	logger, buffer, throttle, _ := newThrottledLogger(time.Minute, nil)
+	syncLogger := logger.With(FieldComponent, string(ComponentSync))
+
+	syncLogger.Info("retrying")
+	syncLogger.Info("retrying")
+	throttle.Flush()
+
+	entries := buffer.Entries(LogFilter{})
+	if len(entries) != 2 || entries[1].Message != "suppressed 1 similar records" {
+		t.Fatalf("expected flushed summary, got: %+v", entries)
+	}
+	if entries[1].Component != string(ComponentSync) {
+		t.Fatalf("expected summary to keep logger attributes, got: %+v", entries[1])
+	}
+}
+
+func TestThrottleRateLimit(t *testing.T) {
+	limits := map[Component]RateLimit{ComponentSync: {Burst: 2, Rate: 1}}
+	logger, buffer, _, clock := newThrottledLogger(0, limits)
+	syncLogger := logger.With(FieldComponent, string(ComponentSync))
+
+	for range 5 {
+		syncLogger.Info("tick")
+	}
+	logger.Info("unlimited", FieldComponent, string(ComponentREST))
+	if count := len(buffer.Entries(LogFilter{Component: string(ComponentSync)})); count != 2 {
+		t.Fatalf("expected burst of 2 sync records, got %d", count)
+	}
+	if count := len(buffer.Entries(LogFilter{Component: string(ComponentREST)})); count != 1 {
+		t.Fatalf("expected unlimited component to pass, got %d", count)
+	}
+
+	syncLogger.Error("sync failed")
+	syncLogger.Error("sync failed again")
+	errors := buffer.Entries(LogFilter{Level: slog.LevelError})
+	if len(errors) != 1 || errors[0].Message != "sync failed" {
+		t.Fatalf("expected one error through an empty bucket, got: %+v", errors)
+	}
+	entries := buffer.Entries(LogFilter{})
+	summary := entries[len(entries)-2]
+	if summary.Message != "suppressed 3 similar records" || summary.Attrs[FieldSuppressedReason] != suppressedRateLimit {
+		t.Fatalf("unexpected rate limit summary: %+v", summary)
+	}
+
+	clock.advance(time.Second)
+	syncLogger.Info("tick")
+	entries = buffer.Entries(LogFilter{})
+	if entries[len(entries)-2].Message != "suppressed 1 similar records" {
+		t.Fatalf("expected summary for the dropped error, got: %+v", entries[len(entries)-2])
+	}
+	if entries[len(entries)-1].Message != "tick" {
+		t.Fatalf("expected record after refill, got: %+v", entries[len(entries)-1])
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
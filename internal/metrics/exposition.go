@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Prometheus text exposition.
+// This file renders a Registry in the Prometheus text format (version 0.0.4)
+// and serves it over HTTP. Families and series are sorted so scrapes are
+// stable and diffable; scrape hooks run first so pulled values are fresh.
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Exposition. {{{
+
+const (
+	Path        = "/metrics"
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// Handler serves the registry in the text exposition format.
+func Handler(registry *Registry) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", ContentType)
+		_ = registry.WriteText(writer)
+	})
+}
+
+// WriteText runs the scrape hooks and writes every family to writer.
+func (registry *Registry) WriteText(writer io.Writer) error {
+	registry.scrapeMu.Lock()
+	defer registry.scrapeMu.Unlock()
+
+	registry.mu.RLock()
+	hooks := append([]func(){}, registry.hooks...)
+	families := make([]*family, 0, len(registry.families))
+	for _, entry := range registry.families {
+		families = append(families, entry)
+	}
+	registry.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	buffered := bufio.NewWriter(writer)
+	for _, entry := range families {
+		entry.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+func (family *family) write(writer *bufio.Writer) {
+	family.mu.Lock()
+	defer family.mu.Unlock()
+	if len(family.series) == 0 {
+		return
+	}
+
+	if family.help != "" {
+		writer.WriteString("# HELP " + family.name + " " + escapeHelp(family.help) + "\n")
+	}
+	writer.WriteString("# TYPE " + family.name + " " + string(family.kind) + "\n")
+
+	keys := make([]string, 0, len(family.series))
+	for key := range family.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		entry := family.series[key]
+		if family.kind != typeHistogram {
+			writeSample(writer, family.name, family.labels, entry.values, "", "", entry.value)
+			continue
+		}
+
+		var cumulative uint64
+		for index, bound := range family.buckets {
+			cumulative += entry.counts[index]
+			writeSample(writer, family.name+"_bucket", family.labels, entry.values, labelBucket, formatValue(bound), float64(cumulative))
+		}
+		writeSample(writer, family.name+"_bucket", family.labels, entry.values, labelBucket, "+Inf", float64(entry.count))
+		writeSample(writer, family.name+"_sum", family.labels, entry.values, "", "", entry.sum)
+		writeSample(writer, family.name+"_count", family.labels, entry.values, "", "", float64(entry.count))
+	}
+}
+
+func writeSample(writer *bufio.Writer, name string, labels []string, values []string, extraLabel string, extraValue string, value float64) {
+	writer.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		writer.WriteByte('{')
+		for index, label := range labels {
+			if index > 0 {
+				writer.WriteByte(',')
+			}
+			writer.WriteString(label + `="` + escapeLabel(values[index]) + `"`)
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				writer.WriteByte(',')
+			}
+			writer.WriteString(extraLabel + `="` + extraValue + `"`)
+		}
+		writer.WriteByte('}')
+	}
+	writer.WriteString(" " + formatValue(value) + "\n")
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(text string) string {
+	return helpEscaper.Replace(text)
+}
+
+func escapeLabel(text string) string {
+	return labelEscaper.Replace(text)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// HTTP request metrics.
+// This file defines HTTPMetrics, middleware that counts requests, measures
+// latency, and tracks in-flight requests per listener. Labels are limited to
+// listener, method, and status code; request paths are left out to keep
+// cardinality bounded.
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTP metrics. {{{
+
+const (
+	labelCode     = "code"
+	labelListener = "listener"
+	labelMethod   = "method"
+	methodOther   = "OTHER"
+)
+
+// HTTPMetrics instruments HTTP handlers.
+type HTTPMetrics struct {
+	requests *Counter
+	duration *Histogram
+	inFlight *Gauge
+}
+
+// NewHTTPMetrics registers the HTTP request metrics.
+func NewHTTPMetrics(registry *Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: registry.Counter("http_requests_total", "HTTP requests handled, by listener, method, and status code.", labelListener, labelMethod, labelCode),
+		duration: registry.Histogram("http_request_duration_seconds", "HTTP request latency, by listener and method.", nil, labelListener, labelMethod),
+		inFlight: registry.Gauge("http_requests_in_flight", "HTTP requests currently being served, by listener.", labelListener),
+	}
+}
+
+// Wrap returns next instrumented under the listener label.
+func (httpMetrics *HTTPMetrics) Wrap(listener string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		method := normalizeMethod(request.Method)
+		httpMetrics.inFlight.Inc(listener)
+		defer httpMetrics.inFlight.Dec(listener)
+
+		recorder := &statusRecorder{ResponseWriter: writer, status: http.StatusOK}
+		started := time.Now()
+		next.ServeHTTP(recorder, request)
+
+		httpMetrics.duration.Observe(time.Since(started).Seconds(), listener, method)
+		httpMetrics.requests.Inc(listener, method, strconv.Itoa(recorder.status))
+	})
+}
+
+func normalizeMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	}
+	return methodOther
+}
+
+// statusRecorder captures the response status code.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	if !recorder.wroteHeader {
+		recorder.status = status
+		recorder.wroteHeader = true
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(data []byte) (int, error) {
+	recorder.wroteHeader = true
+	return recorder.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
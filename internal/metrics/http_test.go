@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// HTTP request metric tests.
+// This file verifies the middleware counts requests by listener, method,
+// and status code and records their latency.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// HTTP metric tests. {{{
+
+func TestHTTPMetrics(t *testing.T) {
+	registry := NewRegistry()
+	handler := NewHTTPMetrics(registry).Wrap("rest", http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/missing" {
+			http.NotFound(writer, request)
+			return
+		}
+		_, _ = writer.Write([]byte("ok\n"))
+	}))
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/healthz", nil),
+		httptest.NewRequest(http.MethodGet, "/missing", nil),
+		httptest.NewRequest("BREW", "/healthz", nil),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	body := scrape(t, registry)
+	for _, line := range []string{
+		`http_requests_total{listener="rest",method="GET",code="200"} 1`,
+		`http_requests_total{listener="rest",method="GET",code="404"} 1`,
+		`http_requests_total{listener="rest",method="OTHER",code="200"} 1`,
+		`http_request_duration_seconds_count{listener="rest",method="GET"} 2`,
+		`http_requests_in_flight{listener="rest"} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("missing %s in exposition:\n%s", line, body)
+		}
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
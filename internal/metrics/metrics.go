@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Metrics registry.
+// This file defines Registry and its counters, gauges, and histograms. Each
+// metric is a family of series keyed by label values; the registry renders
+// them in the Prometheus text exposition format (see exposition.go), so bmsd
+// can be scraped without a client library or a push gateway. Metric names
+// are constants, so registering an invalid or duplicate name panics.
+
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Metric types. {{{
+
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+
+	labelBucket    = "le"
+	labelSeparator = "\xff"
+)
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	// DefaultBuckets suit request latencies in seconds.
+	DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+)
+
+type family struct {
+	name    string
+	help    string
+	kind    metricType
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	value  float64
+	counts []uint64 // Per-bucket (not cumulative) histogram counts.
+	count  uint64
+	sum    float64
+}
+
+// lookup returns the series for label values, creating it on first use.
+// The caller holds family.mu.
+func (family *family) lookup(values []string) *series {
+	if len(values) != len(family.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", family.name, len(family.labels), len(values)))
+	}
+	key := strings.Join(values, labelSeparator)
+	entry, ok := family.series[key]
+	if !ok {
+		entry = &series{values: append([]string(nil), values...)}
+		if family.kind == typeHistogram {
+			entry.counts = make([]uint64, len(family.buckets))
+		}
+		family.series[key] = entry
+	}
+	return entry
+}
+
+// }}}
+// Registry. {{{
+
+// Registry holds metric families and scrape hooks.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+	hooks    []func()
+	scrapeMu sync.Mutex
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter registers a counter with the label names.
+func (registry *Registry) Counter(name string, help string, labels ...string) *Counter {
+	return &Counter{family: registry.register(name, help, typeCounter, labels, nil)}
+}
+
+// Gauge registers a gauge with the label names.
+func (registry *Registry) Gauge(name string, help string, labels ...string) *Gauge {
+	return &Gauge{family: registry.register(name, help, typeGauge, labels, nil)}
+}
+
+// Histogram registers a histogram with upper bucket bounds (nil uses
+// DefaultBuckets) and the label names.
+func (registry *Registry) Histogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	if len(buckets) > 0 && math.IsInf(buckets[len(buckets)-1], 1) {
+		buckets = buckets[:len(buckets)-1]
+	}
+	for _, label := range labels {
+		if label == labelBucket {
+			panic(fmt.Sprintf("metrics: %s uses reserved label %q", name, label))
+		}
+	}
+	return &Histogram{family: registry.register(name, help, typeHistogram, labels, buckets)}
+}
+
+// OnScrape adds a hook that runs before each scrape, for metrics read from
+// elsewhere (runtime stats, health results) rather than updated in place.
+func (registry *Registry) OnScrape(hook func()) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.hooks = append(registry.hooks, hook)
+}
+
+func (registry *Registry) register(name string, help string, kind metricType, labels []string, buckets []float64) *family {
+	if !metricNamePattern.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, label := range labels {
+		if !labelNamePattern.MatchString(label) || strings.HasPrefix(label, "__") {
+			panic(fmt.Sprintf("metrics: %s has invalid label name %q", name, label))
+		}
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, exists := registry.families[name]; exists {
+		panic(fmt.Sprintf("metrics: metric %q registered twice", name))
+	}
+	entry := &family{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  append([]string(nil), labels...),
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	registry.families[name] = entry
+	return entry
+}
+
+// }}}
+// Counters, gauges, and histograms. {{{
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	family *family
+}
+
+// Inc adds one to the series for the label values.
+func (counter *Counter) Inc(values ...string) {
+	counter.Add(1, values...)
+}
+
+// Add adds delta, which must not be negative.
+func (counter *Counter) Add(delta float64, values ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", counter.family.name))
+	}
+	counter.family.mu.Lock()
+	defer counter.family.mu.Unlock()
+	counter.family.lookup(values).value += delta
+}
+
+// set replaces the value; scrape hooks use it to mirror counters kept
+// elsewhere (runtime GC counts, CPU time).
+func (counter *Counter) set(value float64, values ...string) {
+	counter.family.mu.Lock()
+	defer counter.family.mu.Unlock()
+	counter.family.lookup(values).value = value
+}
+
+// Gauge is a value that goes up and down.
+type Gauge struct {
+	family *family
+}
+
+// Set replaces the value of the series for the label values.
+func (gauge *Gauge) Set(value float64, values ...string) {
+	gauge.family.mu.Lock()
+	defer gauge.family.mu.Unlock()
+	gauge.family.lookup(values).value = value
+}
+
+// Add adds delta (which may be negative).
+func (gauge *Gauge) Add(delta float64, values ...string) {
+	gauge.family.mu.Lock()
+	defer gauge.family.mu.Unlock()
+	gauge.family.lookup(values).value += delta
+}
+
+// Inc adds one.
+func (gauge *Gauge) Inc(values ...string) {
+	gauge.Add(1, values...)
+}
+
+// Dec subtracts one.
+func (gauge *Gauge) Dec(values ...string) {
+	gauge.Add(-1, values...)
+}
+
+// Histogram counts observations into buckets.
+type Histogram struct {
+	family *family
+}
+
+// Observe records a value for the label values.
+func (histogram *Histogram) Observe(value float64, values ...string) {
+	histogram.family.mu.Lock()
+	defer histogram.family.mu.Unlock()
+	entry := histogram.family.lookup(values)
+	index := sort.SearchFloat64s(histogram.family.buckets, value)
+	if index < len(entry.counts) {
+		entry.counts[index]++
+	}
+	entry.count++
+	entry.sum += value
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
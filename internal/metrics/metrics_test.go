@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Metrics registry tests.
+// This file verifies the text exposition of counters, gauges, and
+// histograms, label escaping, scrape hooks, registration rules, and the
+// runtime metrics.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Registry tests. {{{
+
+func scrape(t *testing.T, registry *Registry) string {
+	t.Helper()
+	var builder strings.Builder
+	if err := registry.WriteText(&builder); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return builder.String()
+}
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.Counter("bms_requests_total", "Requests served.", "method")
+	temperature := registry.Gauge("bms_temperature", "Current temperature.\nIn celsius.")
+	registry.Gauge("bms_unused", "Never set, so not exposed.")
+
+	requests.Inc("GET")
+	requests.Add(2, "GET")
+	requests.Inc(`PO"ST`)
+	temperature.Set(21.5)
+	temperature.Dec()
+
+	expected := `# HELP bms_requests_total Requests served.
+# TYPE bms_requests_total counter
+bms_requests_total{method="GET"} 3
+bms_requests_total{method="PO\"ST"} 1
+# HELP bms_temperature Current temperature.\nIn celsius.
+# TYPE bms_temperature gauge
+bms_temperature 20.5
+`
+	if got := scrape(t, registry); got != expected {
+		t.Fatalf("unexpected exposition:\n%s", got)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	registry := NewRegistry()
+	latency := registry.Histogram("bms_latency_seconds", "Latency.", []float64{1, 0.1}, "listener")
+	for _, value := range []float64{0.05, 0.1, 0.5, 3} {
+		latency.Observe(value, "rest")
+	}
+
+	expected := `# HELP bms_latency_seconds Latency.
+# TYPE bms_latency_seconds histogram
+bms_latency_seconds_bucket{listener="rest",le="0.1"} 2
+bms_latency_seconds_bucket{listener="rest",le="1"} 3
+bms_latency_seconds_bucket{listener="rest",le="+Inf"} 4
+bms_latency_seconds_sum{listener="rest"} 3.65
+bms_latency_seconds_count{listener="rest"} 4
+`
+	if got := scrape(t, registry); got != expected {
+		t.Fatalf("unexpected exposition:\n%s", got)
+	}
+}
+
+func TestOnScrape(t *testing.T) {
+	registry := NewRegistry()
+	pulled := registry.Gauge("bms_pulled", "")
+	calls := 0
+	registry.OnScrape(func() {
+		calls++
+		pulled.Set(float64(calls))
+	})
+
+	scrape(t, registry)
+	if got := scrape(t, registry); !strings.Contains(got, "bms_pulled 2\n") || strings.Contains(got, "# HELP") {
+		t.Fatalf("unexpected exposition:\n%s", got)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	cases := map[string]func(*Registry){
+		"invalid name":   func(registry *Registry) { registry.Counter("bms-requests", "") },
+		"invalid label":  func(registry *Registry) { registry.Counter("bms_requests", "", "__name") },
+		"reserved le":    func(registry *Registry) { registry.Histogram("bms_latency", "", nil, "le") },
+		"duplicate":      func(registry *Registry) { registry.Gauge("bms_a", ""); registry.Counter("bms_a", "") },
+		"label count":    func(registry *Registry) { registry.Counter("bms_b", "", "method").Inc() },
+		"negative delta": func(registry *Registry) { registry.Counter("bms_c", "").Add(-1) },
+	}
+	for name, register := range cases {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected a panic")
+				}
+			}()
+			register(NewRegistry())
+		})
+	}
+}
+
+func TestRuntimeMetrics(t *testing.T) {
+	registry := NewRegistry()
+	RegisterRuntime(registry)
+
+	recorder := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, Path, nil))
+	if recorder.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("unexpected content type: %q", recorder.Header().Get("Content-Type"))
+	}
+	body := recorder.Body.String()
+	for _, name := range []string{"go_goroutines ", "go_info{version=", "go_memstats_alloc_bytes ", "process_start_time_seconds "} {
+		if !strings.Contains(body, name) {
+			t.Fatalf("missing %s in exposition:\n%s", name, body)
+		}
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
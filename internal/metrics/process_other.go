@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Process metrics elsewhere.
+// This file stubs process metrics on platforms without the Unix process
+// accounting calls; only the portable metrics in runtime.go are reported.
+
+//go:build !unix
+
+package metrics
+
+// Process metrics. {{{
+
+func registerProcess(*Registry) func() {
+	return func() {}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
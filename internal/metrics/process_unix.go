@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Process metrics on Unix.
+// This file reads CPU time and the descriptor limit from the kernel, and on
+// systems with procfs the open descriptor count and resident memory too.
+// Metrics that cannot be read on the platform are not registered at all, so
+// a missing value never shows up as a misleading zero.
+
+//go:build unix
+
+package metrics
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Process metrics. {{{
+
+const (
+	procSelf  = "/proc/self"
+	procFD    = procSelf + "/fd"
+	procStatm = procSelf + "/statm"
+)
+
+// registerProcess registers the process metrics and returns their scrape
+// function.
+func registerProcess(registry *Registry) func() {
+	cpu := registry.Counter("process_cpu_seconds_total", "Total user and system CPU time spent in seconds.")
+	maxFDs := registry.Gauge("process_max_fds", "Maximum number of open file descriptors.")
+
+	var openFDs, resident *Gauge
+	if _, err := os.Stat(procSelf); err == nil {
+		openFDs = registry.Gauge("process_open_fds", "Number of open file descriptors.")
+		resident = registry.Gauge("process_resident_memory_bytes", "Resident memory size in bytes.")
+	}
+
+	return func() {
+		var usage syscall.Rusage
+		if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err == nil {
+			seconds := float64(usage.Utime.Sec+usage.Stime.Sec) + float64(usage.Utime.Usec+usage.Stime.Usec)/1e6
+			cpu.set(seconds)
+		}
+		var limit syscall.Rlimit
+		if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err == nil {
+			maxFDs.Set(float64(limit.Cur))
+		}
+		if openFDs != nil {
+			if entries, err := os.ReadDir(procFD); err == nil {
+				openFDs.Set(float64(len(entries)))
+			}
+		}
+		if resident != nil {
+			if pages, ok := residentPages(); ok {
+				resident.Set(float64(pages * int64(os.Getpagesize())))
+			}
+		}
+	}
+}
+
+// residentPages reads the resident set size, in pages, from procfs.
+func residentPages() (int64, bool) {
+	data, err := os.ReadFile(procStatm)
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	return pages, err == nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
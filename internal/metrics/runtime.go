@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Go runtime and process metrics.
+// This file registers the standard go_* and process_* metrics. Values are
+// read at scrape time through a hook; platform-specific process figures
+// (CPU time, open descriptors, resident memory) live in process_*.go.
+
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// Runtime metrics. {{{
+
+// RegisterRuntime adds Go runtime and process metrics to registry.
+func RegisterRuntime(registry *Registry) {
+	info := registry.Gauge("go_info", "Go version the binary was built with.", "version")
+	goroutines := registry.Gauge("go_goroutines", "Number of goroutines that currently exist.")
+	allocated := registry.Gauge("go_memstats_alloc_bytes", "Bytes of allocated heap objects.")
+	heapInuse := registry.Gauge("go_memstats_heap_inuse_bytes", "Bytes in in-use heap spans.")
+	heapObjects := registry.Gauge("go_memstats_heap_objects", "Number of allocated heap objects.")
+	system := registry.Gauge("go_memstats_sys_bytes", "Bytes of memory obtained from the OS.")
+	gcCycles := registry.Counter("go_gc_cycles_total", "Completed GC cycles.")
+	gcPause := registry.Counter("go_gc_pause_seconds_total", "Cumulative GC stop-the-world pause time.")
+	startTime := registry.Gauge("process_start_time_seconds", "Start time of the process since the Unix epoch.")
+
+	info.Set(1, runtime.Version())
+	startTime.Set(float64(time.Now().UnixNano()) / float64(time.Second))
+	process := registerProcess(registry)
+
+	registry.OnScrape(func() {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+
+		goroutines.Set(float64(runtime.NumGoroutine()))
+		allocated.Set(float64(stats.Alloc))
+		heapInuse.Set(float64(stats.HeapInuse))
+		heapObjects.Set(float64(stats.HeapObjects))
+		system.Set(float64(stats.Sys))
+		gcCycles.set(float64(stats.NumGC))
+		gcPause.set(float64(stats.PauseTotalNs) / float64(time.Second))
+		process()
+	})
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Migration lint.
+// This file checks a migration tree laid out like db/migrations against the
+// repository rules: up and down files come in pairs, shared/ and the driver
+// trees hold the same files, destructive changes carry an IRREVERSIBLE
+// header, seed rollbacks delete by public_id, and schema dumps exist for the
+// latest migration. Problems name the file and, where one applies, the line
+// of the offending statement. The runner refuses trees with problems; the
+// same checks run from go test and `bms dev migrate-check`.
+
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Problems. {{{
+
+// Rule names one lint rule.
+type Rule string
+
+const (
+	RuleLayout       Rule = "layout"       // Required directories exist.
+	RulePair         Rule = "pair"         // Every up file has a down file and vice versa.
+	RuleParity       Rule = "parity"       // shared/, sqlite/, and postgres/ hold the same files.
+	RuleIrreversible Rule = "irreversible" // DROP and TRUNCATE need an IRREVERSIBLE header.
+	RuleSeedDown     Rule = "seed_down"    // Seed rollbacks delete by public_id.
+	RuleSchemaDump   Rule = "schema_dump"  // Schema dumps exist for the latest migration.
+)
+
+// Problem is one rule violation. File is relative to the tree root.
+type Problem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Rule    Rule   `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (problem Problem) Error() string {
+	if problem.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s (%s)", problem.File, problem.Line, problem.Message, problem.Rule)
+	}
+	return fmt.Sprintf("%s: %s (%s)", problem.File, problem.Message, problem.Rule)
+}
+
+// Problems is the result of a check; it is an error when not empty.
+type Problems []Problem
+
+func (problems Problems) Error() string {
+	messages := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		messages = append(messages, problem.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Err returns problems as an error, or nil when there are none.
+func (problems Problems) Err() error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// }}}
+// Migration checks. {{{
+
+const (
+	sharedTree = "shared"
+	downSuffix = ".down.sql"
+	upSuffix   = ".up.sql"
+)
+
+// DriverTrees are the trees the runner applies, one per driver.
+var DriverTrees = []string{"sqlite", "postgres"}
+
+var (
+	destructive  = regexp.MustCompile(`(?i)\b(drop|truncate)\b`)
+	irreversible = regexp.MustCompile(`(?i)^--\s*IRREVERSIBLE:`)
+	blanket      = regexp.MustCompile(`(?i)^\s*(delete|truncate)\b`)
+)
+
+// Check runs the migration rules against the tree in migrations.
+func Check(migrations fs.FS) Problems {
+	var problems Problems
+	trees := make(map[string][]string)
+	for _, tree := range append([]string{sharedTree}, DriverTrees...) {
+		names, err := sqlFiles(migrations, tree)
+		if err != nil {
+			problems = append(problems, Problem{File: tree, Rule: RuleLayout, Message: directoryMessage(err)})
+			continue
+		}
+		trees[tree] = names
+		problems = append(problems, checkPairs(tree, names)...)
+	}
+
+	if shared, ok := trees[sharedTree]; ok {
+		for _, tree := range DriverTrees {
+			if names, ok := trees[tree]; ok {
+				problems = append(problems, checkParity(tree, shared, names)...)
+			}
+		}
+	}
+
+	for _, tree := range append([]string{sharedTree}, DriverTrees...) {
+		for _, name := range trees[tree] {
+			file := path.Join(tree, name)
+			body, err := fs.ReadFile(migrations, file)
+			if err != nil {
+				problems = append(problems, Problem{File: file, Rule: RuleLayout, Message: err.Error()})
+				continue
+			}
+			if tree != sharedTree && strings.HasSuffix(name, upSuffix) {
+				problems = append(problems, checkIrreversible(file, string(body))...)
+			}
+			if strings.Contains(name, "_seed") && strings.HasSuffix(name, downSuffix) {
+				problems = append(problems, checkSeedDown(file, string(body))...)
+			}
+		}
+	}
+	return problems
+}
+
+// CheckSchemaDumps checks that schema holds a dump per driver taken after
+// the latest shared migration, named schema_<time>_after_<version>*.sql.
+// Problem files are relative to schema; a missing shared/ is left to Check.
+func CheckSchemaDumps(migrations fs.FS, schema fs.FS) Problems {
+	names, _ := sqlFiles(migrations, sharedTree)
+	var latest string
+	for _, name := range names {
+		version, _, _ := strings.Cut(name, "_")
+		if strings.HasSuffix(name, upSuffix) && version > latest {
+			latest = version
+		}
+	}
+	if latest == "" {
+		return nil
+	}
+
+	var problems Problems
+	for _, tree := range DriverTrees {
+		if _, err := fs.ReadDir(schema, tree); err != nil {
+			problems = append(problems, Problem{File: tree, Rule: RuleLayout, Message: directoryMessage(err)})
+			continue
+		}
+		matches, err := fs.Glob(schema, path.Join(tree, "schema_*_after_"+latest+"*.sql"))
+		if err != nil || len(matches) == 0 {
+			problems = append(problems, Problem{File: tree, Rule: RuleSchemaDump, Message: "no schema dump after migration " + latest})
+		}
+	}
+	return problems
+}
+
+// sqlFiles lists the up and down files in tree.
+func sqlFiles(fsys fs.FS, tree string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, tree)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && (strings.HasSuffix(name, upSuffix) || strings.HasSuffix(name, downSuffix)) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func directoryMessage(err error) string {
+	if errors.Is(err, fs.ErrNotExist) {
+		return "missing directory"
+	}
+	return err.Error()
+}
+
+func checkPairs(tree string, names []string) Problems {
+	present := make(map[string]bool, len(names))
+	for _, name := range names {
+		present[name] = true
+	}
+	var problems Problems
+	for _, name := range names {
+		var pair string
+		if stem, ok := strings.CutSuffix(name, upSuffix); ok {
+			pair = stem + downSuffix
+		} else {
+			pair = strings.TrimSuffix(name, downSuffix) + upSuffix
+		}
+		if !present[pair] {
+			problems = append(problems, Problem{File: path.Join(tree, name), Rule: RulePair, Message: "missing " + pair})
+		}
+	}
+	return problems
+}
+
+func checkParity(tree string, shared []string, names []string) Problems {
+	present := make(map[string]bool, len(names))
+	for _, name := range names {
+		present[name] = true
+	}
+	inShared := make(map[string]bool, len(shared))
+	var problems Problems
+	for _, name := range shared {
+		inShared[name] = true
+		if !present[name] {
+			problems = append(problems, Problem{File: path.Join(sharedTree, name), Rule: RuleParity, Message: "missing from " + tree + "/"})
+		}
+	}
+	for _, name := range names {
+		if !inShared[name] {
+			problems = append(problems, Problem{File: path.Join(tree, name), Rule: RuleParity, Message: "not in " + sharedTree + "/"})
+		}
+	}
+	return problems
+}
+
+// checkIrreversible reports the first destructive statement of an up file
+// without an IRREVERSIBLE header.
+func checkIrreversible(file string, body string) Problems {
+	for _, line := range strings.Split(body, "\n") {
+		if irreversible.MatchString(strings.TrimSpace(line)) {
+			return nil
+		}
+	}
+	for _, stmt := range statements(body) {
+		if match := destructive.FindString(stmt.text); match != "" {
+			return Problems{{File: file, Line: stmt.line, Rule: RuleIrreversible, Message: strings.ToUpper(match) + " without an \"-- IRREVERSIBLE:\" header"}}
+		}
+	}
+	return nil
+}
+
+// checkSeedDown reports DELETE and TRUNCATE statements in a seed rollback
+// that do not filter by public_id.
+func checkSeedDown(file string, body string) Problems {
+	var problems Problems
+	for _, stmt := range statements(body) {
+		if match := blanket.FindStringSubmatch(stmt.text); match != nil && !strings.Contains(stmt.text, "public_id") {
+			problems = append(problems, Problem{File: file, Line: stmt.line, Rule: RuleSeedDown, Message: strings.ToUpper(match[1]) + " without a public_id filter"})
+		}
+	}
+	return problems
+}
+
+// statement is one SQL statement with comments removed and the line it
+// starts on.
+type statement struct {
+	line int
+	text string
+}
+
+// statements splits body on semicolons, dropping -- comments. Quoted
+// semicolons and comment markers are not special-cased; migrations do not
+// use them.
+func statements(body string) []statement {
+	var list []statement
+	var current statement
+	for index, line := range strings.Split(body, "\n") {
+		code, _, _ := strings.Cut(line, "--")
+		for {
+			part, rest, found := strings.Cut(code, ";")
+			if strings.TrimSpace(part) != "" {
+				if current.line == 0 {
+					current.line = index + 1
+				}
+				current.text += part + "\n"
+			}
+			if !found {
+				break
+			}
+			if current.line != 0 {
+				list = append(list, current)
+			}
+			current, code = statement{}, rest
+		}
+	}
+	if current.line != 0 {
+		list = append(list, current)
+	}
+	return list
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Migration lint tests.
+// This file verifies each rule against small in-memory trees, including the
+// reported file and line.
+
+package lint
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// Lint tests. {{{
+
+// tree places the same files in shared/, sqlite/, and postgres/.
+func tree(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, body := range files {
+		for _, dir := range []string{"shared", "sqlite", "postgres"} {
+			fsys[dir+"/"+name] = &fstest.MapFile{Data: []byte(body)}
+		}
+	}
+	return fsys
+}
+
+func describe(problems Problems) string {
+	lines := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		lines = append(lines, problem.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestCheckClean(t *testing.T) {
+	fsys := tree(map[string]string{
+		"1_table.up.sql":      "CREATE TABLE a (id INTEGER);",
+		"1_table.down.sql":    "DROP TABLE a;",
+		"2_drop.up.sql":       "-- IRREVERSIBLE: removes column b.\nALTER TABLE a DROP COLUMN b;",
+		"2_drop.down.sql":     "ALTER TABLE a ADD COLUMN b TEXT;",
+		"3_seed.up.sql":       "INSERT INTO a (public_id) VALUES ('x');",
+		"3_seed.down.sql":     "DELETE FROM a\nWHERE public_id IN ('x');",
+		"4_comments.up.sql":   "-- Nothing is dropped here.\nCREATE TABLE c (id INTEGER);",
+		"4_comments.down.sql": "DROP TABLE c;",
+	})
+	if problems := Check(fsys); len(problems) != 0 || problems.Err() != nil {
+		t.Fatalf("expected no problems, got:\n%s", describe(problems))
+	}
+}
+
+func TestCheckProblems(t *testing.T) {
+	fsys := tree(map[string]string{
+		"1_table.up.sql":   "CREATE TABLE a (id INTEGER);",
+		"1_table.down.sql": "DROP TABLE a;",
+		"2_drop.up.sql":    "CREATE TABLE b (id INTEGER);\n\nDROP TABLE\n  a;",
+		"2_drop.down.sql":  "CREATE TABLE a (id INTEGER);",
+		"3_seed.up.sql":    "INSERT INTO a (public_id) VALUES ('x');",
+		"3_seed.down.sql":  "DELETE FROM a WHERE public_id = 'x';\n-- Clean up.\nDELETE FROM b;",
+	})
+	delete(fsys, "postgres/1_table.down.sql")
+	delete(fsys, "sqlite/2_drop.up.sql")
+	delete(fsys, "sqlite/2_drop.down.sql")
+	fsys["sqlite/9_extra.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	fsys["sqlite/9_extra.down.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+
+	want := []string{
+		"postgres/1_table.up.sql: missing 1_table.down.sql (pair)",
+		"shared/2_drop.down.sql: missing from sqlite/ (parity)",
+		"shared/2_drop.up.sql: missing from sqlite/ (parity)",
+		"sqlite/9_extra.down.sql: not in shared/ (parity)",
+		"sqlite/9_extra.up.sql: not in shared/ (parity)",
+		"shared/1_table.down.sql: missing from postgres/ (parity)",
+		"shared/3_seed.down.sql:3: DELETE without a public_id filter (seed_down)",
+		"sqlite/3_seed.down.sql:3: DELETE without a public_id filter (seed_down)",
+		"postgres/2_drop.up.sql:3: DROP without an \"-- IRREVERSIBLE:\" header (irreversible)",
+		"postgres/3_seed.down.sql:3: DELETE without a public_id filter (seed_down)",
+	}
+	problems := Check(fsys)
+	if got := describe(problems); got != strings.Join(want, "\n") {
+		t.Fatalf("unexpected problems:\n%s", got)
+	}
+	var target Problems
+	if err := problems.Err(); !errors.As(err, &target) || len(target) != len(want) {
+		t.Fatalf("expected Problems as the error, got %v", err)
+	}
+}
+
+func TestCheckLayout(t *testing.T) {
+	fsys := fstest.MapFS{"sqlite/1_a.up.sql": {}, "sqlite/1_a.down.sql": {}}
+	got := describe(Check(fsys))
+	want := "shared: missing directory (layout)\npostgres: missing directory (layout)"
+	if got != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestCheckSchemaDumps(t *testing.T) {
+	migrations := tree(map[string]string{
+		"20261019120000_a.up.sql":   "",
+		"20261019120000_a.down.sql": "",
+		"20261020120000_b.up.sql":   "",
+		"20261020120000_b.down.sql": "",
+	})
+	schema := fstest.MapFS{
+		"sqlite/schema_20261020130000_after_20261020120000.sql":   {},
+		"postgres/schema_20261019130000_after_20261019120000.sql": {},
+	}
+	got := describe(CheckSchemaDumps(migrations, schema))
+	if got != "postgres: no schema dump after migration 20261020120000 (schema_dump)" {
+		t.Fatalf("unexpected problems:\n%s", got)
+	}
+	if got := describe(CheckSchemaDumps(migrations, fstest.MapFS{})); !strings.Contains(got, "sqlite: missing directory (layout)") {
+		t.Fatalf("expected missing schema directories, got:\n%s", got)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Migration runner.
+// This file defines Migrator, which applies and rolls back migrations and
+// records the current version in schema_migrations, the same single-row
+// table the migrate CLI uses, so databases migrated either way stay
+// interchangeable. Each step runs in its own transaction together with the
+// version update and re-reads the version first. Concurrent runners are
+// serialized by pg_advisory_lock on PostgreSQL and by the immediate write
+// transaction on SQLite, so a second bmsd starting at the same time waits
+// and then finds nothing left to do.
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/migrate/lint"
+)
+
+// Migrator. {{{
+
+// lockKey is the pg_advisory_lock key shared by every bmsd migrating the
+// same database ("bms_mig").
+const lockKey int64 = 0x626d735f6d6967
+
+// Version table definitions, matching the migrate CLI drivers.
+var createTable = map[config.DatabaseDriver]string{
+	config.DriverPostgres: `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`,
+	config.DriverSQLite:   `CREATE TABLE IF NOT EXISTS schema_migrations (version uint64, dirty bool); CREATE UNIQUE INDEX IF NOT EXISTS version_unique ON schema_migrations (version)`,
+}
+
+// Version table lookups used by Status, which must not create it.
+var tableExists = map[config.DatabaseDriver]string{
+	config.DriverPostgres: `SELECT count(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'`,
+	config.DriverSQLite:   `SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`,
+}
+
+// Migrator runs migrations against one database.
+type Migrator struct {
+	db         *db.DB
+	logger     *slog.Logger
+	migrations []Migration
+}
+
+// New loads the migrations for the database's driver from fsys. A tree that
+// fails the lint checks is refused.
+func New(database *db.DB, fsys fs.FS, logger *slog.Logger) (*Migrator, error) {
+	if err := lint.Check(fsys).Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", errtext.ErrMigrationLint, err)
+	}
+	list, err := Load(fsys, database.Dialect().Driver())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: database, logger: logger, migrations: list}, nil
+}
+
+// Migrations returns the known migrations in version order.
+func (migrator *Migrator) Migrations() []Migration {
+	return migrator.migrations
+}
+
+// Up applies every pending migration and returns the ones it applied.
+func (migrator *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return migrator.upTo(ctx, math.MaxUint64)
+}
+
+// UpTo applies the pending migrations up to and including version, which
+// must be known, and returns the ones it applied.
+func (migrator *Migrator) UpTo(ctx context.Context, version uint64) ([]Migration, error) {
+	if migrator.index(version) < 0 {
+		return nil, fmt.Errorf("%s: %d", errtext.ErrMigrationUnknown, version)
+	}
+	return migrator.upTo(ctx, version)
+}
+
+func (migrator *Migrator) upTo(ctx context.Context, version uint64) ([]Migration, error) {
+	var applied []Migration
+	err := migrator.locked(ctx, func(conn *sql.Conn) error {
+		for {
+			var next *Migration
+			err := migrator.step(ctx, conn, func(current uint64) (string, uint64, error) {
+				next = migrator.after(current)
+				if next == nil || next.Version > version {
+					next = nil
+					return "", current, nil
+				}
+				return next.Up, next.Version, nil
+			})
+			if err != nil || next == nil {
+				return err
+			}
+			migrator.logger.Info("migration applied", "migration", next.String())
+			applied = append(applied, *next)
+		}
+	})
+	return applied, err
+}
+
+// Down rolls back the last count applied migrations and returns them in the
+// order they were rolled back.
+func (migrator *Migrator) Down(ctx context.Context, count int) ([]Migration, error) {
+	var reverted []Migration
+	err := migrator.locked(ctx, func(conn *sql.Conn) error {
+		for range count {
+			var last Migration
+			err := migrator.step(ctx, conn, func(current uint64) (string, uint64, error) {
+				if current == 0 {
+					return "", 0, nil
+				}
+				index := migrator.index(current)
+				if index < 0 {
+					return "", 0, fmt.Errorf("%s: %d", errtext.ErrMigrationUnknown, current)
+				}
+				last = migrator.migrations[index]
+				var previous uint64
+				if index > 0 {
+					previous = migrator.migrations[index-1].Version
+				}
+				return last.Down, previous, nil
+			})
+			if err != nil || last.Version == 0 {
+				return err
+			}
+			migrator.logger.Info("migration rolled back", "migration", last.String())
+			reverted = append(reverted, last)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Force records version as current and clean without running anything,
+// for recovering from a dirty state. Version 0 clears the record.
+func (migrator *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 && migrator.index(version) < 0 {
+		return fmt.Errorf("%s: %d", errtext.ErrMigrationUnknown, version)
+	}
+	return migrator.locked(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := migrator.setVersion(ctx, tx, version); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+		return tx.Commit()
+	})
+}
+
+// after returns the first migration newer than version.
+func (migrator *Migrator) after(version uint64) *Migration {
+	for index := range migrator.migrations {
+		if migrator.migrations[index].Version > version {
+			return &migrator.migrations[index]
+		}
+	}
+	return nil
+}
+
+// index returns the position of version, or -1.
+func (migrator *Migrator) index(version uint64) int {
+	for index, migration := range migrator.migrations {
+		if migration.Version == version {
+			return index
+		}
+	}
+	return -1
+}
+
+// }}}
+// Steps and locking. {{{
+
+// locked runs fn on a dedicated connection holding the migration lock,
+// after making sure the version table exists.
+func (migrator *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := migrator.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	driver := migrator.db.Dialect().Driver()
+	if driver == config.DriverPostgres {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+			return fmt.Errorf("%s: %w", errtext.ErrMigrationLock, err)
+		}
+		defer func() {
+			_, _ = conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey)
+		}()
+	}
+	if _, err := conn.ExecContext(ctx, createTable[driver]); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// step runs one transaction: plan receives the current version and returns
+// the SQL to run and the version to record, or an empty body when there is
+// nothing to do.
+func (migrator *Migrator) step(ctx context.Context, conn *sql.Conn, plan func(current uint64) (string, uint64, error)) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	current, dirty, err := migrator.version(ctx, tx)
+	if err == nil && dirty {
+		err = fmt.Errorf("%s at version %d: fix the schema, then run bmsd migrate force", errtext.ErrMigrationDirty, current)
+	}
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	body, version, err := plan(current)
+	if err != nil || body == "" {
+		return errors.Join(err, tx.Rollback())
+	}
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		err = fmt.Errorf("%s: %d: %w", errtext.ErrMigrationFailed, max(current, version), err)
+		return errors.Join(err, tx.Rollback())
+	}
+	if err := migrator.setVersion(ctx, tx, version); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// queryer is the query side of *sql.Tx, *sql.Conn, and *sql.DB.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// version reads the recorded version; an empty table means version 0.
+func (migrator *Migrator) version(ctx context.Context, query queryer) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := query.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(version), dirty, nil
+}
+
+func (migrator *Migrator) setVersion(ctx context.Context, tx *sql.Tx, version uint64) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+	insert := migrator.db.Dialect().Rebind("INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)")
+	_, err := tx.ExecContext(ctx, insert, int64(version), false)
+	return err
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Migration runner tests.
+// This file verifies applying, partially applying, rolling back, forcing,
+// and reporting migrations on SQLite, refusing trees that fail lint and
+// dirty versions, and that concurrent runners apply each migration once.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+)
+
+// Runner tests. {{{
+
+var testMigrations = tree(map[string]string{
+	"1_first.up.sql":    "CREATE TABLE first (id INTEGER);",
+	"1_first.down.sql":  "DROP TABLE first;",
+	"2_second.up.sql":   "CREATE TABLE second (id INTEGER); INSERT INTO second VALUES (1);",
+	"2_second.down.sql": "DROP TABLE second;",
+})
+
+// tree places the same files in shared/, sqlite/, and postgres/.
+func tree(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, body := range files {
+		for _, dir := range []string{"shared", "sqlite", "postgres"} {
+			fsys[dir+"/"+name] = file(body)
+		}
+	}
+	return fsys
+}
+
+// openMigrator opens a migrator on the SQLite file at path.
+func openMigrator(t *testing.T, path string, fsys fstest.MapFS) *Migrator {
+	t.Helper()
+	settings := config.DefaultConfig().Database
+	settings.Driver = config.DriverSQLite
+	settings.DSN = "file:" + path
+	database, err := db.Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = database.Close()
+	})
+	migrator, err := New(database, fsys, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	return migrator
+}
+
+func status(t *testing.T, migrator *Migrator) Status {
+	t.Helper()
+	current, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	return current
+}
+
+func TestUpDownStatus(t *testing.T) {
+	migrator := openMigrator(t, filepath.Join(t.TempDir(), "bms.db"), testMigrations)
+	ctx := context.Background()
+
+	if current := status(t, migrator); current.Version != 0 || current.Pending != 2 || current.Latest != 2 || current.Current() {
+		t.Fatalf("unexpected initial status %+v", current)
+	}
+	var tables int
+	if err := migrator.db.QueryRow("SELECT count(*) FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&tables); err != nil || tables != 0 {
+		t.Fatalf("expected status not to create the version table, got %d (%v)", tables, err)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("expected two applied migrations, got %v (%v)", applied, err)
+	}
+	if current := status(t, migrator); current.Version != 2 || !current.Current() || !current.Migrations[1].Applied {
+		t.Fatalf("unexpected status after up %+v", current)
+	}
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("expected nothing left to apply, got %v (%v)", applied, err)
+	}
+
+	reverted, err := migrator.Down(ctx, 1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("expected version 2 rolled back, got %v (%v)", reverted, err)
+	}
+	if current := status(t, migrator); current.Version != 1 || current.Pending != 1 {
+		t.Fatalf("unexpected status after down %+v", current)
+	}
+	if reverted, err := migrator.Down(ctx, 5); err != nil || len(reverted) != 1 {
+		t.Fatalf("expected down to stop at version 0, got %v (%v)", reverted, err)
+	}
+	if err := migrator.db.QueryRow("SELECT count(*) FROM sqlite_master WHERE name IN ('first', 'second')").Scan(&tables); err != nil || tables != 0 {
+		t.Fatalf("expected every table dropped, got %d (%v)", tables, err)
+	}
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+	fsys := tree(map[string]string{
+		"1_first.up.sql":   "CREATE TABLE first (id INTEGER);",
+		"1_first.down.sql": "DROP TABLE first;",
+		"2_bad.up.sql":     "CREATE TABLE bad (id INTEGER); SELECT * FROM missing;",
+		"2_bad.down.sql":   "DROP TABLE bad;",
+	})
+	migrator := openMigrator(t, filepath.Join(t.TempDir(), "bms.db"), fsys)
+
+	applied, err := migrator.Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "migration failed: 2") || len(applied) != 1 {
+		t.Fatalf("expected migration 2 to fail after applying 1, got %v (%v)", applied, err)
+	}
+	var tables int
+	if err := migrator.db.QueryRow("SELECT count(*) FROM sqlite_master WHERE name = 'bad'").Scan(&tables); err != nil || tables != 0 {
+		t.Fatalf("expected the failed migration rolled back, got %d (%v)", tables, err)
+	}
+	if current := status(t, migrator); current.Version != 1 || current.Dirty {
+		t.Fatalf("expected a clean version 1, got %+v", current)
+	}
+}
+
+func TestDirtyAndForce(t *testing.T) {
+	migrator := openMigrator(t, filepath.Join(t.TempDir(), "bms.db"), testMigrations)
+	ctx := context.Background()
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if _, err := migrator.db.Exec("UPDATE schema_migrations SET version = 1, dirty = 1"); err != nil {
+		t.Fatalf("mark dirty: %v", err)
+	}
+
+	if current := status(t, migrator); !current.Dirty || current.Current() {
+		t.Fatalf("expected a dirty status, got %+v", current)
+	}
+	if _, err := migrator.Up(ctx); err == nil || !strings.Contains(err.Error(), "dirty") {
+		t.Fatalf("expected up to refuse a dirty version, got %v", err)
+	}
+	if err := migrator.Force(ctx, 7); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("expected forcing an unknown version to fail, got %v", err)
+	}
+	if err := migrator.Force(ctx, 2); err != nil {
+		t.Fatalf("force: %v", err)
+	}
+	if current := status(t, migrator); current.Version != 2 || !current.Current() {
+		t.Fatalf("expected a clean version 2, got %+v", current)
+	}
+	if err := migrator.Force(ctx, 0); err != nil {
+		t.Fatalf("force 0: %v", err)
+	}
+	if current := status(t, migrator); current.Version != 0 || current.Pending != 2 {
+		t.Fatalf("expected no recorded version, got %+v", current)
+	}
+}
+
+func TestUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bms.db")
+	migrator := openMigrator(t, path, testMigrations)
+	ctx := context.Background()
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+
+	older := openMigrator(t, path, tree(map[string]string{
+		"1_first.up.sql":   "CREATE TABLE first (id INTEGER);",
+		"1_first.down.sql": "DROP TABLE first;",
+	}))
+	if current := status(t, older); !current.Unknown || current.Current() {
+		t.Fatalf("expected an unknown version, got %+v", current)
+	}
+	if _, err := older.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("expected down from an unknown version to fail, got %v", err)
+	}
+}
+
+func TestUpTo(t *testing.T) {
+	migrator := openMigrator(t, filepath.Join(t.TempDir(), "bms.db"), testMigrations)
+	ctx := context.Background()
+
+	if _, err := migrator.UpTo(ctx, 3); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("expected an unknown target to fail, got %v", err)
+	}
+	applied, err := migrator.UpTo(ctx, 1)
+	if err != nil || len(applied) != 1 || applied[0].Version != 1 {
+		t.Fatalf("expected migration 1 only, got %v, %v", applied, err)
+	}
+	if current := status(t, migrator); current.Version != 1 || current.Pending != 1 {
+		t.Fatalf("expected version 1 with one pending, got %+v", current)
+	}
+	if applied, err := migrator.UpTo(ctx, 1); err != nil || len(applied) != 0 {
+		t.Fatalf("expected nothing to apply, got %v, %v", applied, err)
+	}
+}
+
+func TestConcurrentUp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bms.db")
+	migrators := []*Migrator{openMigrator(t, path, testMigrations), openMigrator(t, path, testMigrations)}
+
+	var wait sync.WaitGroup
+	counts := make([]int, len(migrators))
+	errs := make([]error, len(migrators))
+	for index, migrator := range migrators {
+		wait.Go(func() {
+			applied, err := migrator.Up(context.Background())
+			counts[index], errs[index] = len(applied), err
+		})
+	}
+	wait.Wait()
+	if err := errors.Join(errs...); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if counts[0]+counts[1] != 2 {
+		t.Fatalf("expected each migration applied once, got %v", counts)
+	}
+	var rows int
+	if err := migrators[0].db.QueryRow("SELECT count(*) FROM second").Scan(&rows); err != nil || rows != 1 {
+		t.Fatalf("expected one row from migration 2, got %d (%v)", rows, err)
+	}
+}
+
+func TestNewRefusesLintProblems(t *testing.T) {
+	fsys := tree(map[string]string{
+		"1_first.up.sql":   "CREATE TABLE first (id INTEGER);",
+		"1_first.down.sql": "DROP TABLE first;",
+	})
+	delete(fsys, "postgres/1_first.down.sql")
+	settings := config.DefaultConfig().Database
+	settings.Driver = config.DriverSQLite
+	settings.DSN = "file::memory:"
+	database, err := db.Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer database.Close()
+	if _, err := New(database, fsys, slog.New(slog.DiscardHandler)); err == nil || !strings.Contains(err.Error(), "missing 1_first.down.sql") {
+		t.Fatalf("expected the lint problem, got %v", err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Migration sources.
+// This file loads migrations from a tree laid out like db/migrations: one
+// directory per driver holding <version>_<slug>.up.sql and .down.sql pairs,
+// where the version is a timestamp. The embedded trees are used unless
+// database.migrations names a directory with the same layout.
+
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SandorMiskey/bms-core/db/migrations"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Migration files. {{{
+
+const (
+	downSuffix = ".down.sql"
+	upSuffix   = ".up.sql"
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version uint64 `json:"version"`
+	Name    string `json:"name"` // Slug after the version.
+	Up      string `json:"-"`
+	Down    string `json:"-"`
+}
+
+// String returns the file name stem, such as 20261019120000_server_meta.
+func (migration Migration) String() string {
+	return fmt.Sprintf("%d_%s", migration.Version, migration.Name)
+}
+
+// Source returns the migration tree for settings: the directory in
+// database.migrations, or the embedded trees.
+func Source(settings config.DatabaseConfig) fs.FS {
+	if settings.Migrations != "" {
+		return os.DirFS(settings.Migrations)
+	}
+	return migrations.FS
+}
+
+// Load reads the migrations for driver from fsys, sorted by version. Every
+// up file needs a down file and versions must be unique.
+func Load(fsys fs.FS, driver config.DatabaseDriver) ([]Migration, error) {
+	dir := string(driver)
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var stem string
+		var up bool
+		switch {
+		case entry.IsDir():
+			continue
+		case strings.HasSuffix(name, upSuffix):
+			stem, up = strings.TrimSuffix(name, upSuffix), true
+		case strings.HasSuffix(name, downSuffix):
+			stem = strings.TrimSuffix(name, downSuffix)
+		default:
+			continue
+		}
+
+		version, slug, err := parseStem(stem)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q: %w", errtext.ErrMigrationInvalid, path.Join(dir, name), err)
+		}
+		body, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: slug}
+			byVersion[version] = migration
+		}
+		if migration.Name != slug {
+			return nil, fmt.Errorf("%s: %q: version %d is used by %q", errtext.ErrMigrationInvalid, path.Join(dir, name), version, migration.Name)
+		}
+		if up {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("%s: %q: needs both up and down files", errtext.ErrMigrationInvalid, path.Join(dir, migration.String()))
+		}
+		list = append(list, *migration)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+// parseStem splits <version>_<slug>.
+func parseStem(stem string) (uint64, string, error) {
+	prefix, slug, ok := strings.Cut(stem, "_")
+	if !ok || slug == "" {
+		return 0, "", errors.New("expected <version>_<name>")
+	}
+	version, err := strconv.ParseUint(prefix, 10, 63)
+	if err != nil || version == 0 {
+		return 0, "", fmt.Errorf("version %q is not a positive number", prefix)
+	}
+	return version, slug, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
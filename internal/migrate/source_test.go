@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Migration source tests.
+// This file verifies loading migration pairs, rejecting malformed trees, and
+// that the embedded trees load for every driver.
+
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/SandorMiskey/bms-core/db/migrations"
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Source tests. {{{
+
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sqlite/20261020000000_second.up.sql":   file("CREATE TABLE b (id INTEGER);"),
+		"sqlite/20261020000000_second.down.sql": file("DROP TABLE b;"),
+		"sqlite/20261019000000_first.up.sql":    file("CREATE TABLE a (id INTEGER);"),
+		"sqlite/20261019000000_first.down.sql":  file("DROP TABLE a;"),
+		"sqlite/README.md":                      file("ignored"),
+	}
+	list, err := Load(fsys, config.DriverSQLite)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(list) != 2 || list[0].String() != "20261019000000_first" || list[1].String() != "20261020000000_second" {
+		t.Fatalf("unexpected migrations %v", list)
+	}
+	if list[1].Up != "CREATE TABLE b (id INTEGER);" || list[1].Down != "DROP TABLE b;" {
+		t.Fatalf("unexpected bodies %+v", list[1])
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"needs both up and down": {
+			"sqlite/1_only.up.sql": file("SELECT 1;"),
+		},
+		"expected <version>_<name>": {
+			"sqlite/1.up.sql":   file("SELECT 1;"),
+			"sqlite/1.down.sql": file("SELECT 1;"),
+		},
+		"is not a positive number": {
+			"sqlite/x_bad.up.sql":   file("SELECT 1;"),
+			"sqlite/x_bad.down.sql": file("SELECT 1;"),
+		},
+		"version 1 is used by": {
+			"sqlite/1_one.up.sql":   file("SELECT 1;"),
+			"sqlite/1_one.down.sql": file("SELECT 1;"),
+			"sqlite/1_two.up.sql":   file("SELECT 1;"),
+			"sqlite/1_two.down.sql": file("SELECT 1;"),
+		},
+	}
+	for want, fsys := range cases {
+		if _, err := Load(fsys, config.DriverSQLite); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q, got %v", want, err)
+		}
+	}
+	if _, err := Load(fstest.MapFS{}, config.DriverPostgres); err == nil {
+		t.Fatal("expected a missing driver directory to fail")
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	var versions [][]uint64
+	for _, driver := range []config.DatabaseDriver{config.DriverSQLite, config.DriverPostgres} {
+		list, err := Load(migrations.FS, driver)
+		if err != nil {
+			t.Fatalf("%s: %v", driver, err)
+		}
+		if len(list) == 0 {
+			t.Fatalf("%s: no embedded migrations", driver)
+		}
+		var driverVersions []uint64
+		for _, migration := range list {
+			driverVersions = append(driverVersions, migration.Version)
+		}
+		versions = append(versions, driverVersions)
+	}
+	if len(versions[0]) != len(versions[1]) {
+		t.Fatalf("drivers disagree on migrations: %v", versions)
+	}
+	for index := range versions[0] {
+		if versions[0][index] != versions[1][index] {
+			t.Fatalf("drivers disagree on migrations: %v", versions)
+		}
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Migration status.
+// This file defines Status, a read-only view of the recorded version against
+// the known migrations. It neither takes the migration lock nor creates the
+// version table, so doctor and readiness checks can call it safely.
+
+package migrate
+
+import "context"
+
+// Status. {{{
+
+// MigrationStatus is one known migration and whether it is applied.
+type MigrationStatus struct {
+	Migration
+	Applied bool `json:"applied"`
+}
+
+// Status reports the recorded version against the known migrations.
+type Status struct {
+	Version    uint64            `json:"version"`           // Recorded version (0 for none).
+	Dirty      bool              `json:"dirty"`             // A failed migration left the schema unknown.
+	Latest     uint64            `json:"latest"`            // Newest known migration.
+	Pending    int               `json:"pending"`           // Known migrations not applied yet.
+	Unknown    bool              `json:"unknown,omitempty"` // Version is not a known migration.
+	Migrations []MigrationStatus `json:"migrations"`
+}
+
+// Current reports whether every known migration is applied and the state
+// is clean.
+func (status Status) Current() bool {
+	return status.Pending == 0 && !status.Dirty && !status.Unknown
+}
+
+// Status reads the recorded version without changing the database.
+func (migrator *Migrator) Status(ctx context.Context) (Status, error) {
+	status := Status{Migrations: make([]MigrationStatus, 0, len(migrator.migrations))}
+
+	var exists int
+	driver := migrator.db.Dialect().Driver()
+	if err := migrator.db.QueryRowContext(ctx, tableExists[driver]).Scan(&exists); err != nil {
+		return status, err
+	}
+	if exists > 0 {
+		version, dirty, err := migrator.version(ctx, migrator.db)
+		if err != nil {
+			return status, err
+		}
+		status.Version, status.Dirty = version, dirty
+		status.Unknown = version != 0 && migrator.index(version) < 0
+	}
+
+	for _, migration := range migrator.migrations {
+		applied := migration.Version <= status.Version
+		if !applied {
+			status.Pending++
+		}
+		status.Latest = migration.Version
+		status.Migrations = append(status.Migrations, MigrationStatus{Migration: migration, Applied: applied})
+	}
+	return status, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,353 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// PostgreSQL schema.
+// This file reads the tables of the current schema from pg_catalog using the
+// same functions pg_dump uses (format_type, pg_get_expr,
+// pg_get_constraintdef, pg_get_indexdef), and parses the CREATE TABLE,
+// ADD CONSTRAINT, SET DEFAULT, and CREATE INDEX statements of a pg_dump
+// --schema-only file into the same form. Schema qualifiers are removed from
+// both, since pg_dump qualifies every name and a live session does not.
+// Other objects (sequences, functions, views) are not compared.
+
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PostgreSQL. {{{
+
+const (
+	postgresColumnsQuery = `SELECT c.relname, a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull, a.attgenerated, coalesce(pg_get_expr(d.adbin, d.adrelid), '')
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE c.relnamespace = current_schema()::regnamespace AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped`
+	postgresConstraintsQuery = `SELECT c.relname, con.conname, pg_get_constraintdef(con.oid)
+		FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid
+		WHERE c.relnamespace = current_schema()::regnamespace AND con.contype IN ('c', 'f', 'p', 'u', 'x')`
+	postgresIndexesQuery = `SELECT t.relname, i.relname, pg_get_indexdef(x.indexrelid)
+		FROM pg_index x
+		JOIN pg_class i ON i.oid = x.indexrelid
+		JOIN pg_class t ON t.oid = x.indrelid
+		WHERE t.relnamespace = current_schema()::regnamespace AND t.relkind IN ('r', 'p')
+			AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = x.indexrelid AND con.contype IN ('p', 'u', 'x'))`
+)
+
+func introspectPostgres(ctx context.Context, database queryer) (*Schema, error) {
+	var current string
+	if err := queryRow(ctx, database, "SELECT current_schema()", &current); err != nil {
+		return nil, err
+	}
+	unqualify := qualifier([]string{current})
+	schema := New()
+
+	rows, err := database.QueryContext(ctx, postgresColumnsQuery)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var table, name, columnType, generated, expression string
+		var notNull bool
+		if err := rows.Scan(&table, &name, &columnType, &notNull, &generated, &expression); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		defaultValue := expression
+		if generated == "s" {
+			columnType += " GENERATED ALWAYS AS (" + expression + ") STORED"
+			defaultValue = ""
+		}
+		schema.Add(Object{Kind: KindTable, Table: table})
+		schema.Add(Object{Kind: KindColumn, Table: table, Name: name, Definition: unqualify(columnDefinition(columnType, defaultValue, notNull))})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, kind := range []Kind{KindConstraint, KindIndex} {
+		query := postgresConstraintsQuery
+		if kind == KindIndex {
+			query = postgresIndexesQuery
+		}
+		rows, err := database.QueryContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var table, name, definition string
+			if err := rows.Scan(&table, &name, &definition); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			schema.Add(Object{Kind: kind, Table: table, Name: name, Definition: unqualify(definition)})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+func queryRow(ctx context.Context, database queryer, query string, value any) error {
+	rows, err := database.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	return rows.Scan(value)
+}
+
+// Statements of a pg_dump file that carry schema objects.
+var (
+	dumpCreateTable   = regexp.MustCompile(`(?is)^CREATE (?:UNLOGGED )?TABLE (\S+) \(`)
+	dumpAddConstraint = regexp.MustCompile(`(?is)^ALTER TABLE (?:ONLY )?(\S+)\s+ADD CONSTRAINT (\S+) (.*)$`)
+	dumpSetDefault    = regexp.MustCompile(`(?is)^ALTER TABLE (?:ONLY )?(\S+)\s+ALTER COLUMN (\S+) SET DEFAULT (.*)$`)
+	dumpCreateIndex   = regexp.MustCompile(`(?is)^CREATE (?:UNIQUE )?INDEX (\S+) ON (?:ONLY )?(\S+) `)
+)
+
+// dumpColumn is a column of a CREATE TABLE statement.
+type dumpColumn struct {
+	table, name, columnType, defaultValue string
+	notNull                               bool
+}
+
+func parsePostgresDump(dump string) (*Schema, error) {
+	statements, err := splitStatements(dump)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the schemas tables are qualified with first, so definitions
+	// can be unqualified.
+	var schemas []string
+	for _, statement := range statements {
+		if match := dumpCreateTable.FindStringSubmatch(statement); match != nil {
+			if qualifier, _, ok := strings.Cut(match[1], "."); ok {
+				schemas = append(schemas, qualifier)
+			}
+		}
+	}
+	unqualify := qualifier(schemas)
+
+	schema := New()
+	var columns []*dumpColumn
+	byName := make(map[string]*dumpColumn)
+	for _, statement := range statements {
+		switch {
+		case dumpCreateTable.MatchString(statement):
+			match := dumpCreateTable.FindStringSubmatch(statement)
+			table := unquote(unqualify(match[1]))
+			schema.Add(Object{Kind: KindTable, Table: table})
+			body, ok := parenthesized(statement[len(match[0])-1:])
+			if !ok {
+				return nil, fmt.Errorf("unbalanced CREATE TABLE %s", match[1])
+			}
+			for _, element := range splitTopLevel(body) {
+				element = normalizeSpace(element)
+				if name, definition, ok := strings.Cut(element, " "); ok && strings.EqualFold(name, "CONSTRAINT") {
+					name, definition, _ = strings.Cut(definition, " ")
+					schema.Add(Object{Kind: KindConstraint, Table: table, Name: unquote(name), Definition: unqualify(definition)})
+					continue
+				}
+				column, err := parseDumpColumn(table, element)
+				if err != nil {
+					return nil, err
+				}
+				columns = append(columns, column)
+				byName[table+"."+column.name] = column
+			}
+		case dumpAddConstraint.MatchString(statement):
+			match := dumpAddConstraint.FindStringSubmatch(statement)
+			table := unquote(unqualify(match[1]))
+			schema.Add(Object{Kind: KindConstraint, Table: table, Name: unquote(match[2]), Definition: unqualify(match[3])})
+		case dumpSetDefault.MatchString(statement):
+			match := dumpSetDefault.FindStringSubmatch(statement)
+			column := byName[unquote(unqualify(match[1]))+"."+unquote(match[2])]
+			if column == nil {
+				return nil, fmt.Errorf("default for unknown column %s.%s", match[1], match[2])
+			}
+			column.defaultValue = normalizeSpace(match[3])
+		case dumpCreateIndex.MatchString(statement):
+			match := dumpCreateIndex.FindStringSubmatch(statement)
+			schema.Add(Object{Kind: KindIndex, Table: unquote(unqualify(match[2])), Name: unquote(match[1]), Definition: unqualify(statement)})
+		}
+	}
+	for _, column := range columns {
+		definition := unqualify(columnDefinition(column.columnType, column.defaultValue, column.notNull))
+		schema.Add(Object{Kind: KindColumn, Table: column.table, Name: column.name, Definition: definition})
+	}
+	return schema, nil
+}
+
+// parseDumpColumn splits a pg_dump column, `name type [DEFAULT expr]
+// [NOT NULL]`.
+func parseDumpColumn(table string, element string) (*dumpColumn, error) {
+	name, rest, ok := cutIdentifier(element)
+	if !ok || rest == "" {
+		return nil, fmt.Errorf("column %q in table %s", element, table)
+	}
+	column := &dumpColumn{table: table, name: name}
+	rest, column.notNull = strings.CutSuffix(rest, " NOT NULL")
+	column.columnType, column.defaultValue, _ = strings.Cut(rest, " DEFAULT ")
+	return column, nil
+}
+
+// cutIdentifier splits a possibly quoted identifier from the rest.
+func cutIdentifier(value string) (string, string, bool) {
+	if strings.HasPrefix(value, `"`) {
+		end := strings.Index(value[1:], `"`)
+		if end < 0 {
+			return "", "", false
+		}
+		return value[1 : end+1], strings.TrimSpace(value[end+2:]), true
+	}
+	name, rest, ok := strings.Cut(value, " ")
+	return name, rest, ok
+}
+
+func unquote(identifier string) string {
+	return strings.Trim(identifier, `"`)
+}
+
+// qualifier returns a function removing "schema." prefixes for schemas.
+func qualifier(schemas []string) func(string) string {
+	seen := make(map[string]bool)
+	var patterns []string
+	for _, schema := range schemas {
+		schema = unquote(schema)
+		if schema != "" && !seen[schema] {
+			seen[schema] = true
+			patterns = append(patterns, regexp.QuoteMeta(schema), regexp.QuoteMeta(`"`+schema+`"`))
+		}
+	}
+	if len(patterns) == 0 {
+		return func(value string) string { return value }
+	}
+	pattern := regexp.MustCompile(`(^|[\s(,'])(?:` + strings.Join(patterns, "|") + `)\.`)
+	return func(value string) string {
+		return pattern.ReplaceAllString(value, "$1")
+	}
+}
+
+// parenthesized returns the text inside the parentheses value starts with.
+func parenthesized(value string) (string, bool) {
+	depth := 0
+	var quote byte
+	for index := 0; index < len(value); index++ {
+		switch character := value[index]; {
+		case quote != 0:
+			if character == quote {
+				quote = 0
+			}
+		case character == '\'' || character == '"':
+			quote = character
+		case character == '(':
+			depth++
+		case character == ')':
+			depth--
+			if depth == 0 {
+				return value[1:index], true
+			}
+		}
+	}
+	return "", false
+}
+
+// splitTopLevel splits the body of CREATE TABLE on commas outside
+// parentheses and quotes.
+func splitTopLevel(body string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for index := 0; index < len(body); index++ {
+		switch character := body[index]; {
+		case quote != 0:
+			if character == quote {
+				quote = 0
+			}
+		case character == '\'' || character == '"':
+			quote = character
+		case character == '(':
+			depth++
+		case character == ')':
+			depth--
+		case character == ',' && depth == 0:
+			parts = append(parts, body[start:index])
+			start = index + 1
+		}
+	}
+	if strings.TrimSpace(body[start:]) != "" {
+		parts = append(parts, body[start:])
+	}
+	return parts
+}
+
+// dollarTag matches the opening tag of a dollar-quoted string.
+var dollarTag = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// splitStatements splits a dump on semicolons outside quotes, dollar quotes,
+// and comments, and drops the comments.
+func splitStatements(dump string) ([]string, error) {
+	var statements []string
+	var current strings.Builder
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+	for index := 0; index < len(dump); index++ {
+		character := dump[index]
+		switch {
+		case strings.HasPrefix(dump[index:], "--"):
+			end := strings.IndexByte(dump[index:], '\n')
+			if end < 0 {
+				index = len(dump)
+			} else {
+				index += end
+				current.WriteByte('\n')
+			}
+		case character == '\'' || character == '"':
+			end := strings.IndexByte(dump[index+1:], character)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote at offset %d", index)
+			}
+			current.WriteString(dump[index : index+end+2])
+			index += end + 1
+		case character == '$' && dollarTag.MatchString(dump[index:]):
+			tag := dollarTag.FindString(dump[index:])
+			end := strings.Index(dump[index+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated %s quote at offset %d", tag, index)
+			}
+			length := len(tag) + end + len(tag)
+			current.WriteString(dump[index : index+length])
+			index += length - 1
+		case character == ';':
+			flush()
+		default:
+			current.WriteByte(character)
+		}
+	}
+	flush()
+	return statements, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
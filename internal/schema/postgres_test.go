@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// PostgreSQL schema tests.
+// This file verifies parsing pg_dump output. The comparison with a live
+// server runs only when BMS_TEST_POSTGRES_DSN names one; it migrates a
+// scratch schema and compares it with the committed dump.
+
+package schema
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/db/migrations"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+)
+
+// PostgreSQL tests. {{{
+
+const postgresTestDump = `--
+-- PostgreSQL database dump
+--
+
+SET client_encoding = 'UTF8';
+SELECT pg_catalog.set_config('search_path', '', false);
+
+CREATE FUNCTION public.touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$BEGIN NEW.updated_at := now(); RETURN NEW; END;$$;
+
+CREATE TABLE public.station (
+    id integer NOT NULL,
+    callsign text NOT NULL,
+    "user" text DEFAULT 'a;b'::text,
+    total integer GENERATED ALWAYS AS ((id * 2)) STORED,
+    CONSTRAINT station_callsign_check CHECK ((callsign <> ''::text))
+);
+
+CREATE SEQUENCE public.station_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1;
+
+ALTER TABLE ONLY public.station ALTER COLUMN id SET DEFAULT nextval('public.station_id_seq'::regclass);
+
+ALTER TABLE ONLY public.station
+    ADD CONSTRAINT station_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.contact
+    ADD CONSTRAINT contact_station_fkey FOREIGN KEY (station_id) REFERENCES public.station(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX station_callsign ON public.station USING btree (lower(callsign));
+`
+
+func TestParsePostgresDump(t *testing.T) {
+	got, err := ParseDump(context.Background(), config.DriverPostgres, postgresTestDump)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var lines []string
+	for _, object := range got.Objects() {
+		lines = append(lines, fmt.Sprintf("%s: %s", object, object.Definition))
+	}
+	want := strings.Join([]string{
+		"constraint contact.contact_station_fkey: FOREIGN KEY (station_id) REFERENCES station(id) ON DELETE CASCADE",
+		"table station: ",
+		"column station.callsign: text NOT NULL",
+		"column station.id: integer DEFAULT nextval('station_id_seq'::regclass) NOT NULL",
+		"column station.total: integer GENERATED ALWAYS AS ((id * 2)) STORED",
+		"column station.user: text DEFAULT 'a;b'::text",
+		"constraint station.station_callsign_check: CHECK ((callsign <> ''::text))",
+		"constraint station.station_pkey: PRIMARY KEY (id)",
+		"index station.station_callsign: CREATE UNIQUE INDEX station_callsign ON station USING btree (lower(callsign))",
+	}, "\n")
+	if result := strings.Join(lines, "\n"); result != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, result)
+	}
+
+	if _, err := ParseDump(context.Background(), config.DriverPostgres, "CREATE FUNCTION f() AS $$ SELECT 1;"); err == nil {
+		t.Fatal("expected an unterminated dollar quote to fail")
+	}
+}
+
+func TestParseCommittedPostgresDump(t *testing.T) {
+	root := filepath.Join("..", "..", "db", "schema")
+	matches, err := filepath.Glob(filepath.Join(root, "postgres", "*.sql"))
+	if err != nil || len(matches) == 0 {
+		t.Fatalf("expected committed postgres dumps, got %v (%v)", matches, err)
+	}
+	for _, path := range matches {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		got, err := ParseDump(context.Background(), config.DriverPostgres, string(body))
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		for _, object := range got.Objects() {
+			if object.Table == "schema_migrations" && object.Name == "version" && object.Definition != "bigint NOT NULL" {
+				t.Fatalf("%s: unexpected %s definition %q", path, object, object.Definition)
+			}
+		}
+		if len(got.Objects()) == 0 {
+			t.Fatalf("%s: no objects", path)
+		}
+	}
+}
+
+func TestPostgresMatchesCommittedDump(t *testing.T) {
+	dsn := os.Getenv("BMS_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("BMS_TEST_POSTGRES_DSN is not set")
+	}
+	ctx := context.Background()
+	settings := config.DefaultConfig().Database
+	settings.Driver, settings.DSN = config.DriverPostgres, dsn
+	admin, err := db.Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer admin.Close()
+	scratch := fmt.Sprintf("bms_drift_%d", time.Now().UnixNano())
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+scratch); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	defer func() {
+		_, _ = admin.ExecContext(ctx, "DROP SCHEMA "+scratch+" CASCADE")
+	}()
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("BMS_TEST_POSTGRES_DSN must be a URL: %v", err)
+	}
+	query := parsed.Query()
+	query.Set("search_path", scratch)
+	parsed.RawQuery = query.Encode()
+	settings.DSN = parsed.String()
+	database, err := db.Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer database.Close()
+
+	migrator, err := migrate.New(database, migrations.FS, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	list := migrator.Migrations()
+	root := filepath.Join("..", "..", "db", "schema")
+	path, err := LatestDump(os.DirFS(root), config.DriverPostgres, list[len(list)-1].Version)
+	if err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	body, err := os.ReadFile(filepath.Join(root, path))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want, err := ParseDump(ctx, config.DriverPostgres, string(body))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	got, err := Introspect(ctx, database)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	if differences := Diff(want, got); len(differences) != 0 {
+		t.Fatalf("migrated schema drifts from %s:\n%s", path, describe(differences))
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Database schema model.
+// This file defines Schema, the tables, columns, constraints, and indexes of
+// a database reduced to one normalized definition string each, and Diff,
+// which compares two of them. Live databases are read by Introspect and the
+// committed dumps in db/schema by ParseDump; both produce definitions in the
+// same per-dialect form, so equal schemas compare equal regardless of how
+// the DDL was written.
+
+package schema
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Schema objects. {{{
+
+// Kind is the type of a schema object.
+type Kind string
+
+const (
+	KindTable      Kind = "table"
+	KindColumn     Kind = "column"
+	KindConstraint Kind = "constraint"
+	KindIndex      Kind = "index"
+)
+
+// kindOrder sorts objects of one table.
+var kindOrder = map[Kind]int{KindTable: 0, KindColumn: 1, KindConstraint: 2, KindIndex: 3}
+
+// Object is one table or an object belonging to a table. Name is empty for
+// tables; Definition is the normalized DDL fragment that must match.
+type Object struct {
+	Kind       Kind   `json:"kind"`
+	Table      string `json:"table"`
+	Name       string `json:"name,omitempty"`
+	Definition string `json:"definition,omitempty"`
+}
+
+// String names the object, such as "column server_meta.key".
+func (object Object) String() string {
+	if object.Kind == KindTable {
+		return "table " + object.Table
+	}
+	return fmt.Sprintf("%s %s.%s", object.Kind, object.Table, object.Name)
+}
+
+func (object Object) key() string {
+	return string(object.Kind) + "\x00" + object.Table + "\x00" + object.Name
+}
+
+// Schema is a set of objects.
+type Schema struct {
+	objects map[string]Object
+}
+
+// New returns an empty schema.
+func New() *Schema {
+	return &Schema{objects: make(map[string]Object)}
+}
+
+// Add records object, replacing an object of the same kind and name.
+func (schema *Schema) Add(object Object) {
+	object.Definition = normalizeSpace(object.Definition)
+	schema.objects[object.key()] = object
+}
+
+// Objects returns the objects sorted by table, kind, and name.
+func (schema *Schema) Objects() []Object {
+	list := make([]Object, 0, len(schema.objects))
+	for _, object := range schema.objects {
+		list = append(list, object)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return less(list[i], list[j])
+	})
+	return list
+}
+
+func less(a Object, b Object) bool {
+	if a.Table != b.Table {
+		return a.Table < b.Table
+	}
+	if a.Kind != b.Kind {
+		return kindOrder[a.Kind] < kindOrder[b.Kind]
+	}
+	return a.Name < b.Name
+}
+
+// }}}
+// Diff. {{{
+
+// Change is how an object differs.
+type Change string
+
+const (
+	ChangeExtra   Change = "extra"   // In the database only.
+	ChangeMissing Change = "missing" // In the dump only.
+	ChangeChanged Change = "changed" // In both with different definitions.
+)
+
+// Difference is one object that differs between the expected and the
+// actual schema.
+type Difference struct {
+	Change Change `json:"change"`
+	Object
+	Want string `json:"want,omitempty"` // Expected definition.
+	Got  string `json:"got,omitempty"`  // Actual definition.
+}
+
+func (difference Difference) String() string {
+	definition := difference.Want
+	switch difference.Change {
+	case ChangeChanged:
+		return fmt.Sprintf("%s %s: want %q, got %q", difference.Change, difference.Object, difference.Want, difference.Got)
+	case ChangeExtra:
+		definition = difference.Got
+	}
+	if definition == "" {
+		return fmt.Sprintf("%s %s", difference.Change, difference.Object)
+	}
+	return fmt.Sprintf("%s %s: %s", difference.Change, difference.Object, definition)
+}
+
+// Diff compares the expected schema with the actual one. Objects of a table
+// that is missing or extra as a whole are not listed separately.
+func Diff(want *Schema, got *Schema) []Difference {
+	var differences []Difference
+	for key, expected := range want.objects {
+		actual, ok := got.objects[key]
+		if ok && actual.Definition == expected.Definition {
+			continue
+		}
+		difference := Difference{Change: ChangeMissing, Object: expected, Want: expected.Definition}
+		if ok {
+			difference.Change, difference.Got = ChangeChanged, actual.Definition
+		}
+		difference.Definition = ""
+		differences = append(differences, difference)
+	}
+	for key, actual := range got.objects {
+		if _, ok := want.objects[key]; !ok {
+			difference := Difference{Change: ChangeExtra, Object: actual, Got: actual.Definition}
+			difference.Definition = ""
+			differences = append(differences, difference)
+		}
+	}
+
+	wholeTables := make(map[string]bool)
+	for _, difference := range differences {
+		if difference.Kind == KindTable && difference.Change != ChangeChanged {
+			wholeTables[difference.Table] = true
+		}
+	}
+	filtered := differences[:0]
+	for _, difference := range differences {
+		if difference.Kind == KindTable || !wholeTables[difference.Table] {
+			filtered = append(filtered, difference)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return less(filtered[i].Object, filtered[j].Object)
+	})
+	return filtered
+}
+
+// }}}
+// Sources. {{{
+
+// Introspect reads the schema of the connected database.
+func Introspect(ctx context.Context, database *db.DB) (*Schema, error) {
+	var schema *Schema
+	var err error
+	switch driver := database.Dialect().Driver(); driver {
+	case config.DriverSQLite:
+		schema, err = introspectSQLite(ctx, database.DB)
+	case config.DriverPostgres:
+		schema, err = introspectPostgres(ctx, database.DB)
+	default:
+		return nil, fmt.Errorf("%s: %q", errtext.ErrDatabaseDriver, driver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errtext.ErrSchemaIntrospect, err)
+	}
+	return schema, nil
+}
+
+// ParseDump reads a schema dump written for driver: sqlite3 .schema output
+// or pg_dump --schema-only output.
+func ParseDump(ctx context.Context, driver config.DatabaseDriver, dump string) (*Schema, error) {
+	var schema *Schema
+	var err error
+	switch driver {
+	case config.DriverSQLite:
+		schema, err = parseSQLiteDump(ctx, dump)
+	case config.DriverPostgres:
+		schema, err = parsePostgresDump(dump)
+	default:
+		return nil, fmt.Errorf("%s: %q", errtext.ErrDatabaseDriver, driver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errtext.ErrSchemaDumpInvalid, err)
+	}
+	return schema, nil
+}
+
+// LatestDump returns the path in fsys of the newest dump for driver taken
+// after migration version, named
+// <driver>/schema_<time>_after_<version>.sql as the Makefile writes them.
+func LatestDump(fsys fs.FS, driver config.DatabaseDriver, version uint64) (string, error) {
+	matches, err := fs.Glob(fsys, path.Join(string(driver), fmt.Sprintf("schema_*_after_%d.sql", version)))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%s: %s %d", errtext.ErrSchemaDumpMissing, driver, version)
+	}
+	sort.Strings(matches)
+	return matches[len(matches)-1], nil
+}
+
+var spaces = regexp.MustCompile(`\s+`)
+
+// normalizeSpace collapses whitespace runs, drops it inside parentheses,
+// and trims the result.
+func normalizeSpace(value string) string {
+	value = spaces.ReplaceAllString(strings.TrimSpace(value), " ")
+	value = strings.ReplaceAll(value, "( ", "(")
+	return strings.ReplaceAll(value, " )", ")")
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Schema model tests.
+// This file verifies diffing schemas and locating the newest dump for a
+// migration version.
+
+package schema
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Model tests. {{{
+
+func describe(differences []Difference) string {
+	lines := make([]string, 0, len(differences))
+	for _, difference := range differences {
+		lines = append(lines, difference.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDiff(t *testing.T) {
+	want := New()
+	got := New()
+	for _, schema := range []*Schema{want, got} {
+		schema.Add(Object{Kind: KindTable, Table: "a"})
+		schema.Add(Object{Kind: KindColumn, Table: "a", Name: "id", Definition: "integer NOT NULL"})
+	}
+	want.Add(Object{Kind: KindColumn, Table: "a", Name: "name", Definition: "text  NOT\n NULL"})
+	got.Add(Object{Kind: KindColumn, Table: "a", Name: "name", Definition: "text"})
+	want.Add(Object{Kind: KindIndex, Table: "a", Name: "a_id", Definition: "INDEX ON a (id)"})
+	got.Add(Object{Kind: KindConstraint, Table: "a", Name: "a_pkey", Definition: "PRIMARY KEY ( id )"})
+	want.Add(Object{Kind: KindTable, Table: "gone"})
+	want.Add(Object{Kind: KindColumn, Table: "gone", Name: "id", Definition: "integer"})
+	got.Add(Object{Kind: KindTable, Table: "new"})
+	got.Add(Object{Kind: KindColumn, Table: "new", Name: "id", Definition: "integer"})
+
+	expected := strings.Join([]string{
+		`changed column a.name: want "text NOT NULL", got "text"`,
+		`extra constraint a.a_pkey: PRIMARY KEY (id)`,
+		`missing index a.a_id: INDEX ON a (id)`,
+		`missing table gone`,
+		`extra table new`,
+	}, "\n")
+	if result := describe(Diff(want, got)); result != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, result)
+	}
+	if differences := Diff(want, want); len(differences) != 0 {
+		t.Fatalf("expected no differences, got %v", differences)
+	}
+}
+
+func TestLatestDump(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sqlite/schema_20261019130000_after_20261019120000.sql": {},
+		"sqlite/schema_20261020130000_after_20261019120000.sql": {},
+		"sqlite/schema_20261021130000_after_20261021120000.sql": {},
+	}
+	dump, err := LatestDump(fsys, config.DriverSQLite, 20261019120000)
+	if err != nil || dump != "sqlite/schema_20261020130000_after_20261019120000.sql" {
+		t.Fatalf("expected the newest dump, got %q (%v)", dump, err)
+	}
+	if _, err := LatestDump(fsys, config.DriverPostgres, 20261019120000); err == nil || !strings.Contains(err.Error(), "no schema dump") {
+		t.Fatalf("expected a missing dump, got %v", err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
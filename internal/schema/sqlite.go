@@ -0,0 +1,264 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// SQLite schema.
+// This file reads a SQLite schema through the table, index, and foreign key
+// pragmas. A .schema dump is loaded into an in-memory database and read the
+// same way, so both sides are normalized by SQLite itself. Primary key,
+// unique, and foreign key constraints are unnamed in SQLite and are keyed by
+// their columns; CHECK constraints are not visible through the pragmas and
+// are not compared.
+
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+)
+
+// SQLite. {{{
+
+// queryer is the query side of *sql.DB and *sql.Conn.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// sqliteInternal matches the tables SQLite creates itself, which .schema
+// prints but cannot be created again.
+var sqliteInternal = regexp.MustCompile(`(?im)^CREATE TABLE (?:IF NOT EXISTS )?["']?sqlite_\w+["']?\s*\([^;]*\);`)
+
+// sqliteWhere finds the WHERE clause of a partial index.
+var sqliteWhere = regexp.MustCompile(`(?is)\sWHERE\s(.*)$`)
+
+func parseSQLiteDump(ctx context.Context, dump string) (*Schema, error) {
+	settings := config.DefaultConfig().Database
+	settings.Driver, settings.DSN = config.DriverSQLite, ":memory:"
+	database, err := db.Open(settings)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+	if _, err := database.ExecContext(ctx, sqliteInternal.ReplaceAllString(dump, "")); err != nil {
+		return nil, err
+	}
+	return introspectSQLite(ctx, database.DB)
+}
+
+func introspectSQLite(ctx context.Context, database queryer) (*Schema, error) {
+	tables, err := queryStrings(ctx, database, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	schema := New()
+	for _, table := range tables {
+		schema.Add(Object{Kind: KindTable, Table: table})
+		if err := sqliteColumns(ctx, database, schema, table); err != nil {
+			return nil, err
+		}
+		if err := sqliteForeignKeys(ctx, database, schema, table); err != nil {
+			return nil, err
+		}
+		if err := sqliteIndexes(ctx, database, schema, table); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// sqliteColumns adds the columns of table and its primary key.
+func sqliteColumns(ctx context.Context, database queryer, schema *Schema, table string) error {
+	rows, err := database.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	primaryKey := make(map[int]string)
+	for rows.Next() {
+		var name, columnType string
+		var notNull bool
+		var defaultValue sql.NullString
+		var pk int
+		if err := rows.Scan(&name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		schema.Add(Object{Kind: KindColumn, Table: table, Name: name, Definition: columnDefinition(strings.ToUpper(columnType), defaultValue.String, notNull)})
+		if pk > 0 {
+			primaryKey[pk] = name
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(primaryKey) > 0 {
+		columns := make([]string, len(primaryKey))
+		for position, name := range primaryKey {
+			columns[position-1] = name
+		}
+		schema.Add(Object{Kind: KindConstraint, Table: table, Name: "PRIMARY KEY", Definition: "PRIMARY KEY (" + strings.Join(columns, ", ") + ")"})
+	}
+	return nil
+}
+
+// sqliteForeignKeys adds the foreign keys of table, keyed by their columns.
+func sqliteForeignKeys(ctx context.Context, database queryer, schema *Schema, table string) error {
+	rows, err := database.QueryContext(ctx, `SELECT id, "table", "from", coalesce("to", ''), on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type foreignKey struct {
+		parent, onUpdate, onDelete string
+		from, to                   []string
+	}
+	var order []int
+	keys := make(map[int]*foreignKey)
+	for rows.Next() {
+		var id int
+		var parent, from, to, onUpdate, onDelete string
+		if err := rows.Scan(&id, &parent, &from, &to, &onUpdate, &onDelete); err != nil {
+			return err
+		}
+		key := keys[id]
+		if key == nil {
+			key = &foreignKey{parent: parent, onUpdate: onUpdate, onDelete: onDelete}
+			keys[id] = key
+			order = append(order, id)
+		}
+		key.from, key.to = append(key.from, from), append(key.to, to)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range order {
+		key := keys[id]
+		name := "FOREIGN KEY (" + strings.Join(key.from, ", ") + ")"
+		definition := fmt.Sprintf("%s REFERENCES %s(%s)", name, key.parent, strings.Join(key.to, ", "))
+		if key.onUpdate != "NO ACTION" {
+			definition += " ON UPDATE " + key.onUpdate
+		}
+		if key.onDelete != "NO ACTION" {
+			definition += " ON DELETE " + key.onDelete
+		}
+		schema.Add(Object{Kind: KindConstraint, Table: table, Name: name, Definition: definition})
+	}
+	return nil
+}
+
+// sqliteIndexes adds the indexes of table. Indexes backing UNIQUE
+// constraints become constraints; the primary key index is skipped.
+func sqliteIndexes(ctx context.Context, database queryer, schema *Schema, table string) error {
+	rows, err := database.QueryContext(ctx, `SELECT l.name, l."unique", l.origin, coalesce(m.sql, '') FROM pragma_index_list(?) AS l LEFT JOIN sqlite_master AS m ON m.type = 'index' AND m.name = l.name ORDER BY l.name`, table)
+	if err != nil {
+		return err
+	}
+	type index struct {
+		name, origin, sql string
+		unique            bool
+	}
+	var indexes []index
+	for rows.Next() {
+		var entry index
+		if err := rows.Scan(&entry.name, &entry.unique, &entry.origin, &entry.sql); err != nil {
+			rows.Close()
+			return err
+		}
+		indexes = append(indexes, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, entry := range indexes {
+		if entry.origin == "pk" {
+			continue
+		}
+		columns, err := sqliteIndexColumns(ctx, database, entry.name)
+		if err != nil {
+			return err
+		}
+		if entry.origin == "u" {
+			name := "UNIQUE (" + columns + ")"
+			schema.Add(Object{Kind: KindConstraint, Table: table, Name: name, Definition: name})
+			continue
+		}
+		definition := "INDEX ON " + table + " (" + columns + ")"
+		if entry.unique {
+			definition = "UNIQUE " + definition
+		}
+		if match := sqliteWhere.FindStringSubmatch(entry.sql); match != nil {
+			definition += " WHERE " + match[1]
+		}
+		schema.Add(Object{Kind: KindIndex, Table: table, Name: entry.name, Definition: definition})
+	}
+	return nil
+}
+
+// sqliteIndexColumns lists the key columns of index with their order and
+// non-default collation.
+func sqliteIndexColumns(ctx context.Context, database queryer, index string) (string, error) {
+	rows, err := database.QueryContext(ctx, `SELECT coalesce(name, '<expression>'), "desc", coll FROM pragma_index_xinfo(?) WHERE key = 1 ORDER BY seqno`, index)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var name, collation string
+		var descending bool
+		if err := rows.Scan(&name, &descending, &collation); err != nil {
+			return "", err
+		}
+		if collation != "BINARY" {
+			name += " COLLATE " + collation
+		}
+		if descending {
+			name += " DESC"
+		}
+		columns = append(columns, name)
+	}
+	return strings.Join(columns, ", "), rows.Err()
+}
+
+// columnDefinition formats a column like pg_dump does: type, default, and
+// NOT NULL.
+func columnDefinition(columnType string, defaultValue string, notNull bool) string {
+	definition := columnType
+	if defaultValue != "" {
+		definition += " DEFAULT " + defaultValue
+	}
+	if notNull {
+		definition += " NOT NULL"
+	}
+	return definition
+}
+
+func queryStrings(ctx context.Context, database queryer, query string, args ...any) ([]string, error) {
+	rows, err := database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// SQLite schema tests.
+// This file verifies reading a SQLite schema, that a .schema dump of it
+// reads back identically, and that the migrated database matches the
+// committed dump.
+
+package schema
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SandorMiskey/bms-core/db/migrations"
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/db"
+	"github.com/SandorMiskey/bms-core/internal/migrate"
+)
+
+// SQLite tests. {{{
+
+const sqliteTestDDL = `
+CREATE TABLE station (
+    id       INTEGER PRIMARY KEY,
+    callsign text    NOT NULL UNIQUE,
+    created  TEXT    NOT NULL DEFAULT (datetime('now'))
+);
+CREATE TABLE contact (
+    station_id INTEGER NOT NULL REFERENCES station (id) ON DELETE CASCADE,
+    sequence   INTEGER NOT NULL,
+    band       TEXT,
+    PRIMARY KEY (station_id, sequence)
+);
+CREATE INDEX contact_band ON contact (band COLLATE NOCASE, sequence DESC) WHERE band IS NOT NULL;
+`
+
+func openSQLite(t *testing.T) *db.DB {
+	t.Helper()
+	settings := config.DefaultConfig().Database
+	settings.Driver = config.DriverSQLite
+	settings.DSN = "file:" + filepath.Join(t.TempDir(), "bms.db")
+	database, err := db.Open(settings)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = database.Close()
+	})
+	return database
+}
+
+// sqliteDump returns what sqlite3 .schema prints for database.
+func sqliteDump(t *testing.T, database *db.DB) string {
+	t.Helper()
+	statements, err := queryStrings(context.Background(), database, "SELECT sql FROM sqlite_master WHERE sql IS NOT NULL ORDER BY rowid")
+	if err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	return strings.Join(statements, ";\n") + ";\n"
+}
+
+func TestIntrospectSQLite(t *testing.T) {
+	database := openSQLite(t)
+	ctx := context.Background()
+	if _, err := database.ExecContext(ctx, sqliteTestDDL); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err := Introspect(ctx, database)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	definitions := make(map[string]string)
+	for _, object := range got.Objects() {
+		definitions[object.String()] = object.Definition
+	}
+	for name, want := range map[string]string{
+		"table station":                               "",
+		"column station.callsign":                     "TEXT NOT NULL",
+		"column station.created":                      "TEXT DEFAULT datetime('now') NOT NULL",
+		"constraint station.PRIMARY KEY":              "PRIMARY KEY (id)",
+		"constraint station.UNIQUE (callsign)":        "UNIQUE (callsign)",
+		"constraint contact.PRIMARY KEY":              "PRIMARY KEY (station_id, sequence)",
+		"constraint contact.FOREIGN KEY (station_id)": "FOREIGN KEY (station_id) REFERENCES station(id) ON DELETE CASCADE",
+		"index contact.contact_band":                  "INDEX ON contact (band COLLATE NOCASE, sequence DESC) WHERE band IS NOT NULL",
+	} {
+		if definition, ok := definitions[name]; !ok || definition != want {
+			t.Fatalf("expected %s %q, got %q (present %v)", name, want, definition, ok)
+		}
+	}
+	if len(definitions) != 13 {
+		t.Fatalf("expected 13 objects, got %v", definitions)
+	}
+
+	want, err := ParseDump(ctx, config.DriverSQLite, "CREATE TABLE sqlite_sequence(name,seq);\n"+sqliteDump(t, database))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if differences := Diff(want, got); len(differences) != 0 {
+		t.Fatalf("expected the dump to read back identically, got:\n%s", describe(differences))
+	}
+
+	if _, err := database.ExecContext(ctx, "DROP INDEX contact_band; CREATE INDEX contact_band ON contact (band)"); err != nil {
+		t.Fatalf("alter: %v", err)
+	}
+	got, err = Introspect(ctx, database)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	if result := describe(Diff(want, got)); !strings.HasPrefix(result, "changed index contact.contact_band") {
+		t.Fatalf("expected the changed index, got:\n%s", result)
+	}
+}
+
+func TestSQLiteMatchesCommittedDump(t *testing.T) {
+	database := openSQLite(t)
+	ctx := context.Background()
+	migrator, err := migrate.New(database, migrations.FS, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	list := migrator.Migrations()
+
+	dumps := os.DirFS(filepath.Join("..", "..", "db", "schema"))
+	path, err := LatestDump(dumps, config.DriverSQLite, list[len(list)-1].Version)
+	if err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	body, err := os.ReadFile(filepath.Join("..", "..", "db", "schema", path))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want, err := ParseDump(ctx, config.DriverSQLite, string(body))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	got, err := Introspect(ctx, database)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	if differences := Diff(want, got); len(differences) != 0 {
+		t.Fatalf("migrated schema drifts from %s:\n%s", path, describe(differences))
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Listener addresses.
+// This file binds TCP host:port addresses and unix:///path sockets. For a
+// unix socket it creates the parent directory, removes a stale socket left
+// by a crashed process (one nobody accepts on), refuses to touch a live
+// socket or a non-socket file, and applies the configured mode and owner.
+// The socket file is removed when the listener closes.
+
+package socket
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Listening. {{{
+
+const (
+	defaultMode   = 0o660
+	directoryMode = 0o750
+	staleTimeout  = time.Second
+)
+
+// Listen binds address, a host:port or unix:///path.
+func Listen(address string, settings config.SocketConfig) (net.Listener, error) {
+	path, unix := config.UnixSocketPath(address)
+	if !unix {
+		return net.Listen("tcp", address)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), directoryMode); err != nil {
+		return nil, err
+	}
+	if err := removeStale(path); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPermissions(path, settings); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// removeStale deletes a socket file at path that no process accepts on.
+func removeStale(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%s: %q", errtext.ErrSocketNotSocket, path)
+	}
+	if conn, err := net.DialTimeout("unix", path, staleTimeout); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s: %q", errtext.ErrSocketInUse, path)
+	}
+	return os.Remove(path)
+}
+
+// applyPermissions sets the mode and owner of the socket file.
+func applyPermissions(path string, settings config.SocketConfig) error {
+	mode := uint64(defaultMode)
+	if settings.Mode != "" {
+		parsed, err := strconv.ParseUint(settings.Mode, 8, 32)
+		if err != nil {
+			return err
+		}
+		mode = parsed
+	}
+	if err := os.Chmod(path, fs.FileMode(mode)); err != nil {
+		return err
+	}
+	if settings.User == "" && settings.Group == "" {
+		return nil
+	}
+
+	uid, gid := -1, -1
+	if settings.User != "" {
+		id, err := lookupID(settings.User, func(name string) (string, error) {
+			account, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return account.Uid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s: user %q: %w", errtext.ErrSocketOwner, settings.User, err)
+		}
+		uid = id
+	}
+	if settings.Group != "" {
+		id, err := lookupID(settings.Group, func(name string) (string, error) {
+			group, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return group.Gid, nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s: group %q: %w", errtext.ErrSocketOwner, settings.Group, err)
+		}
+		gid = id
+	}
+	if err := os.Lchown(path, uid, gid); err != nil {
+		return fmt.Errorf("%s: %w", errtext.ErrSocketOwner, err)
+	}
+	return nil
+}
+
+// lookupID resolves a numeric id or a name through lookup.
+func lookupID(value string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, nil
+	}
+	id, err := lookup(value)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
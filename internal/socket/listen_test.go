@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Listener address tests.
+// This file verifies TCP and unix socket binding, socket file mode and
+// owner, stale socket cleanup, and refusal to replace live sockets or
+// regular files.
+
+//go:build unix
+
+package socket
+
+import (
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Listening tests. {{{
+
+func TestListenTCP(t *testing.T) {
+	listener, err := Listen("127.0.0.1:0", config.SocketConfig{})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	if listener.Addr().Network() != "tcp" {
+		t.Fatalf("expected a tcp listener, got %s", listener.Addr().Network())
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "bmsd.sock")
+	settings := config.SocketConfig{Mode: "0600", User: strconv.Itoa(os.Getuid()), Group: strconv.Itoa(os.Getgid())}
+	listener, err := Listen(config.UnixSocketPrefix+path, settings)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Type() != fs.ModeSocket || info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected socket mode %v", info.Mode())
+	}
+
+	go func() {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	conn.Close()
+
+	_ = listener.Close()
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the socket file to be removed on close, got %v", err)
+	}
+}
+
+func TestListenUnixStaleAndInUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bmsd.sock")
+	address := config.UnixSocketPrefix + path
+
+	crashed, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	crashed.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = crashed.Close()
+
+	listener, err := Listen(address, config.SocketConfig{})
+	if err != nil {
+		t.Fatalf("expected the stale socket to be replaced, got %v", err)
+	}
+	defer listener.Close()
+
+	if _, err := Listen(address, config.SocketConfig{}); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("expected a live socket to be refused, got %v", err)
+	}
+
+	regular := filepath.Join(t.TempDir(), "bmsd.sock")
+	if err := os.WriteFile(regular, []byte("data"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Listen(config.UnixSocketPrefix+regular, config.SocketConfig{}); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("expected a regular file to be refused, got %v", err)
+	}
+	if _, err := os.Stat(regular); err != nil {
+		t.Fatalf("expected the regular file to be kept, got %v", err)
+	}
+}
+
+func TestListenUnixUnknownOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bmsd.sock")
+	_, err := Listen(config.UnixSocketPrefix+path, config.SocketConfig{User: "no-such-bms-user"})
+	if err == nil || !strings.Contains(err.Error(), "owner") {
+		t.Fatalf("expected an owner error, got %v", err)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the socket to be removed after the failure, got %v", err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
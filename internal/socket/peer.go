@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Peer credentials.
+// This file exposes the credentials of the process on the other end of a
+// unix socket connection, as reported by the kernel, so the auth layer can
+// apply local trust (for example to requests from the same user as bmsd).
+// ConnContext stores them on each HTTP request context; TCP connections
+// carry none.
+
+package socket
+
+import (
+	"context"
+	"net"
+)
+
+// Peer credentials. {{{
+
+// Credentials identify a local peer process.
+type Credentials struct {
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+type peerKey struct{}
+
+// Peer returns the credentials of the process connected on conn, which
+// must be a unix socket connection.
+func Peer(conn net.Conn) (Credentials, error) {
+	return peerCredentials(conn)
+}
+
+// ConnContext is an http.Server ConnContext hook that attaches the peer
+// credentials of unix socket connections to the request context.
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	if _, ok := conn.(*net.UnixConn); !ok {
+		return ctx
+	}
+	credentials, err := peerCredentials(conn)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, peerKey{}, credentials)
+}
+
+// PeerFromContext returns the credentials ConnContext attached, if any.
+func PeerFromContext(ctx context.Context) (Credentials, bool) {
+	credentials, ok := ctx.Value(peerKey{}).(Credentials)
+	return credentials, ok
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
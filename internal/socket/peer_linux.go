@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Peer credentials on Linux.
+// This file reads SO_PEERCRED from a unix socket connection.
+
+//go:build linux
+
+package socket
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Peer credentials. {{{
+
+func peerCredentials(conn net.Conn) (Credentials, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return Credentials{}, fmt.Errorf("%s: not a unix socket connection", errtext.ErrPeerCredentials)
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("%s: %w", errtext.ErrPeerCredentials, err)
+	}
+
+	var ucred *syscall.Ucred
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		ucred, sockErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err == nil {
+		err = sockErr
+	}
+	if err != nil {
+		return Credentials{}, fmt.Errorf("%s: %w", errtext.ErrPeerCredentials, err)
+	}
+	return Credentials{PID: ucred.Pid, UID: ucred.Uid, GID: ucred.Gid}, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Peer credentials elsewhere.
+// This file reports peer credentials as unsupported outside Linux; local
+// trust then falls back to the other auth methods.
+
+//go:build !linux
+
+package socket
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Peer credentials. {{{
+
+func peerCredentials(net.Conn) (Credentials, error) {
+	return Credentials{}, fmt.Errorf("%s: %w", errtext.ErrPeerCredentials, errors.ErrUnsupported)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Peer credential tests.
+// This file verifies SO_PEERCRED lookups and that ConnContext attaches them
+// to requests served over a unix socket but not over TCP.
+
+//go:build linux
+
+package socket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SandorMiskey/bms-core/internal/config"
+)
+
+// Peer credential tests. {{{
+
+// servePeer serves the caller's credentials on listener.
+func servePeer(t *testing.T, listener net.Listener) {
+	t.Helper()
+	server := &http.Server{
+		ConnContext: ConnContext,
+		Handler: http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			credentials, ok := PeerFromContext(request.Context())
+			if !ok {
+				_, _ = io.WriteString(writer, "none")
+				return
+			}
+			fmt.Fprintf(writer, "%d:%d:%d", credentials.UID, credentials.GID, credentials.PID)
+		}),
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+}
+
+func fetch(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	response, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	return string(body)
+}
+
+func TestConnContextUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bmsd.sock")
+	listener, err := Listen(config.UnixSocketPrefix+path, config.SocketConfig{})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	servePeer(t, listener)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	}}
+	want := fmt.Sprintf("%d:%d:%d", os.Getuid(), os.Getgid(), os.Getpid())
+	if got := fetch(t, client, "http://localhost/"); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestConnContextTCP(t *testing.T) {
+	listener, err := Listen("127.0.0.1:0", config.SocketConfig{})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	servePeer(t, listener)
+	if got := fetch(t, http.DefaultClient, "http://"+listener.Addr().String()+"/"); got != "none" {
+		t.Fatalf("expected no credentials over tcp, got %s", got)
+	}
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := Peer(conn); err == nil {
+		t.Fatal("expected peer credentials to be unavailable over tcp")
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Socket activation.
+// This file reads the sockets systemd passes with LISTEN_FDS, starting at
+// file descriptor 3, and names them from LISTEN_FDNAMES (the socket unit's
+// FileDescriptorName=). Servers look their listener up by name and fall
+// back to binding their configured address when none was passed. The
+// variables are cleared after reading so child processes do not inherit
+// them.
+
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Socket activation. {{{
+
+const (
+	ListenFDNamesEnv = "LISTEN_FDNAMES"
+	ListenFDsEnv     = "LISTEN_FDS"
+	ListenPIDEnv     = "LISTEN_PID"
+
+	listenFDsStart = 3
+	unnamedFD      = "unknown"
+)
+
+// Activated holds socket-activated listeners by name.
+type Activated map[string][]net.Listener
+
+// Take removes and returns the first listener named name, or nil.
+func (activated Activated) Take(name string) net.Listener {
+	listeners := activated[name]
+	if len(listeners) == 0 {
+		return nil
+	}
+	if len(listeners) == 1 {
+		delete(activated, name)
+	} else {
+		activated[name] = listeners[1:]
+	}
+	return listeners[0]
+}
+
+// Close closes the listeners nobody took and returns their names.
+func (activated Activated) Close() []string {
+	var names []string
+	for name, listeners := range activated {
+		for _, listener := range listeners {
+			_ = listener.Close()
+			names = append(names, name)
+		}
+		delete(activated, name)
+	}
+	return names
+}
+
+// Listeners returns the sockets passed by systemd, or an empty set when the
+// process was not socket activated.
+func Listeners() (Activated, error) {
+	defer func() {
+		_ = os.Unsetenv(ListenPIDEnv)
+		_ = os.Unsetenv(ListenFDsEnv)
+		_ = os.Unsetenv(ListenFDNamesEnv)
+	}()
+	return listeners(os.Getenv(ListenPIDEnv), os.Getenv(ListenFDsEnv), os.Getenv(ListenFDNamesEnv), listenFDsStart)
+}
+
+// listeners converts count descriptors starting at first into listeners.
+func listeners(pid string, count string, names string, first int) (Activated, error) {
+	activated := Activated{}
+	if count == "" || pid != strconv.Itoa(os.Getpid()) {
+		return activated, nil
+	}
+	fds, err := strconv.Atoi(count)
+	if err != nil || fds < 0 {
+		return activated, fmt.Errorf("%s: %s=%q", errtext.ErrSocketActivation, ListenFDsEnv, count)
+	}
+	labels := strings.Split(names, ":")
+	if names == "" {
+		labels = nil
+	}
+
+	for offset := range fds {
+		name := unnamedFD
+		if offset < len(labels) && labels[offset] != "" {
+			name = labels[offset]
+		}
+		listener, err := fileListener(first+offset, name)
+		if err != nil {
+			activated.Close()
+			return Activated{}, fmt.Errorf("%s: fd %d (%s): %w", errtext.ErrSocketActivation, first+offset, name, err)
+		}
+		activated[name] = append(activated[name], listener)
+	}
+	return activated, nil
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Socket activation elsewhere.
+// This file rejects inherited descriptors on platforms without systemd.
+
+//go:build !unix
+
+package systemd
+
+import (
+	"errors"
+	"net"
+)
+
+// Descriptors. {{{
+
+func fileListener(int, string) (net.Listener, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Socket activation tests.
+// This file verifies inherited descriptors become named listeners, that
+// activation meant for another process is ignored, and that unused
+// listeners are closed.
+
+//go:build unix
+
+package systemd
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+// Test helpers. {{{
+
+// inheritedFD returns a raw descriptor for a bound TCP socket, as systemd
+// would pass it, and the address it listens on.
+func inheritedFD(t *testing.T) (int, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	file, err := listener.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("file: %v", err)
+	}
+	defer file.Close()
+	fd, err := syscall.Dup(int(file.Fd()))
+	if err != nil {
+		t.Fatalf("dup: %v", err)
+	}
+	return fd, listener.Addr().String()
+}
+
+// }}}
+// Socket activation tests. {{{
+
+func TestListeners(t *testing.T) {
+	fd, address := inheritedFD(t)
+	activated, err := listeners(strconv.Itoa(os.Getpid()), "1", "rest", fd)
+	if err != nil {
+		t.Fatalf("listeners: %v", err)
+	}
+
+	listener := activated.Take("rest")
+	if listener == nil {
+		t.Fatalf("expected a rest listener, got %v", activated)
+	}
+	defer listener.Close()
+	if listener.Addr().String() != address {
+		t.Fatalf("expected %s, got %s", address, listener.Addr())
+	}
+	if activated.Take("rest") != nil {
+		t.Fatal("expected the rest listener to be taken once")
+	}
+
+	go func() {
+		if conn, err := net.Dial("tcp", address); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	conn.Close()
+}
+
+func TestListenersUnnamedAndClose(t *testing.T) {
+	fd, _ := inheritedFD(t)
+	activated, err := listeners(strconv.Itoa(os.Getpid()), "1", "", fd)
+	if err != nil {
+		t.Fatalf("listeners: %v", err)
+	}
+	names := activated.Close()
+	if len(names) != 1 || names[0] != unnamedFD {
+		t.Fatalf("expected one %s listener closed, got %v", unnamedFD, names)
+	}
+	if len(activated) != 0 {
+		t.Fatalf("expected no listeners after close, got %v", activated)
+	}
+}
+
+func TestListenersNotActivated(t *testing.T) {
+	activated, err := listeners("", "", "", listenFDsStart)
+	if err != nil || len(activated) != 0 {
+		t.Fatalf("expected no listeners, got %v, %v", activated, err)
+	}
+	activated, err = listeners("1", "2", "rest:grpc", listenFDsStart)
+	if err != nil || len(activated) != 0 {
+		t.Fatalf("expected activation for another pid to be ignored, got %v, %v", activated, err)
+	}
+	if _, err := listeners(strconv.Itoa(os.Getpid()), "many", "", listenFDsStart); err == nil {
+		t.Fatal("expected invalid LISTEN_FDS to be rejected")
+	}
+}
+
+func TestListenersClearsEnvironment(t *testing.T) {
+	t.Setenv(ListenPIDEnv, "1")
+	t.Setenv(ListenFDsEnv, "1")
+	t.Setenv(ListenFDNamesEnv, "rest")
+	if _, err := Listeners(); err != nil {
+		t.Fatalf("listeners: %v", err)
+	}
+	for _, name := range []string{ListenPIDEnv, ListenFDsEnv, ListenFDNamesEnv} {
+		if _, ok := os.LookupEnv(name); ok {
+			t.Fatalf("expected %s to be cleared", name)
+		}
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Socket activation on Unix.
+// This file wraps an inherited stream socket descriptor in a net.Listener.
+
+//go:build unix
+
+package systemd
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// Descriptors. {{{
+
+// fileListener returns a listener for fd. The descriptor is marked
+// close-on-exec and released once net has its own copy.
+func fileListener(fd int, name string) (net.Listener, error) {
+	syscall.CloseOnExec(fd)
+	file := os.NewFile(uintptr(fd), name)
+	defer file.Close()
+	return net.FileListener(file)
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Service manager notifications.
+// This file implements the sd_notify protocol: newline-separated KEY=VALUE
+// messages sent as one datagram to the unix socket named by NOTIFY_SOCKET
+// (a leading @ selects the abstract namespace). Outside systemd the variable
+// is unset and NewNotifier returns nil, on which every method is a no-op, so
+// callers never need to check whether they run under a service manager.
+
+package systemd
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+)
+
+// Notifications. {{{
+
+const (
+	NotifySocketEnv = "NOTIFY_SOCKET"
+
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// Status returns a STATUS= message shown by systemctl status.
+func Status(text string) string {
+	return "STATUS=" + strings.ReplaceAll(text, "\n", " ")
+}
+
+// Notifier sends notifications to the service manager.
+type Notifier struct {
+	address *net.UnixAddr
+	logger  *slog.Logger
+}
+
+// NewNotifier returns a notifier for NOTIFY_SOCKET, or nil when it is unset.
+// Failed sends are logged to logger.
+func NewNotifier(logger *slog.Logger) *Notifier {
+	socket := os.Getenv(NotifySocketEnv)
+	if socket == "" {
+		return nil
+	}
+	return &Notifier{address: &net.UnixAddr{Name: socket, Net: "unixgram"}, logger: logger}
+}
+
+// Notify sends messages as a single datagram.
+func (notifier *Notifier) Notify(messages ...string) error {
+	if notifier == nil || len(messages) == 0 {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, notifier.address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errtext.ErrSystemdNotifyFailed, err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(strings.Join(messages, "\n"))); err != nil {
+		return fmt.Errorf("%s: %w", errtext.ErrSystemdNotifyFailed, err)
+	}
+	return nil
+}
+
+// send is Notify for callers with nowhere to return the error.
+func (notifier *Notifier) send(messages ...string) {
+	if err := notifier.Notify(messages...); err != nil && notifier.logger != nil {
+		notifier.logger.Warn(errtext.ErrSystemdNotifyFailed, "error", err)
+	}
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
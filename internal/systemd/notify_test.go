@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Service notification tests.
+// This file verifies notifications against a local unix datagram socket
+// standing in for systemd: state changes, watchdog keep-alives, and the
+// no-op behavior outside a service manager.
+
+//go:build unix
+
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/health"
+)
+
+// Test helpers. {{{
+
+// listenNotify binds a datagram socket and points NOTIFY_SOCKET at it.
+func listenNotify(t *testing.T) *net.UnixConn {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv(NotifySocketEnv, path)
+	return conn
+}
+
+// receive reads one datagram.
+func receive(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buffer := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buffer)
+	if err != nil {
+		t.Fatalf("read notification: %v", err)
+	}
+	return string(buffer[:n])
+}
+
+// }}}
+// Notification tests. {{{
+
+func TestNotifierDisabled(t *testing.T) {
+	t.Setenv(NotifySocketEnv, "")
+	notifier := NewNotifier(nil)
+	if notifier != nil {
+		t.Fatalf("expected nil notifier without %s", NotifySocketEnv)
+	}
+	if err := notifier.Notify(Ready); err != nil {
+		t.Fatalf("nil notifier should be a no-op, got %v", err)
+	}
+	notifier.Follow(health.NewState(nil))
+	notifier.RunWatchdog(context.Background(), time.Millisecond, func() bool { return true })
+}
+
+func TestNotify(t *testing.T) {
+	conn := listenNotify(t)
+	notifier := NewNotifier(nil)
+
+	if err := notifier.Notify(Ready, Status("serving\nrequests")); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if got, want := receive(t, conn), "READY=1\nSTATUS=serving requests"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	conn := listenNotify(t)
+	state := health.NewState(nil)
+	NewNotifier(nil).Follow(state)
+
+	if got := receive(t, conn); got != "STATUS=starting: process started" {
+		t.Fatalf("unexpected initial status: %q", got)
+	}
+	state.Set(health.PhaseReady, "startup complete")
+	if got := receive(t, conn); got != "READY=1\nSTATUS=ready: startup complete" {
+		t.Fatalf("unexpected ready notification: %q", got)
+	}
+	state.Set(health.PhaseDraining, "shutdown signal received")
+	if got := receive(t, conn); got != "STOPPING=1\nSTATUS=draining: shutdown signal received" {
+		t.Fatalf("unexpected draining notification: %q", got)
+	}
+}
+
+// }}}
+// Watchdog tests. {{{
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv(WatchdogUSecEnv, "")
+	if interval, err := WatchdogInterval(); interval != 0 || err != nil {
+		t.Fatalf("expected disabled watchdog, got %v, %v", interval, err)
+	}
+
+	t.Setenv(WatchdogUSecEnv, "30000000")
+	t.Setenv(WatchdogPIDEnv, strconv.Itoa(os.Getpid()))
+	if interval, err := WatchdogInterval(); interval != 15*time.Second || err != nil {
+		t.Fatalf("expected 15s, got %v, %v", interval, err)
+	}
+
+	t.Setenv(WatchdogPIDEnv, "1")
+	if interval, _ := WatchdogInterval(); interval != 0 {
+		t.Fatalf("expected watchdog for another pid to be ignored, got %v", interval)
+	}
+
+	t.Setenv(WatchdogPIDEnv, "")
+	t.Setenv(WatchdogUSecEnv, "soon")
+	if _, err := WatchdogInterval(); err == nil || !strings.Contains(err.Error(), "soon") {
+		t.Fatalf("expected invalid watchdog error, got %v", err)
+	}
+}
+
+func TestRunWatchdog(t *testing.T) {
+	conn := listenNotify(t)
+	notifier := NewNotifier(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		notifier.RunWatchdog(ctx, 10*time.Millisecond, func() bool { return true })
+		close(done)
+	}()
+
+	for range 2 {
+		if got := receive(t, conn); got != Watchdog {
+			t.Fatalf("expected %q, got %q", Watchdog, got)
+		}
+	}
+	cancel()
+	<-done
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet:
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2026 Sandor Miskey (HA5BMS, sandor@HA5BMS.RADIO)
+
+// Service watchdog and state reporting.
+// This file reads the watchdog interval systemd passes in WATCHDOG_USEC and
+// sends WATCHDOG=1 keep-alives at half of it while the process is alive, and
+// maps health state changes to READY=1, STOPPING=1, and STATUS= messages.
+
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/SandorMiskey/bms-core/internal/errtext"
+	"github.com/SandorMiskey/bms-core/internal/health"
+)
+
+// Watchdog. {{{
+
+const (
+	WatchdogPIDEnv  = "WATCHDOG_PID"
+	WatchdogUSecEnv = "WATCHDOG_USEC"
+)
+
+// WatchdogInterval returns how often to send keep-alives: half the timeout
+// in WATCHDOG_USEC. It returns zero when the watchdog is disabled or meant
+// for another process (WATCHDOG_PID).
+func WatchdogInterval() (time.Duration, error) {
+	value := os.Getenv(WatchdogUSecEnv)
+	if value == "" {
+		return 0, nil
+	}
+	if pid := os.Getenv(WatchdogPIDEnv); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+	usec, err := strconv.ParseUint(value, 10, 63)
+	if err != nil || usec == 0 {
+		return 0, fmt.Errorf("%s: %s=%q", errtext.ErrInvalidWatchdog, WatchdogUSecEnv, value)
+	}
+	return time.Duration(usec) * time.Microsecond / 2, nil
+}
+
+// RunWatchdog sends WATCHDOG=1 every interval while alive reports true,
+// until ctx is done. A hung alive check or a stopped process stops the
+// keep-alives, and systemd restarts the service.
+func (notifier *Notifier) RunWatchdog(ctx context.Context, interval time.Duration, alive func() bool) {
+	if notifier == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if alive() {
+			notifier.send(Watchdog)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// }}}
+// Health state. {{{
+
+// StateMessages returns the notifications for a health snapshot.
+func StateMessages(snapshot health.Snapshot) []string {
+	status := string(snapshot.State)
+	if snapshot.Reason != "" {
+		status += ": " + snapshot.Reason
+	}
+	switch snapshot.State {
+	case health.PhaseReady, health.PhaseDegraded:
+		return []string{Ready, Status(status)}
+	case health.PhaseDraining, health.PhaseStopped:
+		return []string{Stopping, Status(status)}
+	default:
+		return []string{Status(status)}
+	}
+}
+
+// Follow reports the current health state and every later change.
+func (notifier *Notifier) Follow(state *health.State) {
+	if notifier == nil {
+		return
+	}
+	state.Follow(func(current health.Snapshot) {
+		notifier.send(StateMessages(current)...)
+	})
+}
+
+// }}}
+
+// vim: set ts=4 sw=4 noet: